@@ -0,0 +1,82 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// accessor records that the function only returns the nillable field
+// of its single argument, as in
+//
+//	func (s *S) P() *T { return s.p }
+type accessor struct{ Field int }
+
+func (*accessor) AFact() {}
+
+func (a *accessor) String() string { return fmt.Sprintf("accessor(%d)", a.Field) }
+
+// checkAccessor exports accessor for fn if fn is a trivial accessor of
+// a nillable field.
+func checkAccessor(pass *analysis.Pass, fn *ssa.Function) {
+	if factObject(fn) == nil || len(fn.Params) != 1 || len(fn.Blocks) != 1 {
+		return
+	}
+	ret, ok := fn.Blocks[0].Instrs[len(fn.Blocks[0].Instrs)-1].(*ssa.Return)
+	if !ok || len(ret.Results) != 1 || !isNillable(ret.Results[0].Type()) {
+		return
+	}
+	x, field, ok := fieldLoad(ret.Results[0])
+	if ok && x == fn.Params[0] {
+		pass.ExportObjectFact(factObject(fn), &accessor{field})
+	}
+}
+
+// fieldLoad returns the value and the field index selected by v if v
+// loads a field, as x.f or *(&x.f) does.
+func fieldLoad(v ssa.Value) (ssa.Value, int, bool) {
+	switch v := v.(type) {
+	case *ssa.Field:
+		return v.X, v.Field, true
+	case *ssa.UnOp:
+		if fa, ok := v.X.(*ssa.FieldAddr); ok && v.Op == token.MUL {
+			return fa.X, fa.Field, true
+		}
+	}
+	return nil, 0, false
+}
+
+// access returns the value and the field index read by v, either
+// directly or through a call of an accessor.
+func access(pass *analysis.Pass, v ssa.Value) (ssa.Value, int, bool) {
+	c, ok := v.(*ssa.Call)
+	if !ok {
+		return fieldLoad(v)
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil || len(c.Call.Args) != 1 {
+		return nil, 0, false
+	}
+	var fact accessor
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return nil, 0, false
+	}
+	return c.Call.Args[0], fact.Field, true
+}
+
+// sameAccess reports whether a and b read the same field of the same
+// value, treating accessor calls like the loads of the fields they
+// return.
+func sameAccess(pass *analysis.Pass, a, b ssa.Value) bool {
+	if a == b {
+		return true
+	}
+	xa, fa, ok := access(pass, a)
+	if !ok {
+		return false
+	}
+	xb, fb, ok := access(pass, b)
+	return ok && fa == fb && xa == xb
+}
@@ -0,0 +1,98 @@
+package nilarg
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// addrAliases returns the loads of the local variable st stores v into,
+// when the address of the variable is taken but nothing can change it
+// after st, as in
+//
+//	func f(p *T) {
+//		log(&p)
+//		p.x = 1 // loads p from its variable
+//	}
+//
+// The builder spills such parameters into variables, and the loads are v
+// itself as long as the only store into the variable is st and its
+// address is only read: loaded, or passed to functions of the package
+// which only load through their parameter.
+func addrAliases(st *ssa.Store, v ssa.Value) []ssa.Value {
+	alloc, ok := st.Addr.(*ssa.Alloc)
+	if !ok || st.Val != v || alloc.Referrers() == nil {
+		return nil
+	}
+	var loads []ssa.Value
+	for _, r := range *alloc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.DebugRef:
+		case *ssa.Store:
+			if r != st {
+				return nil
+			}
+		case *ssa.UnOp:
+			if r.Op != token.MUL {
+				return nil
+			}
+			if before(st, r) {
+				loads = append(loads, r)
+			}
+		case ssa.CallInstruction:
+			if !onlyLoadsThrough(r.Common(), alloc) {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return loads
+}
+
+// before reports whether the instruction x is executed before instr on
+// all the paths to it.
+func before(x, instr ssa.Instruction) bool {
+	if x.Block() != instr.Block() {
+		return x.Block().Dominates(instr.Block())
+	}
+	for _, i := range x.Block().Instrs {
+		switch i {
+		case x:
+			return true
+		case instr:
+			return false
+		}
+	}
+	return false
+}
+
+// onlyLoadsThrough reports whether the call common only reads the
+// variable addr: addr is only passed as arguments to a static callee
+// which only loads from the corresponding parameters.
+func onlyLoadsThrough(common *ssa.CallCommon, addr ssa.Value) bool {
+	s := common.StaticCallee()
+	if s == nil || s.Blocks == nil || common.Value == addr {
+		return false
+	}
+	for i, a := range common.Args {
+		if a != addr {
+			continue
+		}
+		if i >= len(s.Params) || s.Params[i].Referrers() == nil {
+			return false
+		}
+		for _, r := range *s.Params[i].Referrers() {
+			switch r := r.(type) {
+			case *ssa.DebugRef:
+			case *ssa.UnOp:
+				if r.Op != token.MUL {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package nilarg
+
+import (
+	"reflect"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// anonFacts records the panicArgs of the anonymous functions of each
+// pass. Function literals have no objects to export facts for, but they
+// can only be called in their package, as in
+//
+//	each := func(p *T) { p.x++ }
+//	each(nil)
+var anonFacts = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[*ssa.Function]panicArgs
+}{m: make(map[*analysis.Pass]map[*ssa.Function]panicArgs)}
+
+func forgetAnonFacts(pass *analysis.Pass) {
+	anonFacts.Lock()
+	defer anonFacts.Unlock()
+	delete(anonFacts.m, pass)
+}
+
+// isAnon reports whether fn is a function literal.
+func isAnon(fn *ssa.Function) bool {
+	return fn.Parent() != nil && fn.Object() == nil
+}
+
+// setAnonFact records fact for the function literal fn and reports
+// whether it changed.
+func setAnonFact(pass *analysis.Pass, fn *ssa.Function, fact panicArgs) bool {
+	anonFacts.Lock()
+	defer anonFacts.Unlock()
+	facts := anonFacts.m[pass]
+	if facts == nil {
+		facts = make(map[*ssa.Function]panicArgs)
+		anonFacts.m[pass] = facts
+	}
+	if old, ok := facts[fn]; ok && reflect.DeepEqual(old, fact) || !ok && len(fact) == 0 {
+		return false
+	}
+	facts[fn] = fact
+	return true
+}
+
+// calleePanicArgs imports the panicArgs of the callee s into fact, from
+// the facts of its object, or from anonFacts for function literals.
+func calleePanicArgs(pass *analysis.Pass, s *ssa.Function, fact *panicArgs) bool {
+	if obj := factObject(s); obj != nil {
+		return importPanicArgs(pass, obj, fact)
+	}
+	if !isAnon(s) {
+		return false
+	}
+	anonFacts.Lock()
+	defer anonFacts.Unlock()
+	f, ok := anonFacts.m[pass][s]
+	*fact = f
+	return ok && len(f) > 0
+}
@@ -0,0 +1,303 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// boundMethod is a method value stored in a struct field, bound to a
+// receiver which can be nil.
+type boundMethod struct {
+	method *types.Func
+	pos    token.Pos
+}
+
+// boundFields maps the passes being run to the struct fields of
+// function type in their packages which are assigned method values
+// bound to receivers which can be nil.
+var boundFields = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[*types.Var]boundMethod
+}{m: make(map[*analysis.Pass]map[*types.Var]boundMethod)}
+
+// collectBoundFields records the struct fields to which fns store method
+// values bound to receivers which can be nil, as in
+//
+//	var s *Server
+//	if enabled {
+//		s = newServer()
+//	}
+//	hooks := &Hooks{OnStart: s.start}
+//
+// It returns a function which forgets the fields.
+func collectBoundFields(pass *analysis.Pass, fns []*ssa.Function) func() {
+	fields := make(map[*types.Var]boundMethod)
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				st, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				fa, ok := st.Addr.(*ssa.FieldAddr)
+				if !ok {
+					continue
+				}
+				mc, ok := st.Val.(*ssa.MakeClosure)
+				if !ok || len(mc.Bindings) != 1 {
+					continue
+				}
+				method, ok := mc.Fn.(*ssa.Function).Object().(*types.Func)
+				if !ok || !isBoundWrapper(mc.Fn.(*ssa.Function)) || !possiblyNil(pass, mc.Bindings[0], b) {
+					continue
+				}
+				if _, ok := fields[field(fa)]; !ok {
+					fields[field(fa)] = boundMethod{method, st.Pos()}
+				}
+			}
+		}
+	}
+	boundFields.Lock()
+	defer boundFields.Unlock()
+	boundFields.m[pass] = fields
+	return func() {
+		boundFields.Lock()
+		defer boundFields.Unlock()
+		delete(boundFields.m, pass)
+	}
+}
+
+// isBoundWrapper reports whether fn is the closure of a method value,
+// binding the receiver as its free variable.
+func isBoundWrapper(fn *ssa.Function) bool {
+	return fn.Synthetic != "" && len(fn.FreeVars) == 1 && fn.Signature.Recv() == nil
+}
+
+// boundCallee returns the method called by common through a method
+// value made in the same function, as f in
+//
+//	f := t.Close
+//	f(nil)
+//
+// with the receiver bound to it. The receiver is the argument 0 of the
+// method, so the arguments of the call are shifted by one against its
+// parameters. The method value can be stored into a variable first, as
+// in
+//
+//	h := hooks{close: t.Close}
+//	h.close(nil)
+func boundCallee(common *ssa.CallCommon) (*ssa.Function, ssa.Value) {
+	mc := storedClosure(common.Value)
+	if mc == nil {
+		return nil, nil
+	}
+	m := boundFunc(mc)
+	if m == nil {
+		return nil, nil
+	}
+	return m, mc.Bindings[0]
+}
+
+// boundFunc returns the method of the method value mc, or nil if mc
+// isn't one of a concrete method.
+func boundFunc(mc *ssa.MakeClosure) *ssa.Function {
+	if len(mc.Bindings) != 1 {
+		return nil
+	}
+	w := mc.Fn.(*ssa.Function)
+	method, ok := w.Object().(*types.Func)
+	if !ok || !isBoundWrapper(w) {
+		return nil
+	}
+	// FuncValue is nil for a method of an interface.
+	return w.Prog.FuncValue(method)
+}
+
+// boundCall returns a call which calls the method value mc in its
+// function: a call of mc itself or of the variable it is stored into,
+// or a call passing it to a function which panics when it is nil and
+// so calls it, as in
+//
+//	apply(t.Close, nil)
+func boundCall(pass *analysis.Pass, mc *ssa.MakeClosure) ssa.CallInstruction {
+	vals := append([]ssa.Value{mc}, closureLoads(mc)...)
+	for _, v := range vals {
+		if v.Referrers() == nil {
+			continue
+		}
+		for _, r := range *v.Referrers() {
+			c, ok := r.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			common := c.Common()
+			if common.Value == v {
+				return c
+			}
+			s := common.StaticCallee()
+			if common.IsInvoke() || s == nil {
+				continue
+			}
+			var fact panicArgs
+			if !calleePanicArgs(pass, s, &fact) {
+				continue
+			}
+			for _, i := range argIndices(common, v) {
+				if _, ok := fact[i]; ok {
+					return c
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// storedClosure returns the closure which v is, directly or loaded from
+// a local variable or a field of one to which only it is stored, or nil
+// if v isn't known to be a closure.
+func storedClosure(v ssa.Value) *ssa.MakeClosure {
+	if mc, ok := v.(*ssa.MakeClosure); ok {
+		return mc
+	}
+	load, ok := v.(*ssa.UnOp)
+	if !ok || load.Op != token.MUL {
+		return nil
+	}
+	sts := storesTo(sameVar(load.X))
+	if len(sts) != 1 {
+		return nil
+	}
+	mc, _ := sts[0].Val.(*ssa.MakeClosure)
+	return mc
+}
+
+// closureLoads returns the loads of the variables to which only the
+// closure mc is stored.
+func closureLoads(mc *ssa.MakeClosure) []ssa.Value {
+	var loads []ssa.Value
+	for _, r := range *mc.Referrers() {
+		st, ok := r.(*ssa.Store)
+		if !ok || st.Val != mc {
+			continue
+		}
+		addrs := sameVar(st.Addr)
+		if len(storesTo(addrs)) != 1 {
+			continue
+		}
+		for _, a := range addrs {
+			for _, ar := range *a.Referrers() {
+				if load, ok := ar.(*ssa.UnOp); ok && load.Op == token.MUL && load.X == a {
+					loads = append(loads, load)
+				}
+			}
+		}
+	}
+	return loads
+}
+
+// sameVar returns the addresses of the variable at addr in its
+// function: a local variable, or the same field of one struct. It
+// returns nil for the other addresses, whose variables can be shared.
+func sameVar(addr ssa.Value) []ssa.Value {
+	switch addr := addr.(type) {
+	case *ssa.Alloc:
+		return []ssa.Value{addr}
+	case *ssa.FieldAddr:
+		x, ok := addr.X.(*ssa.Alloc)
+		if !ok {
+			return nil
+		}
+		var addrs []ssa.Value
+		for _, r := range *x.Referrers() {
+			switch r := r.(type) {
+			case *ssa.FieldAddr:
+				if r.Field == addr.Field {
+					addrs = append(addrs, r)
+				}
+			case *ssa.DebugRef:
+			default:
+				// The struct escapes or is stored as a whole.
+				return nil
+			}
+		}
+		return addrs
+	}
+	return nil
+}
+
+// storesTo returns the stores to addrs. If any of addrs escapes, as
+// it's passed to a call, it returns nil.
+func storesTo(addrs []ssa.Value) []*ssa.Store {
+	var sts []*ssa.Store
+	for _, a := range addrs {
+		for _, r := range *a.Referrers() {
+			switch r := r.(type) {
+			case *ssa.Store:
+				if r.Addr == a {
+					sts = append(sts, r)
+				}
+			case *ssa.UnOp, *ssa.FieldAddr, *ssa.DebugRef:
+			default:
+				return nil
+			}
+		}
+	}
+	return sts
+}
+
+// possiblyNil reports whether v, used in the block b, is nil, can be
+// nil on some path, such as a variable left nil in a branch, or is a
+// result of a call which can return nil, without a nil check.
+func possiblyNil(pass *analysis.Pass, v ssa.Value, b *ssa.BasicBlock) bool {
+	if isNil(v) {
+		return true
+	}
+	if isNilChecked(v, b) {
+		return false
+	}
+	if phi, ok := v.(*ssa.Phi); ok {
+		for _, e := range phi.Edges {
+			if isNil(e) {
+				return true
+			}
+		}
+	}
+	_, ok := mayReturnNil(pass, v)
+	return ok
+}
+
+// field returns the field of the address fa.
+func field(fa *ssa.FieldAddr) *types.Var {
+	return fa.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct).Field(fa.Field)
+}
+
+// checkBoundFieldCall reports the call c of a struct field assigned a
+// method value bound to a receiver which can be nil, when the method
+// panics on nil receivers.
+func checkBoundFieldCall(pass *analysis.Pass, c *ssa.Call) {
+	f := calledField(c.Common())
+	if f == nil {
+		return
+	}
+	boundFields.Lock()
+	bm, ok := boundFields.m[pass][f]
+	boundFields.Unlock()
+	if !ok {
+		return
+	}
+	var fact panicArgs
+	if !pass.ImportObjectFact(bm.method, &fact) || isNilSafeRecv(pass, bm.method, 0) {
+		return
+	}
+	if _, ok := fact[0]; !ok {
+		return
+	}
+	posn := pass.Fset.Position(bm.pos)
+	report(pass, c.Pos(), "this call can cause panic: %s is bound to %s of a possibly nil receiver at %s:%d",
+		f.Name(), bm.method.Name(), filepath.Base(posn.Filename), posn.Line)
+}
@@ -0,0 +1,154 @@
+package nilarg
+
+import (
+	"go/token"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// channels enables the experimental tracking of nil values sent over
+// the channels made in a package.
+var channels bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&channels, "channels", false,
+		"report values received from channels of the package which can be nil because nil is sent (experimental)")
+}
+
+// checkChannels reports the dereferences of values received from
+// channels made in fns which are sent nil somewhere in fns. Channels are
+// followed through closures and the parameters of static calls in fns;
+// channels stored to fields or globals aren't followed.
+func checkChannels(pass *analysis.Pass, fns []*ssa.Function) {
+	if !channels {
+		return
+	}
+	callers := make(map[*ssa.Function][]ssa.CallInstruction)
+	closures := make(map[*ssa.Function]*ssa.MakeClosure)
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case ssa.CallInstruction:
+					if s := instr.Common().StaticCallee(); s != nil {
+						callers[s] = append(callers[s], instr)
+					}
+				case *ssa.MakeClosure:
+					closures[instr.Fn.(*ssa.Function)] = instr
+				}
+			}
+		}
+	}
+	origins := func(v ssa.Value) []*ssa.MakeChan { return chanOrigins(v, callers, closures, 0) }
+
+	// Summarize the nil sends of each channel.
+	nilSends := make(map[*ssa.MakeChan]token.Pos)
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if send, ok := instr.(*ssa.Send); ok && isNil(send.X) {
+					for _, mc := range origins(send.Chan) {
+						nilSends[mc] = send.Pos()
+					}
+				}
+			}
+		}
+	}
+	if len(nilSends) == 0 {
+		return
+	}
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				recv, ok := instr.(*ssa.UnOp)
+				if !ok || recv.Op != token.ARROW {
+					continue
+				}
+				var pos token.Pos
+				for _, mc := range origins(recv.X) {
+					if p, ok := nilSends[mc]; ok {
+						pos = p
+					}
+				}
+				if !pos.IsValid() {
+					continue
+				}
+				v := receivedValue(recv)
+				if v == nil || !isNillable(v.Type()) || !panicsOnAny(pass, v) {
+					continue
+				}
+				posn := pass.Fset.Position(pos)
+				report(pass, recv.Pos(), "the value received from this channel can be nil: nil is sent at %s:%d", filepath.Base(posn.Filename), posn.Line)
+			}
+		}
+	}
+}
+
+// chanOrigins returns the makes of the channel v, following
+// conversions, variables captured by closures and parameters of
+// functions called statically.
+func chanOrigins(v ssa.Value, callers map[*ssa.Function][]ssa.CallInstruction, closures map[*ssa.Function]*ssa.MakeClosure, depth int) []*ssa.MakeChan {
+	if depth > 8 {
+		return nil
+	}
+	follow := func(v ssa.Value) []*ssa.MakeChan { return chanOrigins(v, callers, closures, depth+1) }
+	switch v := v.(type) {
+	case *ssa.MakeChan:
+		return []*ssa.MakeChan{v}
+	case *ssa.ChangeType:
+		return follow(v.X)
+	case *ssa.UnOp:
+		// a load of a captured variable
+		if v.Op == token.MUL {
+			return follow(v.X)
+		}
+	case *ssa.Alloc:
+		var origins []*ssa.MakeChan
+		for _, r := range *v.Referrers() {
+			if st, ok := r.(*ssa.Store); ok && st.Addr == v {
+				origins = append(origins, follow(st.Val)...)
+			}
+		}
+		return origins
+	case *ssa.FreeVar:
+		mc := closures[v.Parent()]
+		if mc == nil {
+			return nil
+		}
+		for i, fv := range v.Parent().FreeVars {
+			if fv == v {
+				return follow(mc.Bindings[i])
+			}
+		}
+	case *ssa.Parameter:
+		fn := v.Parent()
+		var origins []*ssa.MakeChan
+		for i, fp := range fn.Params {
+			if fp != v {
+				continue
+			}
+			for _, c := range callers[fn] {
+				if args := c.Common().Args; i < len(args) {
+					origins = append(origins, follow(args[i])...)
+				}
+			}
+		}
+		return origins
+	}
+	return nil
+}
+
+// receivedValue returns the value received by recv.
+func receivedValue(recv *ssa.UnOp) ssa.Value {
+	if !recv.CommaOk {
+		return recv
+	}
+	for _, r := range *recv.Referrers() {
+		if e, ok := r.(*ssa.Extract); ok && e.Index == 0 {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package nilarg
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+var (
+	// checkpointDir is the directory where the fact state of packages
+	// whose fixpoint didn't converge in time is saved and resumed from.
+	checkpointDir string
+
+	// fixpointTimeout bounds the time spent on the fixpoint of a
+	// package. Zero means no limit.
+	fixpointTimeout time.Duration
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&checkpointDir, "checkpoint", "",
+		"directory to save the facts of packages whose analysis times out and to resume them from")
+	Analyzer.Flags.DurationVar(&fixpointTimeout, "fixpoint-timeout", 0,
+		"stop the fixpoint of a package after this duration, reporting partial results")
+}
+
+// checkpointFile returns the checkpoint file of the package of pass.
+func checkpointFile(pass *analysis.Pass) string {
+	return filepath.Join(checkpointDir, url.PathEscape(pass.Pkg.Path())+".json")
+}
+
+// checkpoint is the saved fact state of a package. Key identifies the
+// contents of the files compiled for the run and the facts of the
+// dependencies, so that the facts aren't resumed for other files, e.g.
+// with other build tags, or after a dependency is upgraded.
+type checkpoint struct {
+	Key   string
+	Facts map[string][]int
+}
+
+// checkpointKey returns the hash of the contents of the files of pass
+// and of the facts of the packages it imports.
+func checkpointKey(pass *analysis.Pass) string {
+	var lines []string
+	for _, f := range pass.Files {
+		name := pass.Fset.Position(f.Pos()).Filename
+		data, _ := ioutil.ReadFile(name)
+		lines = append(lines, fmt.Sprintf("file %s %x", filepath.Base(name), sha256.Sum256(data)))
+	}
+	for _, f := range pass.AllObjectFacts() {
+		if f.Object.Pkg() == nil || f.Object.Pkg() == pass.Pkg {
+			continue
+		}
+		name := f.Object.Pkg().Path() + "." + f.Object.Name()
+		if fn, ok := f.Object.(*types.Func); ok {
+			name = fn.FullName()
+		}
+		lines = append(lines, fmt.Sprintf("fact %s %v", name, f.Fact))
+	}
+	for _, f := range pass.AllPackageFacts() {
+		if f.Package != pass.Pkg {
+			lines = append(lines, fmt.Sprintf("fact %s %v", f.Package.Path(), f.Fact))
+		}
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(lines, "\n"))))
+}
+
+// loadCheckpoint exports the panicArgs facts of fns saved by a previous
+// run whose fixpoint timed out, so that the fixpoint resumes from them.
+// A checkpoint saved for other files or dependencies is ignored.
+func loadCheckpoint(pass *analysis.Pass, fns []*ssa.Function) {
+	if checkpointDir == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(checkpointFile(pass))
+	if err != nil {
+		return
+	}
+	var cp checkpoint
+	if json.Unmarshal(data, &cp) != nil || cp.Key != checkpointKey(pass) {
+		return
+	}
+	saved := cp.Facts
+	for _, fn := range fns {
+		obj := factObject(fn)
+		if obj == nil || len(saved[fn.String()]) == 0 {
+			continue
+		}
+		fact := panicArgs{}
+		for _, i := range saved[fn.String()] {
+			fact[i] = struct{}{}
+		}
+		pass.ExportObjectFact(obj, &fact)
+	}
+}
+
+// saveCheckpoint saves the panicArgs facts of fns for the next run.
+func saveCheckpoint(pass *analysis.Pass, fns []*ssa.Function) error {
+	if checkpointDir == "" {
+		return nil
+	}
+	saved := make(map[string][]int)
+	for _, fn := range fns {
+		var fact panicArgs
+		if obj := factObject(fn); obj == nil || !pass.ImportObjectFact(obj, &fact) {
+			continue
+		}
+		for i := range fact {
+			saved[fn.String()] = append(saved[fn.String()], i)
+		}
+		sort.Ints(saved[fn.String()])
+	}
+	data, err := json.Marshal(checkpoint{checkpointKey(pass), saved})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(checkpointDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(checkpointFile(pass), data, 0644)
+}
+
+// removeCheckpoint removes the checkpoint of a package which converged.
+func removeCheckpoint(pass *analysis.Pass) {
+	if checkpointDir == "" {
+		return
+	}
+	os.Remove(checkpointFile(pass))
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// changelogVersion is the version of the JSON schema of the changelog.
+const changelogVersion = 1
+
+// changelog is the JSON form of the contract changes between two
+// versions of a module.
+type changelog struct {
+	Version int      `json:"version"`
+	Old     string   `json:"old"`
+	New     string   `json:"new"`
+	Changes []change `json:"changes"`
+}
+
+// change is a parameter of an exported function which panics on nil in
+// the new version but not in the old one, or the other way around.
+type change struct {
+	// Func is the package path and the name of the function as in -run,
+	// e.g. example.com/pkg.T.M.
+	Func  string `json:"func"`
+	Param string `json:"param"`
+	// Kind is "added" for new contracts, which break the callers
+	// passing nil, and "removed" for the relaxed ones.
+	Kind string `json:"kind"`
+	// Contract describes the panic of the version which has it.
+	Contract string `json:"contract"`
+}
+
+// changelogCmd runs the analyzer over two versions of a module given by
+// args, each a directory or a module@version the go command downloads,
+// followed by the packages, ./... by default, and prints the changes of
+// the nil contracts of the exported API. It exits with 3 when contracts
+// were added, as the callers passing nil panic after upgrading.
+func changelogCmd(args []string) int {
+	fs := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the changes as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: nilarg changelog [-json] old new [packages]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 2
+	}
+	pkgs := fs.Args()[2:]
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	var versions [2]map[string][]contract
+	for i, v := range fs.Args()[:2] {
+		dir, err := versionDir(v)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if versions[i], err = contractsOf(dir, pkgs); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", v, err)
+			return 1
+		}
+	}
+	changes := diffContracts(versions[0], versions[1])
+	var err error
+	if *asJSON {
+		err = writeChangelogJSON(os.Stdout, changelog{changelogVersion, fs.Arg(0), fs.Arg(1), changes})
+	} else {
+		err = writeChangelog(os.Stdout, changes)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, c := range changes {
+		if c.Kind == "added" {
+			return 3
+		}
+	}
+	return 0
+}
+
+// versionDir returns the directory of the version v of a module, which
+// is either a directory or a module@version downloaded to the module
+// cache.
+func versionDir(v string) (string, error) {
+	if fi, err := os.Stat(v); err == nil {
+		if !fi.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", v)
+		}
+		return v, nil
+	}
+	if !strings.Contains(v, "@") {
+		return "", fmt.Errorf("%s is neither a directory nor a module@version", v)
+	}
+	out, err := goCommand("", "mod", "download", "-json", v)
+	if err != nil {
+		return "", err
+	}
+	var mod struct{ Dir, Error string }
+	if err := json.Unmarshal([]byte(out), &mod); err != nil {
+		return "", err
+	}
+	if mod.Error != "" {
+		return "", fmt.Errorf("%s: %s", v, mod.Error)
+	}
+	return mod.Dir, nil
+}
+
+// diffContracts returns the contracts of the exported API added to or
+// removed from old in new, sorted by the function and the parameter.
+// The contracts of a parameter in both only differ in their positions
+// or wording, so they aren't changes.
+func diffContracts(old, new map[string][]contract) []change {
+	changes := []change{}
+	diff := func(from, to map[string][]contract, kind string) {
+		for fn, cs := range to {
+			if !isExportedAPI(fn) {
+				continue
+			}
+			for _, c := range cs {
+				if !hasParam(from[fn], c.Param) && !hasChange(changes, fn, c.Param) {
+					changes = append(changes, change{fn, c.Param, kind, c.Text})
+				}
+			}
+		}
+	}
+	diff(old, new, "added")
+	diff(new, old, "removed")
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		return a.Func < b.Func || a.Func == b.Func && a.Param < b.Param
+	})
+	return changes
+}
+
+func hasParam(cs []contract, param string) bool {
+	for _, c := range cs {
+		if c.Param == param {
+			return true
+		}
+	}
+	return false
+}
+
+func hasChange(changes []change, fn, param string) bool {
+	for _, c := range changes {
+		if c.Func == fn && c.Param == param {
+			return true
+		}
+	}
+	return false
+}
+
+// isExportedAPI reports whether the function named fn as in -run, with
+// its package path, can be called by the importers of its package: the
+// package is neither a main package nor internal, and the function and
+// the receiver type of a method are exported.
+func isExportedAPI(fn string) bool {
+	slash := strings.LastIndex(fn, "/")
+	dot := strings.Index(fn[slash+1:], ".")
+	if dot < 0 {
+		return false
+	}
+	pkg, name := fn[:slash+1+dot], fn[slash+2+dot:]
+	if pkg == "main" || pkg == "internal" || strings.HasPrefix(pkg, "internal/") ||
+		strings.Contains(pkg, "/internal/") || strings.HasSuffix(pkg, "/internal") {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if !token.IsExported(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeChangelog prints changes a line each, the added contracts marked
+// with + and the removed ones with -, like a diff of the API.
+func writeChangelog(w io.Writer, changes []change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no nil contracts of the exported API changed")
+		return err
+	}
+	for _, c := range changes {
+		var err error
+		if c.Kind == "added" {
+			_, err = fmt.Fprintf(w, "+ %s panics on nil %s: %s\n", c.Func, c.Param, c.Contract)
+		} else {
+			_, err = fmt.Fprintf(w, "- %s no longer panics on nil %s\n", c.Func, c.Param)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChangelogJSON(w io.Writer, l changelog) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(l)
+}
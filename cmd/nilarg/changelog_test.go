@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDiffContracts(t *testing.T) {
+	old := map[string][]contract{
+		"example.com/m.F":            {{"p", "must not be nil: dereferenced at m.go:3"}},
+		"example.com/m.T.M":          {{"q", "must not be nil: dereferenced at m.go:8"}},
+		"example.com/m.unexported":   {{"p", "must not be nil: dereferenced at m.go:12"}},
+		"example.com/m/internal/x.G": {{"p", "must not be nil: called at x.go:3"}},
+	}
+	new := map[string][]contract{
+		"example.com/m.F":            {{"p", "must not be nil: dereferenced at m.go:5"}, {"r", "must not be nil: indexed at m.go:6"}},
+		"example.com/m.New":          {{"opts", "must not be nil: called at m.go:20"}},
+		"example.com/m/internal/x.H": {{"p", "must not be nil: called at x.go:3"}},
+		"main.Run":                   {{"p", "must not be nil: dereferenced at main.go:3"}},
+	}
+	want := []change{
+		{"example.com/m.F", "r", "added", "must not be nil: indexed at m.go:6"},
+		{"example.com/m.New", "opts", "added", "must not be nil: called at m.go:20"},
+		{"example.com/m.T.M", "q", "removed", "must not be nil: dereferenced at m.go:8"},
+	}
+	got := diffContracts(old, new)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffContracts() = %+v; want %+v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeChangelog(&buf, got); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `+ example.com/m.F panics on nil r: must not be nil: indexed at m.go:6
++ example.com/m.New panics on nil opts: must not be nil: called at m.go:20
+- example.com/m.T.M no longer panics on nil q
+`
+	if buf.String() != wantText {
+		t.Errorf("writeChangelog() =\n%s\nwant\n%s", buf.String(), wantText)
+	}
+}
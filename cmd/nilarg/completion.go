@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/Matts966/nilarg"
+)
+
+// driverFlags are the flags of the analysis driver other than the
+// analyzer flags, with whether they are boolean.
+var driverFlags = map[string]bool{
+	"V":          true,
+	"c":          false,
+	"cpuprofile": false,
+	"debug":      false,
+	"fix":        true,
+	"flags":      true,
+	"json":       true,
+	"memprofile": false,
+	"trace":      false,
+}
+
+// commandFlags are the flags handled by the command itself.
+var commandFlags = map[string]string{
+	"completion": "print a completion script for the shell (bash, zsh or fish)",
+	"fix-defs":   "insert guard clauses into flagged exported functions",
+	"format":     "print diagnostics as text, json, sarif, github, markdown, html or csv",
+}
+
+// flagUsages returns the usages of all the flags of the command keyed by
+// their names.
+func flagUsages() map[string]string {
+	usages := make(map[string]string)
+	for name := range driverFlags {
+		usages[name] = ""
+	}
+	for name, usage := range commandFlags {
+		usages[name] = usage
+	}
+	nilarg.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		usages[f.Name] = f.Usage
+	})
+	return usages
+}
+
+// writeCompletion writes the completion script for shell to w,
+// completing the flags and the directories for package patterns.
+func writeCompletion(w io.Writer, shell string) error {
+	usages := flagUsages()
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	switch shell {
+	case "bash", "zsh":
+		if shell == "zsh" {
+			fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		}
+		fmt.Fprintf(w, `_nilarg() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "-%s" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -d -- "$cur"))
+	fi
+}
+complete -F _nilarg nilarg
+`, strings.Join(names, " -"))
+	case "fish":
+		for _, name := range names {
+			fmt.Fprintf(w, "complete -c nilarg -o %s -d %q\n", name, usages[name])
+		}
+		fmt.Fprintln(w, "complete -c nilarg -x -a '(__fish_complete_directories)'")
+	default:
+		return fmt.Errorf("unknown shell %q: want bash, zsh or fish", shell)
+	}
+	return nil
+}
+
+// boolValue is a flag.Value standing in for the flags of the analyzer
+// and the driver while looking for package patterns.
+type boolValue bool
+
+func (v *boolValue) String() string   { return "" }
+func (v *boolValue) Set(string) error { return nil }
+func (v *boolValue) IsBoolFlag() bool { return bool(*v) }
+
+// withDefaultPattern returns args with the package pattern "." appended
+// if args has no package patterns, analyzing the package in the current
+// directory like go vet does.
+func withDefaultPattern(args []string) []string {
+	fs := flag.NewFlagSet("nilarg", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	for name, isBool := range driverFlags {
+		v := boolValue(isBool)
+		fs.Var(&v, name, "")
+	}
+	nilarg.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		v := boolValue(ok && b.IsBoolFlag())
+		fs.Var(&v, f.Name, "")
+	})
+	if fs.Parse(args) != nil || fs.NArg() > 0 {
+		return args
+	}
+	return append(args, ".")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Matts966/nilarg"
+)
+
+// sample is a package for which the analyzer must report a diagnostic.
+const sample = `package sample
+
+type T struct{ x int }
+
+func get(p *T) int {
+	return p.x
+}
+
+func use() int {
+	return get(nil)
+}
+`
+
+// doctor checks the environment the analyzer depends on and runs the
+// analyzer over a sample package, printing the problems found with how
+// to fix them. It returns the exit status.
+func doctor() int {
+	problems := 0
+	check := func(name string, err error, fix string) {
+		if err == nil {
+			fmt.Printf("ok      %s\n", name)
+			return
+		}
+		problems++
+		fmt.Printf("problem %s: %v\n        %s\n", name, err, fix)
+	}
+
+	version, err := goCommand("", "version")
+	check("go command: "+version, err, "install Go and make sure the go command is in PATH")
+	if err != nil {
+		return 1
+	}
+
+	export, err := goCommand("", "list", "-export", "-f", "{{.Export}}", "fmt")
+	if err == nil && export == "" {
+		err = fmt.Errorf("no export data for fmt")
+	}
+	check("export data", err, "make sure the standard library can be built, e.g. with go build std")
+
+	cache, err := goCommand("", "env", "GOCACHE")
+	if err == nil {
+		err = writable(cache)
+	}
+	check("build cache "+cache, err, "set GOCACHE to a writable directory")
+
+	err = runSample()
+	check("sample analysis", err, "rebuild nilarg with the same Go version as the go command")
+
+	if problems > 0 {
+		return 1
+	}
+	return 0
+}
+
+// goCommand runs the go command with args in dir and returns its
+// trimmed standard output.
+func goCommand(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// writable reports an error if no file can be created in dir.
+func writable(dir string) error {
+	if dir == "" || dir == "off" {
+		return fmt.Errorf("the build cache is disabled")
+	}
+	f, err := ioutil.TempFile(dir, "nilarg-doctor")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// runSample runs the analyzer over the sample package and reports an
+// error unless it reports the call passing nil.
+func runSample() error {
+	dir, err := ioutil.TempDir("", "nilarg-doctor")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module sample\n"), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(sample), 0644); err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(exe, "-json", ".")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var tree map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &tree); err != nil {
+		return err
+	}
+	for _, analyzers := range tree {
+		var diags []struct{ Message string }
+		if json.Unmarshal(analyzers[nilarg.Analyzer.Name], &diags) != nil {
+			continue
+		}
+		for _, d := range diags {
+			if strings.HasPrefix(d.Message, "this call can cause panic") {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("the call passing nil in the sample package was not reported")
+}
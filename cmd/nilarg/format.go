@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Matts966/nilarg"
+)
+
+// reportVersion is the version of the schema of the reports printed with
+// -format. It changes only when a field is removed or changes its
+// meaning; fields may be added within a version.
+const reportVersion = 1
+
+// report is the output of the analyzer in the schema of -format json.
+type report struct {
+	// Version is reportVersion.
+	Version int `json:"version"`
+	// Findings are sorted by file, line, column and message.
+	Findings []finding `json:"findings"`
+	// Errors are the packages which couldn't be analyzed.
+	Errors []packageError `json:"errors,omitempty"`
+}
+
+// finding is a diagnostic of the analyzer.
+type finding struct {
+	// Package is the ID of the package, which is its path followed
+	// by the test in brackets for test variants.
+	Package string `json:"package"`
+	// File is relative to the working directory if it is in it.
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	// Category is set by some flags such as -fingerprints.
+	Category string `json:"category,omitempty"`
+	Message  string `json:"message"`
+}
+
+// packageError is the error analyzing a package.
+type packageError struct {
+	Package string `json:"package"`
+	Error   string `json:"error"`
+}
+
+// formats are the renderers of reports by the names of -format.
+var formats = map[string]func(io.Writer, *report) error{
+	"text":     writeText,
+	"json":     writeJSON,
+	"sarif":    writeSARIF,
+	"github":   writeGitHub,
+	"markdown": writeMarkdown,
+	"html":     writeHTML,
+	"csv":      writeCSV,
+}
+
+// formatNames returns the names of formats, sorted.
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runFormat runs the analyzer over the packages given by args and prints
+// its report in format. It returns 1 for errors, 3 if anything was
+// found, as the driver does, and 0 otherwise.
+func runFormat(format string, args []string) int {
+	write, ok := formats[format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown format %q: must be one of %s\n", format, strings.Join(formatNames(), ", "))
+		return 2
+	}
+	for _, arg := range args {
+		if arg == "-json" || arg == "--json" {
+			fmt.Fprintln(os.Stderr, "-json can't be used with -format; use -format json")
+			return 2
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(exe, append([]string{"-json"}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %s\n", err, strings.TrimSpace(stderr.String()))
+		return 1
+	}
+	wd, _ := os.Getwd()
+	r, err := reportOf(stdout.Bytes(), wd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := write(os.Stdout, r); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(r.Errors) > 0 {
+		if format != "json" {
+			for _, e := range r.Errors {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", e.Package, e.Error)
+			}
+		}
+		return 1
+	}
+	if len(r.Findings) > 0 {
+		return 3
+	}
+	return 0
+}
+
+// reportOf converts the JSON output of the driver to a report, making
+// the files relative to the directory wd.
+func reportOf(data []byte, wd string) (*report, error) {
+	// The JSON tree maps package IDs to analyzer names to diagnostics,
+	// or to an error.
+	var tree map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	r := &report{Version: reportVersion, Findings: []finding{}}
+	for id, analyzers := range tree {
+		raw, ok := analyzers[nilarg.Analyzer.Name]
+		if !ok {
+			continue
+		}
+		var e struct{ Error string }
+		if json.Unmarshal(raw, &e) == nil && e.Error != "" {
+			r.Errors = append(r.Errors, packageError{id, e.Error})
+			continue
+		}
+		var diags []struct{ Category, Posn, Message string }
+		if err := json.Unmarshal(raw, &diags); err != nil {
+			return nil, fmt.Errorf("%s: %v", id, err)
+		}
+		for _, d := range diags {
+			file, line, col := splitPosn(d.Posn)
+			if rel, err := filepath.Rel(wd, file); err == nil && wd != "" && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+			r.Findings = append(r.Findings, finding{id, filepath.ToSlash(file), line, col, d.Category, d.Message})
+		}
+	}
+	sort.Slice(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Message != b.Message {
+			return a.Message < b.Message
+		}
+		return a.Package < b.Package
+	})
+	sort.Slice(r.Errors, func(i, j int) bool { return r.Errors[i].Package < r.Errors[j].Package })
+	return r, nil
+}
+
+// splitPosn splits the position posn formatted as file:line:column, or
+// file:line, into its parts.
+func splitPosn(posn string) (string, int, int) {
+	var nums []int
+	for len(nums) < 2 {
+		i := strings.LastIndex(posn, ":")
+		if i < 0 {
+			break
+		}
+		n, err := strconv.Atoi(posn[i+1:])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		posn = posn[:i]
+	}
+	switch len(nums) {
+	case 2:
+		return posn, nums[0], nums[1]
+	case 1:
+		return posn, nums[0], 0
+	}
+	return posn, 0, 0
+}
+
+// writeText writes the findings as the driver does, one per line.
+func writeText(w io.Writer, r *report) error {
+	for _, f := range r.Findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s\n", f.File, f.Line, f.Column, f.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the report itself.
+func writeJSON(w io.Writer, r *report) error {
+	return encodeJSON(w, r)
+}
+
+// encodeJSON writes v indented, without escaping HTML, so that messages
+// read as they are.
+func encodeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
+
+// The subset of SARIF 2.1.0 written by writeSARIF.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+	}
+	sarifResult struct {
+		RuleID     string            `json:"ruleId"`
+		Level      string            `json:"level"`
+		Message    sarifMessage      `json:"message"`
+		Locations  []sarifLocation   `json:"locations"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+// writeSARIF writes the findings as a SARIF log for code scanning, with
+// the files as URIs relative to the working directory, or file URIs, and
+// the categories as properties.
+func writeSARIF(w io.Writer, r *report) error {
+	run := sarifRun{
+		Tool:    sarifTool{sarifDriver{"nilarg", "https://github.com/Matts966/nilarg"}},
+		Results: []sarifResult{},
+	}
+	for _, f := range r.Findings {
+		uri := &url.URL{Path: f.File}
+		if strings.HasPrefix(f.File, "/") {
+			uri.Scheme = "file"
+		}
+		res := sarifResult{
+			RuleID:  nilarg.Analyzer.Name,
+			Level:   "warning",
+			Message: sarifMessage{f.Message},
+			Locations: []sarifLocation{{sarifPhysicalLocation{
+				sarifArtifactLocation{uri.String()},
+				sarifRegion{f.Line, f.Column},
+			}}},
+		}
+		if f.Category != "" {
+			res.Properties = map[string]string{"category": f.Category}
+		}
+		run.Results = append(run.Results, res)
+	}
+	return encodeJSON(w, sarifLog{"2.1.0", "https://json.schemastore.org/sarif-2.1.0.json", []sarifRun{run}})
+}
+
+// githubEscaper escapes the data of GitHub Actions workflow commands,
+// and githubPropertyEscaper their properties.
+var (
+	githubEscaper         = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// writeGitHub writes the findings as warning commands of GitHub Actions,
+// which annotate the lines of pull requests.
+func writeGitHub(w io.Writer, r *report) error {
+	for _, f := range r.Findings {
+		if _, err := fmt.Fprintf(w, "::warning file=%s,line=%d,col=%d,title=nilarg::%s\n",
+			githubPropertyEscaper.Replace(f.File), f.Line, f.Column, githubEscaper.Replace(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownEscaper escapes the text in cells of Markdown tables.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "`", "\\`")
+
+// writeMarkdown writes the findings as a Markdown table, such as for
+// comments on pull requests.
+func writeMarkdown(w io.Writer, r *report) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## nilarg\n\n")
+	if len(r.Findings) == 0 {
+		fmt.Fprintf(&buf, "No findings.\n")
+	} else {
+		fmt.Fprintf(&buf, "| Location | Message |\n| --- | --- |\n")
+		for _, f := range r.Findings {
+			fmt.Fprintf(&buf, "| `%s:%d:%d` | %s |\n", markdownEscaper.Replace(f.File), f.Line, f.Column, markdownEscaper.Replace(f.Message))
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var htmlReport = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>nilarg</title>
+</head>
+<body>
+<h1>nilarg</h1>
+{{if .Findings}}<table>
+<tr><th>Location</th><th>Message</th></tr>
+{{range .Findings}}<tr><td>{{.File}}:{{.Line}}:{{.Column}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{else}}<p>No findings.</p>
+{{end}}</body>
+</html>
+`))
+
+// writeHTML writes the findings as a standalone HTML page.
+func writeHTML(w io.Writer, r *report) error {
+	return htmlReport.Execute(w, r)
+}
+
+// writeCSV writes the findings as CSV with a header, one per record.
+func writeCSV(w io.Writer, r *report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"package", "file", "line", "column", "category", "message"})
+	for _, f := range r.Findings {
+		cw.Write([]string{f.Package, f.File, strconv.Itoa(f.Line), strconv.Itoa(f.Column), f.Category, f.Message})
+	}
+	cw.Flush()
+	return cw.Error()
+}
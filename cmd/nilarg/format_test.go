@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestFormats pins the output of each format for the same driver output
+// to golden files, which must only change compatibly within a version of
+// the report.
+func TestFormats(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "format", "driver.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := reportOf(data, "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := &report{Version: reportVersion, Findings: []finding{}}
+	for _, name := range formatNames() {
+		for suffix, r := range map[string]*report{"": r, "-empty": empty} {
+			var buf bytes.Buffer
+			if err := formats[name](&buf, r); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			golden := filepath.Join("testdata", "format", name+suffix+".golden")
+			if *update {
+				if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("%s%s output differs from %s:\n%s", name, suffix, golden, buf.Bytes())
+			}
+		}
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	r, err := reportOf([]byte(`{"example.com/c": {"nilarg": {"error": "boom"}}}`), "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Findings) != 0 || len(r.Errors) != 1 || r.Errors[0] != (packageError{"example.com/c", "boom"}) {
+		t.Errorf("reportOf = %+v; want the error of example.com/c only", r)
+	}
+}
+
+func TestSplitPosn(t *testing.T) {
+	for posn, want := range map[string]finding{
+		"a.go:3:4":      {File: "a.go", Line: 3, Column: 4},
+		"a.go:3":        {File: "a.go", Line: 3},
+		`C:\x\a.go:1:2`: {File: `C:\x\a.go`, Line: 1, Column: 2},
+		"a.go":          {File: "a.go"},
+	} {
+		file, line, col := splitPosn(posn)
+		if got := (finding{File: file, Line: line, Column: col}); got != want {
+			t.Errorf("splitPosn(%q) = %+v; want %+v", posn, got, want)
+		}
+	}
+}
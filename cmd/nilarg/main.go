@@ -1,8 +1,214 @@
+// The nilarg command runs the nilarg analyzer.
+//
+// With -fix-defs, it rewrites the flagged exported functions to begin
+// with guard clauses instead of reporting them, then formats the
+// rewritten files and removes duplicated imports.
+//
+// The packages are given by the patterns of the go command such as
+// ./..., std or module paths, and default to the package in the current
+// directory. With -run, only the diagnostics in the functions matching
+// the regular expression are reported. With -completion bash, zsh or
+// fish, it prints the completion script for the shell.
+//
+// With -fix -dry-run, it prints the suggested fixes as unified diffs
+// instead of applying them, so that they can be reviewed first.
+//
+// With -format, it prints the diagnostics in one of the formats text,
+// json, sarif, github, markdown, html or csv. The json format has a
+// versioned schema, which the other formats are derived from, so that
+// tools can rely on the output across releases.
+//
+// nilarg doctor checks the environment the analyzer depends on, runs
+// the analyzer over a sample package and prints the problems found.
+//
+// nilarg triage [trace] [packages] reads a panic stack trace from the
+// file, or the standard input if it is - or missing, and points at the
+// frames of the functions in the packages which panic on nil arguments.
+//
+// nilarg changelog [-json] old new [packages] compares the nil contracts
+// of the exported API of two versions of a module, each a directory or a
+// module@version, and prints the contracts added and removed. It exits
+// with 3 when contracts were added.
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
 	"github.com/Matts966/nilarg"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
-func main() { singlechecker.Main(nilarg.Analyzer) }
+func main() {
+	if len(os.Args) == 2 && os.Args[1] == "doctor" {
+		os.Exit(doctor())
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "triage" {
+		os.Exit(triage(os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "changelog" {
+		os.Exit(changelogCmd(os.Args[2:]))
+	}
+	os.Args = append(os.Args[:1], dryRunArgs(os.Args[1:])...)
+	for i, arg := range os.Args[1:] {
+		if arg == "-fix-defs" || arg == "--fix-defs" {
+			args := append(os.Args[1:i+1:i+1], os.Args[i+2:]...)
+			os.Exit(fixDefs(withDefaultPattern(args)))
+		}
+		if arg == "-format" || arg == "--format" {
+			if i+2 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "-format requires one of %s\n", strings.Join(formatNames(), ", "))
+				os.Exit(2)
+			}
+			args := append(os.Args[1:i+1:i+1], os.Args[i+3:]...)
+			os.Exit(runFormat(os.Args[i+2], withDefaultPattern(args)))
+		}
+		if strings.HasPrefix(arg, "-format=") || strings.HasPrefix(arg, "--format=") {
+			args := append(os.Args[1:i+1:i+1], os.Args[i+2:]...)
+			os.Exit(runFormat(arg[strings.Index(arg, "=")+1:], withDefaultPattern(args)))
+		}
+		if arg == "-completion" || arg == "--completion" {
+			if i+2 >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "-completion requires a shell: bash, zsh or fish")
+				os.Exit(2)
+			}
+			if err := writeCompletion(os.Stdout, os.Args[i+2]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			return
+		}
+	}
+	os.Args = append(os.Args[:1], withDefaultPattern(os.Args[1:])...)
+	singlechecker.Main(nilarg.Analyzer)
+}
+
+// dryRunArgs returns args without -fix if they have -dry-run, so that
+// the analyzer prints the fixes as diffs while the driver leaves the
+// files alone.
+func dryRunArgs(args []string) []string {
+	dry := false
+	for _, arg := range args {
+		if arg == "-dry-run" || arg == "--dry-run" || arg == "-dry-run=true" || arg == "--dry-run=true" {
+			dry = true
+		}
+	}
+	if !dry {
+		return args
+	}
+	var kept []string
+	for _, arg := range args {
+		if arg == "-fix" || arg == "--fix" || arg == "-fix=true" || arg == "--fix=true" {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
+
+// fixDefs runs the analyzer again applying the guard fixes to the
+// packages given by args, and formats the files it changed.
+func fixDefs(args []string) int {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(exe, append([]string{"-guards", "-fix", "-json"}, args...)...)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// The JSON output of the driver doesn't set the exit status
+		// for diagnostics, so any error is a failure.
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// The JSON tree maps package IDs to analyzer names to diagnostics.
+	var tree map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &tree); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	files := make(map[string]bool)
+	for _, analyzers := range tree {
+		var diags []struct{ Posn string }
+		if json.Unmarshal(analyzers[nilarg.Analyzer.Name], &diags) != nil {
+			continue
+		}
+		for _, d := range diags {
+			files[filename(d.Posn)] = true
+		}
+	}
+
+	exit := 0
+	for file := range files {
+		if err := formatFile(file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit = 1
+			continue
+		}
+		fmt.Println(file)
+	}
+	return exit
+}
+
+// filename returns the file name of the position posn formatted as
+// file:line:column.
+func filename(posn string) string {
+	for i := 0; i < 2; i++ {
+		if j := strings.LastIndex(posn, ":"); j >= 0 {
+			posn = posn[:j]
+		}
+	}
+	return posn
+}
+
+// formatFile removes duplicated imports from file, which the guard
+// fixes of several functions can add, and formats it.
+func formatFile(file string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	decls := f.Decls[:0]
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		specs := gen.Specs[:0]
+		for _, spec := range gen.Specs {
+			path, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+			if seen[path] && spec.(*ast.ImportSpec).Name == nil {
+				continue
+			}
+			seen[path] = true
+			specs = append(specs, spec)
+		}
+		gen.Specs = specs
+		if len(specs) > 0 {
+			decls = append(decls, decl)
+		}
+	}
+	f.Decls = decls
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, buf.Bytes(), 0644)
+}
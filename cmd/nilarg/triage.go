@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Matts966/nilarg"
+)
+
+// frame is a frame of a goroutine stack trace.
+type frame struct {
+	// Func is the function of the frame as printed in the trace, e.g.
+	// example.com/pkg.(*T).M.
+	Func string
+	File string
+	Line int
+}
+
+// parseTrace returns the frames of the first goroutine in the stack
+// trace read from r, innermost first, which is the panicking one.
+func parseTrace(r io.Reader) ([]frame, error) {
+	var frames []frame
+	sc := bufio.NewScanner(r)
+	inStack := false
+	fn := ""
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			if inStack {
+				return frames, nil
+			}
+			inStack = true
+		case !inStack:
+		case strings.TrimSpace(line) == "":
+			if len(frames) > 0 {
+				return frames, nil
+			}
+		case strings.HasPrefix(line, "\t"):
+			if fn == "" {
+				continue
+			}
+			loc := strings.Fields(line)[0]
+			i := strings.LastIndex(loc, ":")
+			if i < 0 {
+				return nil, fmt.Errorf("malformed location %q", loc)
+			}
+			n, err := strconv.Atoi(loc[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("malformed location %q", loc)
+			}
+			frames = append(frames, frame{fn, loc[:i], n})
+			fn = ""
+		case strings.HasPrefix(line, "created by "):
+			// The go statement starting the goroutine isn't
+			// on the stack.
+			fn = ""
+		default:
+			fn = line
+			if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+				fn = line[:i]
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no goroutine stack in the trace")
+	}
+	return frames, nil
+}
+
+// contractKey returns the package path and the name, as in -run, of the
+// function of a frame, such as example.com/pkg and T.M for
+// example.com/pkg.(*T).M.
+func contractKey(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	dot := strings.Index(fn[slash+1:], ".")
+	if dot < 0 {
+		return fn
+	}
+	pkg, name := fn[:slash+1+dot], fn[slash+2+dot:]
+	name = strings.Replace(name, "(*", "", 1)
+	name = strings.Replace(name, ")", "", 1)
+	return pkg + "." + name
+}
+
+// contract is a parameter of a function which must not be nil.
+type contract struct {
+	Param, Text string
+}
+
+// contractsOf runs the analyzer with -contracts in the directory dir, or
+// the current one if it is "", over the packages given by args and
+// returns the contracts keyed by the package path and the name of the
+// functions.
+func contractsOf(dir string, args []string) (map[string][]contract, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(exe, append([]string{"-contracts", "-json"}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	// The JSON tree maps package IDs to analyzer names to diagnostics.
+	var tree map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &tree); err != nil {
+		return nil, err
+	}
+	// Traces name the functions of main packages main.F.
+	mains := make(map[string]bool)
+	if out, err := goCommand(dir, append([]string{"list", "-f", `{{if eq .Name "main"}}{{.ImportPath}}{{end}}`}, args...)...); err == nil {
+		for _, path := range strings.Fields(out) {
+			mains[path] = true
+		}
+	}
+	contracts := make(map[string][]contract)
+	for id, analyzers := range tree {
+		var diags []struct{ Message string }
+		if json.Unmarshal(analyzers[nilarg.Analyzer.Name], &diags) != nil {
+			continue
+		}
+		// The IDs of test variants are followed by the test in
+		// brackets.
+		pkg := strings.Fields(id)[0]
+		if mains[pkg] {
+			pkg = "main"
+		}
+		for _, d := range diags {
+			msg := strings.TrimPrefix(d.Message, "callers of ")
+			parts := strings.SplitN(msg, " can cause panic by passing nil ", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			param := strings.SplitN(parts[1], ": ", 2)
+			if len(param) != 2 {
+				continue
+			}
+			key := pkg + "." + parts[0]
+			c := contract{param[0], param[1]}
+			if !containsContract(contracts[key], c) {
+				contracts[key] = append(contracts[key], c)
+			}
+		}
+	}
+	return contracts, nil
+}
+
+func containsContract(cs []contract, c contract) bool {
+	for _, d := range cs {
+		if d == c {
+			return true
+		}
+	}
+	return false
+}
+
+// triage reads a panic stack trace from the file given by args[0], or
+// the standard input if it is "-", and prints the frames of the
+// functions panicking on nil arguments in the packages given by the rest
+// of args. The innermost frame whose function panics on nil at the line
+// of the frame is the most likely one, and its caller is where the nil
+// argument comes from. It returns the exit status.
+func triage(args []string) int {
+	in := io.Reader(os.Stdin)
+	if len(args) > 0 {
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			defer f.Close()
+			in = f
+		}
+		args = args[1:]
+	}
+	frames, err := parseTrace(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	contracts, err := contractsOf("", withDefaultPattern(args))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	likelyFrame, likelyContract := -1, -1
+	for i, fr := range frames {
+		for k, c := range contracts[contractKey(fr.Func)] {
+			if likelyFrame < 0 && strings.HasSuffix(c.Text, fmt.Sprintf(" at %s:%d", filepath.Base(fr.File), fr.Line)) {
+				likelyFrame, likelyContract = i, k
+			}
+		}
+	}
+	found := false
+	for i, fr := range frames {
+		for k, c := range contracts[contractKey(fr.Func)] {
+			found = true
+			if i != likelyFrame || k != likelyContract {
+				fmt.Printf("also:        %s:%d: %s panics on nil %s: %s\n", fr.File, fr.Line, fr.Func, c.Param, c.Text)
+				continue
+			}
+			fmt.Printf("most likely: %s:%d: %s panics on nil %s: %s\n", fr.File, fr.Line, fr.Func, c.Param, c.Text)
+			if i+1 < len(frames) {
+				caller := frames[i+1]
+				fmt.Printf("             nil %s is passed by %s at %s:%d\n", c.Param, caller.Func, caller.File, caller.Line)
+			}
+		}
+	}
+	if !found {
+		fmt.Println("no frame of the trace is in a function which panics on nil arguments")
+		return 1
+	}
+	return 0
+}
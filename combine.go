@@ -0,0 +1,81 @@
+package nilarg
+
+import (
+	"go/token"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// combine makes the analyzer report the diagnostics at the same position
+// as one, so that a call which can panic for several reasons is reported
+// once.
+var combine bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&combine, "combine", false,
+		"report the diagnostics at the same position as one diagnostic")
+}
+
+// held maps the passes being run to the diagnostics held back for
+// combining, in the order they were found.
+var held = struct {
+	sync.Mutex
+	m map[*analysis.Pass][]analysis.Diagnostic
+}{m: make(map[*analysis.Pass][]analysis.Diagnostic)}
+
+// holdDiag holds d back until flushDiags, merging it into the diagnostic
+// already held at its position, if any.
+func holdDiag(pass *analysis.Pass, d analysis.Diagnostic) {
+	held.Lock()
+	defer held.Unlock()
+	ds := held.m[pass]
+	for i := range ds {
+		if ds[i].Pos == d.Pos {
+			ds[i].Message = combineMessages(ds[i].Message, d.Message)
+			ds[i].SuggestedFixes = append(ds[i].SuggestedFixes, d.SuggestedFixes...)
+			return
+		}
+	}
+	held.m[pass] = append(ds, d)
+}
+
+// flushDiags emits and forgets the diagnostics held back for pass.
+func flushDiags(pass *analysis.Pass) {
+	held.Lock()
+	ds := held.m[pass]
+	delete(held.m, pass)
+	held.Unlock()
+	for _, d := range ds {
+		emitDiag(pass, d)
+	}
+}
+
+// combineMessages appends the message b to a, dropping the lead of b
+// before the first colon when a has the same one, as in
+//
+//	this call can cause panic: p is dereferenced in f; no option sets T.log
+func combineMessages(a, b string) string {
+	if i := strings.Index(b, ": "); i >= 0 && strings.HasPrefix(a, b[:i+2]) {
+		b = b[i+2:]
+	}
+	for _, m := range strings.Split(a, "; ") {
+		if m == b || strings.HasSuffix(m, ": "+b) {
+			return a
+		}
+	}
+	return a + "; " + b
+}
+
+// Covers reports whether a finding of the package is at posn, so that
+// companion analyzers requiring Analyzer can skip the positions it has
+// already reported.
+func (r *Result) Covers(posn token.Position) bool {
+	for _, f := range r.Findings {
+		if f.Posn.Filename == posn.Filename && f.Posn.Line == posn.Line && f.Posn.Column == posn.Column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// commaOk returns the value v of a comma-ok expression, such as a type
+// assertion, a map lookup or a channel receive, if b ends with a branch on
+// its ok, and the nilness of v in each successor of b. A failed comma-ok
+// expression yields the zero value, so v is nil when ok is false, as in
+//
+//	v, ok := m[k]
+//	if !ok {
+//		v.Close() // v is nil
+//	}
+//
+// and a successful assertion to an interface type yields a non-nil
+// interface, while the values of other types may still be nil.
+func commaOk(b *ssa.BasicBlock) (v ssa.Value, tsucc, fsucc *ssa.BasicBlock, onTrue, onFalse nilness) {
+	If, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If)
+	if !ok {
+		return
+	}
+	okv, ok := If.Cond.(*ssa.Extract)
+	if !ok || okv.Index != 1 || !isCommaOk(okv.Tuple) || okv.Tuple.Referrers() == nil {
+		return
+	}
+	for _, r := range *okv.Tuple.Referrers() {
+		if e, ok := r.(*ssa.Extract); ok && e.Index == 0 {
+			v = e
+		}
+	}
+	if v == nil {
+		return
+	}
+	onTrue, onFalse = unknown, unknown
+	if isNillable(v.Type()) || isFunc(v.Type()) || isChan(v.Type()) {
+		onFalse = isnil
+	}
+	if ta, ok := okv.Tuple.(*ssa.TypeAssert); ok && types.IsInterface(ta.AssertedType) {
+		onTrue = isnonnil
+	}
+	return v, b.Succs[0], b.Succs[1], onTrue, onFalse
+}
+
+// isCommaOk reports whether v is the tuple of a comma-ok expression.
+func isCommaOk(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.TypeAssert:
+		return v.CommaOk
+	case *ssa.Lookup:
+		return v.CommaOk
+	case *ssa.UnOp:
+		return v.Op == token.ARROW && v.CommaOk
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// condition is a comparison of an integer parameter, or of the length
+// of a parameter if Len is set, with a constant, as in n > 0 or
+// len(src) != 0.
+type condition struct {
+	Param int
+	Len   bool
+	Op    token.Token
+	Value int64
+}
+
+func (c condition) String() string {
+	if c.Len {
+		return fmt.Sprintf("len(%d)%s%d", c.Param, c.Op, c.Value)
+	}
+	return fmt.Sprintf("%d%s%d", c.Param, c.Op, c.Value)
+}
+
+// describe describes the condition on the parameters of fn.
+func (c condition) describe(fn *ssa.Function) string {
+	if c.Len {
+		return fmt.Sprintf("len(%s) %s %d", fn.Params[c.Param].Name(), c.Op, c.Value)
+	}
+	return fmt.Sprintf("%s %s %d", fn.Params[c.Param].Name(), c.Op, c.Value)
+}
+
+// holds reports whether the condition holds for the argument x.
+func (c condition) holds(x int64) bool {
+	return constant.Compare(constant.MakeInt64(x), c.Op, constant.MakeInt64(c.Value))
+}
+
+// conditionalArgs has the indices of the arguments which cause panic
+// when they are nil and any of the conditions holds, as in
+//
+//	func fill(p *[8]int, n int) {
+//		for i := 0; i < n; i++ {
+//			p[i] = i
+//		}
+//	}
+//
+// which only panics with nil p if n > 0, or
+//
+//	func encode(dst, src []byte) {
+//		for i := range src {
+//			dst[i] = src[i]
+//		}
+//	}
+//
+// which only panics with nil dst if len(src) > 0.
+type conditionalArgs map[int][]condition
+
+func (*conditionalArgs) AFact() {}
+
+func (a *conditionalArgs) String() string {
+	var args []string
+	for i, conds := range *a {
+		args = append(args, fmt.Sprintf("%d:%v", i, conds))
+	}
+	sort.Strings(args)
+	return "conditionalArgs[" + strings.Join(args, " ") + "]"
+}
+
+// complements reports whether the i-th argument already causes panic
+// under the negation of c, so that it causes panic whenever it's nil.
+func (a conditionalArgs) complements(i int, c condition) bool {
+	for _, d := range a[i] {
+		if d.Param == c.Param && d.Len == c.Len && d.Value == c.Value && d.Op == negateOp[c.Op] {
+			return true
+		}
+	}
+	return false
+}
+
+// panicCondition returns the condition on the parameters of fn of the
+// innermost branch dominating the block b, if b is only reached when
+// an integer parameter, or the length of a parameter, compares to a
+// constant.
+func panicCondition(fn *ssa.Function, b *ssa.BasicBlock) (condition, bool) {
+	var c condition
+	ok := dominatingBranches(b, func(If *ssa.If, succ int) bool {
+		binop, ok := If.Cond.(*ssa.BinOp)
+		if !ok {
+			return false
+		}
+		if c, ok = compareParam(fn, binop); !ok {
+			return false
+		}
+		if succ == 1 {
+			c.Op = negateOp[c.Op]
+		}
+		return true
+	})
+	return c, ok
+}
+
+// compareParam returns binop as a condition on a parameter of fn. The
+// induction variable of a loop starting from a constant stands for the
+// constant, since the first iteration compares it.
+func compareParam(fn *ssa.Function, binop *ssa.BinOp) (condition, bool) {
+	if _, ok := negateOp[binop.Op]; !ok {
+		return condition{}, false
+	}
+	x, y, op := binop.X, binop.Y, binop.Op
+	if _, _, ok := paramOf(y); !ok {
+		x, y, op = y, x, mirrorOp[op]
+	}
+	p, isLen, ok := paramOf(y)
+	if !ok {
+		return condition{}, false
+	}
+	val, ok := initialConst(x)
+	if !ok {
+		return condition{}, false
+	}
+	for k, fp := range fn.Params {
+		if fp == p {
+			// x op p is p mirrorOp[op] x.
+			return condition{k, isLen, mirrorOp[op], val}, true
+		}
+	}
+	return condition{}, false
+}
+
+// paramOf returns the parameter of which v is the value, if v is an
+// integer parameter, or the length, if v is len(p) for a parameter p.
+func paramOf(v ssa.Value) (p *ssa.Parameter, isLen bool, ok bool) {
+	if call, ok := v.(*ssa.Call); ok {
+		if b, ok := call.Call.Value.(*ssa.Builtin); ok && b.Name() == "len" {
+			p, ok := call.Call.Args[0].(*ssa.Parameter)
+			return p, true, ok
+		}
+		return nil, false, false
+	}
+	p, ok = v.(*ssa.Parameter)
+	if !ok {
+		return nil, false, false
+	}
+	if b, ok := p.Type().Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+		return nil, false, false
+	}
+	return p, false, true
+}
+
+// initialConst returns the value of the integer constant v, or of the
+// constant which the phi v takes on entering a loop, plus the constant
+// added to the phi in v, if any, as for the index of a range loop.
+func initialConst(v ssa.Value) (int64, bool) {
+	if binop, ok := v.(*ssa.BinOp); ok && binop.Op == token.ADD {
+		if _, ok := binop.X.(*ssa.Phi); !ok {
+			return 0, false
+		}
+		x, ok := initialConst(binop.X)
+		y, ok2 := intConst(binop.Y)
+		return x + y, ok && ok2
+	}
+	if phi, ok := v.(*ssa.Phi); ok {
+		for k, e := range phi.Edges {
+			if phi.Block().Preds[k].Dominates(phi.Block()) {
+				if x, ok := intConst(e); ok {
+					return x, true
+				}
+			}
+		}
+		return 0, false
+	}
+	return intConst(v)
+}
+
+// intConst returns the value of v if v is an integer constant.
+func intConst(v ssa.Value) (int64, bool) {
+	c, ok := v.(*ssa.Const)
+	if !ok || c.Value == nil || c.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(c.Value)
+}
+
+// argLen returns the length of the argument v if it is known, that is,
+// v is nil, a constant string, or a slice of an array made for a slice
+// literal or make with a constant length.
+func argLen(v ssa.Value) (int64, bool) {
+	switch v := v.(type) {
+	case *ssa.Const:
+		if v.IsNil() {
+			return 0, true
+		}
+		if v.Value != nil && v.Value.Kind() == constant.String {
+			return int64(len(constant.StringVal(v.Value))), true
+		}
+	case *ssa.MakeSlice:
+		return intConst(v.Len)
+	case *ssa.Slice:
+		if _, ok := v.X.(*ssa.Alloc); !ok || v.Low != nil {
+			return 0, false
+		}
+		if v.High != nil {
+			return intConst(v.High)
+		}
+		if a, ok := v.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array); ok {
+			return a.Len(), true
+		}
+	}
+	return 0, false
+}
+
+var negateOp = map[token.Token]token.Token{
+	token.EQL: token.NEQ, token.NEQ: token.EQL,
+	token.LSS: token.GEQ, token.GEQ: token.LSS,
+	token.GTR: token.LEQ, token.LEQ: token.GTR,
+}
+
+var mirrorOp = map[token.Token]token.Token{
+	token.EQL: token.EQL, token.NEQ: token.NEQ,
+	token.LSS: token.GTR, token.GTR: token.LSS,
+	token.LEQ: token.GEQ, token.GEQ: token.LEQ,
+}
+
+// positiveLen returns x if binop compares len(x) of a map or a slice x
+// with a constant so that the result being holds means len(x) > 0, as in
+// len(x) != 0, and so x isn't nil.
+func positiveLen(binop *ssa.BinOp, holds bool) ssa.Value {
+	if _, ok := negateOp[binop.Op]; !ok {
+		return nil
+	}
+	x, y, op := binop.X, binop.Y, binop.Op
+	if _, ok := x.(*ssa.Const); ok {
+		x, y, op = y, x, mirrorOp[op]
+	}
+	call, ok := x.(*ssa.Call)
+	if !ok {
+		return nil
+	}
+	if b, ok := call.Call.Value.(*ssa.Builtin); !ok || b.Name() != "len" || !isNillable(call.Call.Args[0].Type()) {
+		return nil
+	}
+	c, ok := intConst(y)
+	if !ok {
+		return nil
+	}
+	if !holds {
+		op = negateOp[op]
+	}
+	if constant.Compare(constant.MakeInt64(0), op, constant.MakeInt64(c)) {
+		return nil
+	}
+	return call.Call.Args[0]
+}
+
+// lenGuard returns x and the successor of b where x isn't nil if b ends
+// with a branch on positiveLen of x.
+func lenGuard(b *ssa.BasicBlock) (ssa.Value, *ssa.BasicBlock) {
+	If, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If)
+	if !ok {
+		return nil, nil
+	}
+	binop, ok := If.Cond.(*ssa.BinOp)
+	if !ok {
+		return nil, nil
+	}
+	for k, succ := range b.Succs {
+		if x := positiveLen(binop, k == 0); x != nil {
+			return x, succ
+		}
+	}
+	return nil, nil
+}
+
+// checkConditional reports the call c if it passes nil for an argument
+// of a conditionalArgs fact of the callee with a constant, or a value of
+// a known length, satisfying one of the conditions.
+func checkConditional(pass *analysis.Pass, c *ssa.Call, stack []fact) {
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return
+	}
+	var fact conditionalArgs
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return
+	}
+	// Report the first argument satisfying a condition, in the order of
+	// the parameters.
+	idx := make([]int, 0, len(fact))
+	for i := range fact {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	for _, i := range idx {
+		if i >= len(c.Call.Args) || nilnessOf(pass, stack, c.Call.Args[i]) != isnil || argSuppressed(pass, c, i) {
+			continue
+		}
+		for _, cond := range fact[i] {
+			if cond.Param >= len(c.Call.Args) {
+				continue
+			}
+			x, ok := intConst(c.Call.Args[cond.Param])
+			if cond.Len {
+				x, ok = argLen(c.Call.Args[cond.Param])
+			}
+			if ok && cond.holds(x) {
+				report(pass, c.Pos(), "this call can cause panic because %s", cond.describe(s))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package nilarg
+
+import (
+	"sync"
+
+	"github.com/Matts966/nilarg/internal/dom"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ssaGraph is the control-flow graph of the blocks of an SSA function,
+// numbered by their indices. The edges leaving the blocks which exit,
+// such as by calling os.Exit, are left out, since they are never taken.
+type ssaGraph struct {
+	blocks []*ssa.BasicBlock
+	exits  map[int]bool
+}
+
+// graphOf returns the control-flow graph of fn.
+func graphOf(fn *ssa.Function) ssaGraph {
+	g := ssaGraph{blocks: fn.Blocks}
+	for _, b := range fn.Blocks {
+		if exits(b) {
+			if g.exits == nil {
+				g.exits = make(map[int]bool)
+			}
+			g.exits[b.Index] = true
+		}
+	}
+	return g
+}
+
+func (g ssaGraph) Len() int { return len(g.blocks) }
+
+func (g ssaGraph) Succs(b int) []int {
+	if g.exits[b] {
+		return nil
+	}
+	return blockIndices(g.blocks[b].Succs)
+}
+
+func (g ssaGraph) Preds(b int) []int {
+	var idx []int
+	for _, p := range g.blocks[b].Preds {
+		if !g.exits[p.Index] {
+			idx = append(idx, p.Index)
+		}
+	}
+	return idx
+}
+
+func blockIndices(bs []*ssa.BasicBlock) []int {
+	idx := make([]int, len(bs))
+	for k, b := range bs {
+		idx[k] = b.Index
+	}
+	return idx
+}
+
+// domTree returns the dominator tree of the blocks of fn in g, which the
+// SSA builder has already computed unless blocks of fn exit. The recover
+// block and the blocks only reached from it are left unreachable,
+// because they are only entered by panics.
+func domTree(fn *ssa.Function, g ssaGraph) dom.Tree {
+	if len(g.exits) > 0 {
+		return dom.Idoms(g)
+	}
+	t := make(dom.Tree, len(fn.Blocks))
+	for _, b := range fn.Blocks {
+		t[b.Index] = -1
+		if b.Idom() != nil {
+			t[b.Index] = b.Idom().Index
+		}
+	}
+	return t
+}
+
+// fnGraph is the graph of a function with its dominator tree.
+type fnGraph struct {
+	g ssaGraph
+	t dom.Tree
+}
+
+// graphs caches the graphs of the functions of the packages being
+// analyzed, since the dominance of many blocks of each is queried.
+var graphs = struct {
+	sync.Mutex
+	m map[*ssa.Package]map[*ssa.Function]fnGraph
+}{m: make(map[*ssa.Package]map[*ssa.Function]fnGraph)}
+
+// cachedGraph returns the graph of fn and its dominator tree, computing
+// them once for the functions of packages.
+func cachedGraph(fn *ssa.Function) (ssaGraph, dom.Tree) {
+	if fn.Pkg == nil {
+		g := graphOf(fn)
+		return g, domTree(fn, g)
+	}
+	graphs.Lock()
+	c, ok := graphs.m[fn.Pkg][fn]
+	graphs.Unlock()
+	if ok {
+		return c.g, c.t
+	}
+	g := graphOf(fn)
+	c = fnGraph{g, domTree(fn, g)}
+	graphs.Lock()
+	defer graphs.Unlock()
+	if graphs.m[fn.Pkg] == nil {
+		graphs.m[fn.Pkg] = make(map[*ssa.Function]fnGraph)
+	}
+	graphs.m[fn.Pkg][fn] = c
+	return c.g, c.t
+}
+
+// forgetGraphs forgets the graphs of the functions of pkg.
+func forgetGraphs(pkg *ssa.Package) {
+	graphs.Lock()
+	defer graphs.Unlock()
+	delete(graphs.m, pkg)
+}
+
+// dominatingBranches calls f for the branches dominating the block b,
+// that is, for the if statements whose successor succ is only left
+// towards b, from the innermost, until f returns true, and reports
+// whether it did. succ is 0 for the then branch and 1 for the else
+// branch.
+func dominatingBranches(b *ssa.BasicBlock, f func(If *ssa.If, succ int) bool) bool {
+	fn := b.Parent()
+	g, t := cachedGraph(fn)
+	return t.Guards(g, b.Index, func(from, to int) bool {
+		bi := fn.Blocks[from]
+		If, ok := bi.Instrs[len(bi.Instrs)-1].(*ssa.If)
+		if !ok {
+			return false
+		}
+		for k, s := range bi.Succs {
+			if s.Index == to {
+				return f(If, k)
+			}
+		}
+		return false
+	})
+}
+
+// edgeDominates reports whether the edge from the block from to its
+// successor to dominates the block b.
+func edgeDominates(from, to, b *ssa.BasicBlock) bool {
+	g, t := cachedGraph(b.Parent())
+	return t.EdgeDominates(g, from.Index, to.Index, b.Index)
+}
@@ -0,0 +1,107 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Matts966/nilarg/internal/diff"
+	"golang.org/x/tools/go/analysis"
+)
+
+// dryRun prints the suggested fixes as diffs instead of leaving them to
+// the driver.
+var dryRun bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&dryRun, "dry-run", false,
+		"print the suggested fixes of each package as unified diffs to stdout without applying them, as the nilarg command does for -fix -dry-run")
+}
+
+// dryRunFixes maps the passes being run to the suggested fixes of their
+// diagnostics, and dryRunMu serializes the diffs of packages analyzed in
+// parallel.
+var (
+	dryRunFixes = struct {
+		sync.Mutex
+		m map[*analysis.Pass][]analysis.SuggestedFix
+	}{m: make(map[*analysis.Pass][]analysis.SuggestedFix)}
+	dryRunMu sync.Mutex
+)
+
+// holdFix records the suggested fixes of d, all of which the driver
+// applies with -fix.
+func holdFix(pass *analysis.Pass, d analysis.Diagnostic) {
+	if !dryRun || len(d.SuggestedFixes) == 0 {
+		return
+	}
+	dryRunFixes.Lock()
+	defer dryRunFixes.Unlock()
+	dryRunFixes.m[pass] = append(dryRunFixes.m[pass], d.SuggestedFixes...)
+}
+
+// printFixes prints the diffs applying the fixes held for pass to the
+// files of its package, skipping the edits overlapping earlier ones as
+// drivers do, and forgets them.
+func printFixes(pass *analysis.Pass) {
+	dryRunFixes.Lock()
+	fixes := dryRunFixes.m[pass]
+	delete(dryRunFixes.m, pass)
+	dryRunFixes.Unlock()
+	edits := make(map[string][]analysis.TextEdit)
+	for _, fix := range fixes {
+		for _, e := range fix.TextEdits {
+			if f := pass.Fset.File(e.Pos); f != nil && e.Pos <= e.End {
+				edits[f.Name()] = append(edits[f.Name()], e)
+			}
+		}
+	}
+	files := make([]string, 0, len(edits))
+	for name := range edits {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	wd, _ := os.Getwd()
+	for _, name := range files {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		rel := name
+		if r, err := filepath.Rel(wd, name); err == nil && wd != "" {
+			rel = r
+		}
+		rel = filepath.ToSlash(rel)
+		d := diff.Unified("a/"+rel, "b/"+rel, string(data), applyEdits(pass.Fset.File(edits[name][0].Pos), data, edits[name]))
+		dryRunMu.Lock()
+		fmt.Fprint(os.Stdout, d)
+		dryRunMu.Unlock()
+	}
+}
+
+// applyEdits returns data, the contents of the file f, with edits
+// applied in the order of their positions, skipping the edits
+// overlapping the ones applied and the repeated ones, such as the same
+// import added by the fixes of several diagnostics.
+func applyEdits(f *token.File, data []byte, edits []analysis.TextEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Pos < edits[j].Pos })
+	var out []byte
+	cur := 0
+	applied := make(map[string]bool)
+	for _, e := range edits {
+		start, end := f.Offset(e.Pos), f.Offset(e.End)
+		key := fmt.Sprintf("%d:%d:%s", start, end, e.NewText)
+		if start < cur || end > len(data) || applied[key] {
+			continue
+		}
+		applied[key] = true
+		out = append(out, data[cur:start]...)
+		out = append(out, e.NewText...)
+		cur = end
+	}
+	return string(append(out, data[cur:]...))
+}
@@ -0,0 +1,99 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkEmbedded reports the struct literals returned by constructors in
+// fns which leave an embedded interface nil when a function in fns calls
+// a method promoted from it, as in
+//
+//	type S struct{ io.Reader }
+//
+//	func New() *S { return &S{} }
+//
+//	func (s *S) Next(p []byte) { s.Read(p) }
+//
+// Fields set to a non-nil value outside the literals, e.g. by a setter,
+// aren't reported.
+func checkEmbedded(pass *analysis.Pass, fns []*ssa.Function) {
+	calls := make(map[*types.Var]*ssa.Call)
+	set := make(map[*types.Var]bool)
+	var literals []*ssa.Alloc
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Call:
+					field := embeddedInterface(instr.Call.Value)
+					if instr.Call.IsInvoke() && field != nil && calls[field] == nil && !isNilChecked(instr.Call.Value, b) {
+						calls[field] = instr
+					}
+				case *ssa.Store:
+					field := selectedField(instr.Addr)
+					if field == nil || isNil(instr.Val) {
+						continue
+					}
+					if alloc, ok := instr.Addr.(*ssa.FieldAddr).X.(*ssa.Alloc); !ok || !returnedLiteral(fn, alloc) {
+						set[field] = true
+					}
+				case *ssa.Alloc:
+					if structOf(instr.Type()) != nil && returnedLiteral(fn, instr) {
+						literals = append(literals, instr)
+					}
+				}
+			}
+		}
+	}
+	for _, alloc := range literals {
+		s := structOf(alloc.Type())
+		for i := 0; i < s.NumFields(); i++ {
+			field := s.Field(i)
+			c := calls[field]
+			if c == nil || set[field] || isFieldSet(alloc, field.Name()) {
+				continue
+			}
+			posn := pass.Fset.Position(c.Pos())
+			report(pass, alloc.Pos(), "the embedded %s is nil and its method %s is called at %s:%d",
+				field.Name(), c.Call.Method.Name(), filepath.Base(posn.Filename), posn.Line)
+		}
+	}
+}
+
+// embeddedInterface returns the embedded interface field loaded as v,
+// or nil if v isn't one.
+func embeddedInterface(v ssa.Value) *types.Var {
+	if load, ok := v.(*ssa.UnOp); ok && load.Op == token.MUL {
+		v = load.X
+	}
+	field := selectedField(v)
+	if field == nil || !field.Anonymous() || !types.IsInterface(field.Type()) {
+		return nil
+	}
+	return field
+}
+
+// returnedLiteral reports whether alloc is a struct literal returned by
+// fn whose fields can only be set by fn before it returns.
+func returnedLiteral(fn *ssa.Function, alloc *ssa.Alloc) bool {
+	if alloc.Referrers() == nil || !returnsValue(fn, alloc) {
+		return false
+	}
+	for _, r := range *alloc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.FieldAddr, *ssa.DebugRef, *ssa.Return:
+		case *ssa.UnOp:
+			if r.Op != token.MUL {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
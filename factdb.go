@@ -0,0 +1,172 @@
+package nilarg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// factFiles are the files of manifests which the analyzer reads for the
+// packages without facts or manifests of their own.
+var factFiles string
+
+func init() {
+	Analyzer.Flags.StringVar(&factFiles, "facts", "",
+		"read the nil contracts of packages without facts from the comma-separated `files` of manifests, later files taking precedence")
+}
+
+// DB is a database of the nil contracts of the functions of packages,
+// merged from manifests, such as a database of the standard library, the
+// manifests published in the module cache, annotations written by hand
+// and the manifests written by the current run.
+type DB struct {
+	// pkgs maps import paths to the contracts of their functions by
+	// name.
+	pkgs map[string]map[string][]ManifestContract
+}
+
+// NewDB returns the database merging ms in increasing precedence: the
+// contracts a manifest lists for a function replace the contracts of the
+// function in the manifests before it, and the functions it doesn't list
+// keep theirs.
+func NewDB(ms ...Manifest) *DB {
+	db := &DB{pkgs: make(map[string]map[string][]ManifestContract)}
+	for _, m := range ms {
+		db.Add(m)
+	}
+	return db
+}
+
+// LoadManifests reads the manifests in file, which is either a manifest
+// as written by -write-manifest or a JSON array of manifests.
+func LoadManifests(file string) ([]Manifest, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var ms []Manifest
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &ms)
+	} else {
+		var m Manifest
+		err = json.Unmarshal(data, &m)
+		ms = []Manifest{m}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	for _, m := range ms {
+		if m.Package == "" {
+			return nil, fmt.Errorf("%s: manifest without a package", file)
+		}
+	}
+	return ms, nil
+}
+
+// LoadDB reads the manifests of files with LoadManifests and merges
+// them, the manifests of later files taking precedence.
+func LoadDB(files ...string) (*DB, error) {
+	db := NewDB()
+	for _, file := range files {
+		ms, err := LoadManifests(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range ms {
+			db.Add(m)
+		}
+	}
+	return db, nil
+}
+
+// Add merges m into db, taking precedence over the manifests already
+// merged.
+func (db *DB) Add(m Manifest) {
+	funcs := db.pkgs[m.Package]
+	if funcs == nil {
+		funcs = make(map[string][]ManifestContract)
+		db.pkgs[m.Package] = funcs
+	}
+	replaced := make(map[string]bool)
+	for _, c := range m.Contracts {
+		if !replaced[c.Func] {
+			replaced[c.Func] = true
+			funcs[c.Func] = nil
+		}
+		funcs[c.Func] = append(funcs[c.Func], c)
+	}
+}
+
+// Packages returns the sorted import paths of the packages in db.
+func (db *DB) Packages() []string {
+	paths := make([]string, 0, len(db.pkgs))
+	for path := range db.pkgs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Manifest returns the merged manifest of the package with the import
+// path, or nil if db has no manifests of the package.
+func (db *DB) Manifest(path string) *Manifest {
+	funcs, ok := db.pkgs[path]
+	if !ok {
+		return nil
+	}
+	m := &Manifest{Package: path, Contracts: []ManifestContract{}}
+	for _, cs := range funcs {
+		m.Contracts = append(m.Contracts, cs...)
+	}
+	sort.Slice(m.Contracts, func(i, j int) bool {
+		a, b := m.Contracts[i], m.Contracts[j]
+		return a.Func < b.Func || a.Func == b.Func && a.Param < b.Param
+	})
+	return m
+}
+
+// Manifests returns the merged manifests of all the packages in db,
+// sorted by their import paths, e.g. to write a merged database.
+func (db *DB) Manifests() []Manifest {
+	var ms []Manifest
+	for _, path := range db.Packages() {
+		ms = append(ms, *db.Manifest(path))
+	}
+	return ms
+}
+
+// Lookup returns the contracts of the function of the package with the
+// import path, named as in -run, sorted by the parameters.
+func (db *DB) Lookup(path, symbol string) []ManifestContract {
+	cs := append([]ManifestContract(nil), db.pkgs[path][symbol]...)
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Param < cs[j].Param })
+	return cs
+}
+
+// factDB caches the database read from factFiles, which are flags that
+// can change between runs in tests.
+var factDB = struct {
+	sync.Mutex
+	files string
+	db    *DB
+	err   error
+}{}
+
+// loadFactDB returns the database of factFiles, or nil without them.
+func loadFactDB() (*DB, error) {
+	factDB.Lock()
+	defer factDB.Unlock()
+	if factFiles == "" {
+		return nil, nil
+	}
+	if factDB.db == nil && factDB.err == nil || factDB.files != factFiles {
+		factDB.files = factFiles
+		factDB.db, factDB.err = LoadDB(strings.Split(factFiles, ",")...)
+	}
+	return factDB.db, factDB.err
+}
@@ -0,0 +1,108 @@
+package nilarg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxListedArgs is the number of parameters up to which panicArgs facts
+// and the messages about them list the parameters one by one. Larger
+// sets, as of generated functions with dozens of pointer parameters, are
+// summarized.
+const maxListedArgs = 8
+
+// indexRuns returns the sorted indices of p as runs of consecutive
+// indices, each a pair of its first index and its length.
+func (p panicArgs) indexRuns() [][2]int {
+	idx := make([]int, 0, len(p))
+	for i := range p {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var runs [][2]int
+	for _, i := range idx {
+		if n := len(runs); n > 0 && runs[n-1][0]+runs[n-1][1] == i {
+			runs[n-1][1]++
+			continue
+		}
+		runs = append(runs, [2]int{i, 1})
+	}
+	return runs
+}
+
+// String prints small facts as the maps they are, and summarizes the
+// others by the runs of their indices, as in panicArgs[40 params: 0-39].
+func (p *panicArgs) String() string {
+	if len(*p) <= maxListedArgs {
+		return fmt.Sprintf("&%v", map[int]struct{}(*p))
+	}
+	var runs []string
+	for _, r := range p.indexRuns() {
+		if r[1] == 1 {
+			runs = append(runs, fmt.Sprint(r[0]))
+		} else {
+			runs = append(runs, fmt.Sprintf("%d-%d", r[0], r[0]+r[1]-1))
+		}
+	}
+	return fmt.Sprintf("panicArgs[%d params: %s]", len(*p), strings.Join(runs, ","))
+}
+
+// GobEncode encodes p as the runs of its indices, so that the facts of
+// functions whose parameters all panic take a few bytes however many
+// parameters they have.
+func (p *panicArgs) GobEncode() ([]byte, error) {
+	var buf []byte
+	var tmp [binary.MaxVarintLen64]byte
+	next := 0
+	for _, r := range p.indexRuns() {
+		// The gap from the end of the previous run, and the length.
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(r[0]-next))]...)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(r[1]))]...)
+		next = r[0] + r[1]
+	}
+	return buf, nil
+}
+
+// GobDecode decodes the runs encoded by GobEncode.
+func (p *panicArgs) GobDecode(data []byte) error {
+	*p = panicArgs{}
+	next := 0
+	for len(data) > 0 {
+		gap, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed panicArgs")
+		}
+		data = data[n:]
+		length, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed panicArgs")
+		}
+		data = data[n:]
+		if gap > 1<<16 || length > 1<<16 {
+			return errors.New("malformed panicArgs")
+		}
+		start := next + int(gap)
+		for i := start; i < start+int(length); i++ {
+			(*p)[i] = struct{}{}
+		}
+		next = start + int(length)
+	}
+	return nil
+}
+
+// paramList lists the names of parameters for messages as "p or q". More
+// than maxListedArgs names are summarized, as "any of its 40 nillable
+// parameters" when they are all of the nillable parameters of the
+// function, of which there are nillable.
+func paramList(names []string, nillable int) string {
+	if len(names) <= maxListedArgs {
+		return strings.Join(names, " or ")
+	}
+	if len(names) == nillable {
+		return fmt.Sprintf("any of its %d nillable parameters", len(names))
+	}
+	return fmt.Sprintf("one of %s and %d more parameters", strings.Join(names[:3], ", "), len(names)-3)
+}
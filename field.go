@@ -0,0 +1,562 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// panicFields has the information about nillable fields of struct
+// arguments which cause panic on calling the function when they are
+// nil. The key is the argument index and the values are field names.
+type panicFields map[int]map[string]struct{}
+
+func (*panicFields) AFact() {}
+
+func (f *panicFields) String() string {
+	var fields []string
+	for i, names := range *f {
+		for name := range names {
+			fields = append(fields, fmt.Sprintf("%d.%s", i, name))
+		}
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("panicFields%v", fields)
+}
+
+// checkFields exports panicFields for fn if a nillable field of a
+// struct or pointer to struct argument is dereferenced without a nil
+// check. The fields in validated are never nil and are skipped.
+func checkFields(pass *analysis.Pass, fn *ssa.Function, validated map[*types.Var]bool) {
+	if factObject(fn) == nil {
+		return
+	}
+	fact := panicFields{}
+	for i, fp := range fn.Params {
+		if structOf(fp.Type()) == nil || fp.Referrers() == nil {
+			continue
+		}
+		for _, fpr := range fieldReferrers(fp) {
+			if validated[selectedField(fpr)] {
+				continue
+			}
+			name, loads := loadField(fpr)
+			for _, v := range loads {
+				if !isNillable(v.Type()) || !panicsOnAny(pass, v) || isOnceInitialized(v) || isValidated(pass, fp, name, v) {
+					continue
+				}
+				if fact[i] == nil {
+					fact[i] = make(map[string]struct{})
+				}
+				fact[i][name] = struct{}{}
+			}
+		}
+	}
+	if len(fact) > 0 {
+		pass.ExportObjectFact(factObject(fn), &fact)
+	}
+}
+
+// validatedFields returns the fields which a constructor in fns
+// initializes with a non-nil value, as in
+//
+//	func New(p *T) (*S, error) {
+//		if p == nil {
+//			return nil, errors.New("p is nil")
+//		}
+//		return &S{p: p}, nil
+//	}
+//
+// and which no other store in fns can set to nil. Neither can any struct
+// allocated in fns leave the field out, as &S{} or new(S) does.
+func validatedFields(pass *analysis.Pass, fns []*ssa.Function) map[*types.Var]bool {
+	validated := make(map[*types.Var]bool)
+	invalid := make(map[*types.Var]bool)
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if alloc, ok := instr.(*ssa.Alloc); ok {
+					for _, field := range zeroFields(pass, alloc) {
+						invalid[field] = true
+					}
+					continue
+				}
+				st, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				field := selectedField(st.Addr)
+				if field == nil || !isNillable(field.Type()) {
+					continue
+				}
+				if !isNonNilStore(pass, st) {
+					invalid[field] = true
+					continue
+				}
+				if alloc, ok := st.Addr.(*ssa.FieldAddr).X.(*ssa.Alloc); ok && returnsValue(fn, alloc) {
+					validated[field] = true
+				}
+			}
+		}
+	}
+	for field := range invalid {
+		delete(validated, field)
+	}
+	return validated
+}
+
+// zeroFields returns the nillable fields of the struct allocated by
+// alloc which aren't set to non-nil values in its function. A struct
+// stored as a whole, such as a copy of another one, has no zero fields.
+func zeroFields(pass *analysis.Pass, alloc *ssa.Alloc) []*types.Var {
+	st := structOf(alloc.Type())
+	if st == nil || alloc.Referrers() == nil {
+		return nil
+	}
+	set := make(map[int]bool)
+	for _, r := range *alloc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.Store:
+			if r.Addr == alloc {
+				return nil
+			}
+		case *ssa.FieldAddr:
+			if r.Referrers() == nil {
+				continue
+			}
+			for _, fr := range *r.Referrers() {
+				if fst, ok := fr.(*ssa.Store); ok && fst.Addr == r && isNonNilStore(pass, fst) {
+					set[r.Field] = true
+				}
+			}
+		}
+	}
+	var fields []*types.Var
+	for i := 0; i < st.NumFields(); i++ {
+		if f := st.Field(i); !set[i] && isNillable(f.Type()) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// selectedField returns the field selected by v, or nil if v isn't a
+// field selection.
+func selectedField(v interface{}) *types.Var {
+	switch v := v.(type) {
+	case *ssa.Field:
+		return structOf(v.X.Type()).Field(v.Field)
+	case *ssa.FieldAddr:
+		return structOf(v.X.Type()).Field(v.Field)
+	}
+	return nil
+}
+
+// isNonNilStore reports whether the value stored by st is never nil,
+// because it's intrinsically non-nil or a nil-checked parameter.
+func isNonNilStore(pass *analysis.Pass, st *ssa.Store) bool {
+	if nilnessOf(pass, nil, st.Val) == isnonnil {
+		return true
+	}
+	p, ok := st.Val.(*ssa.Parameter)
+	return ok && isNilChecked(p, st.Block())
+}
+
+// returnsValue reports whether fn returns the struct allocated by
+// alloc or a pointer to it.
+func returnsValue(fn *ssa.Function, alloc *ssa.Alloc) bool {
+	for _, b := range fn.Blocks {
+		ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+		if !ok {
+			continue
+		}
+		for _, v := range ret.Results {
+			if load, ok := v.(*ssa.UnOp); ok && load.Op == token.MUL {
+				v = load.X
+			}
+			if v == ssa.Value(alloc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldReferrers returns the referrers of the struct parameter fp and
+// of the variable fp is spilled to, if any, e.g. because it is
+// captured by a closure.
+func fieldReferrers(fp *ssa.Parameter) []ssa.Instruction {
+	refs := *fp.Referrers()
+	for _, r := range *fp.Referrers() {
+		st, ok := r.(*ssa.Store)
+		if !ok || st.Val != fp {
+			continue
+		}
+		alloc, ok := st.Addr.(*ssa.Alloc)
+		if !ok || alloc.Referrers() == nil {
+			continue
+		}
+		refs = append(refs[:len(refs):len(refs)], *alloc.Referrers()...)
+		if _, ok := fp.Type().Underlying().(*types.Pointer); !ok {
+			continue
+		}
+		for _, ar := range *alloc.Referrers() {
+			if load, ok := ar.(*ssa.UnOp); ok && load.Op == token.MUL && load.Referrers() != nil {
+				refs = append(refs, *load.Referrers()...)
+			}
+		}
+	}
+	return refs
+}
+
+// structOf returns the struct type of t if t is a struct or a pointer
+// to struct.
+func structOf(t types.Type) *types.Struct {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, _ := t.Underlying().(*types.Struct)
+	return s
+}
+
+// loadField returns the name of the field selected by instr and the
+// values of the field loaded through it.
+func loadField(instr ssa.Instruction) (string, []ssa.Value) {
+	switch instr := instr.(type) {
+	case *ssa.Field:
+		// x.field of a struct value x
+		return structOf(instr.X.Type()).Field(instr.Field).Name(), []ssa.Value{instr}
+	case *ssa.FieldAddr:
+		// *(&x.field) of a pointer x
+		if instr.Referrers() == nil {
+			return "", nil
+		}
+		var loads []ssa.Value
+		for _, r := range *instr.Referrers() {
+			if load, ok := r.(*ssa.UnOp); ok && load.Op == token.MUL {
+				loads = append(loads, load)
+			}
+		}
+		return structOf(instr.X.Type()).Field(instr.Field).Name(), loads
+	}
+	return "", nil
+}
+
+// isOnceInitialized reports whether the field load v is dominated by a
+// call of sync.Once.Do whose function initializes the field, as in
+//
+//	c.once.Do(func() { c.p = new(T) })
+//	c.p.f()
+func isOnceInitialized(v ssa.Value) bool {
+	load, ok := v.(*ssa.UnOp)
+	if !ok {
+		return false
+	}
+	fa, ok := load.X.(*ssa.FieldAddr)
+	if !ok {
+		return false
+	}
+	for _, b := range load.Parent().Blocks {
+		if !b.Dominates(load.Block()) {
+			continue
+		}
+		for _, instr := range b.Instrs {
+			if instr == ssa.Instruction(load) {
+				break
+			}
+			c, ok := instr.(*ssa.Call)
+			if !ok || c.Call.StaticCallee() == nil || len(c.Call.Args) != 2 {
+				continue
+			}
+			if f, ok := c.Call.StaticCallee().Object().(*types.Func); !ok || f.FullName() != "(*sync.Once).Do" {
+				continue
+			}
+			if mc, ok := c.Call.Args[1].(*ssa.MakeClosure); ok && storesField(mc, fa) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// storesField reports whether the function of the closure mc stores a
+// non-nil value to the field selected by fa, of the same struct as fa
+// captured by mc.
+func storesField(mc *ssa.MakeClosure, fa *ssa.FieldAddr) bool {
+	for _, b := range mc.Fn.(*ssa.Function).Blocks {
+		for _, instr := range b.Instrs {
+			st, ok := instr.(*ssa.Store)
+			if !ok || isNil(st.Val) {
+				continue
+			}
+			if sfa, ok := st.Addr.(*ssa.FieldAddr); ok && sfa.Field == fa.Field && isCaptured(mc, sfa.X, fa.X) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCaptured reports whether the value inner of the function of the
+// closure mc is the value outer of the function creating mc. Closures
+// capture variables by their addresses, so both are loads of the same
+// variable, as c in
+//
+//	c.once.Do(func() { c.p = new(T) })
+func isCaptured(mc *ssa.MakeClosure, inner, outer ssa.Value) bool {
+	il, ok := inner.(*ssa.UnOp)
+	if !ok || il.Op != token.MUL {
+		return false
+	}
+	ol, ok := outer.(*ssa.UnOp)
+	if !ok || ol.Op != token.MUL {
+		return false
+	}
+	for k, fv := range mc.Fn.(*ssa.Function).FreeVars {
+		if il.X == ssa.Value(fv) && ol.X == mc.Bindings[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// panicsOnAny reports whether a referrer of v causes panic when v is
+// nil and isn't dominated by a nil check of v.
+func panicsOnAny(pass *analysis.Pass, v ssa.Value) bool {
+	if v.Referrers() == nil {
+		return false
+	}
+	for _, vr := range *v.Referrers() {
+		if panicReason(pass, vr, v) != "" && !isNilChecked(v, vr.Block()) {
+			return true
+		}
+	}
+	return false
+}
+
+// panicReason describes how instr causes panic when v is nil, because
+// instr dereferences or calls v or passes v to a function with a
+// panicArgs fact.
+// It returns "" if instr doesn't panic.
+func panicReason(pass *analysis.Pass, instr ssa.Instruction, v ssa.Value) string {
+	if x, what := dereference(instr); x == v {
+		return what
+	}
+	c, ok := instr.(ssa.CallInstruction)
+	if !ok || c.Common().IsInvoke() {
+		return ""
+	}
+	// Calling a nil function panics, which is only tracked here, e.g.
+	// through fields, when -nillable lists func.
+	if c.Common().Value == v && isNillable(v.Type()) {
+		return "called"
+	}
+	for _, i := range argIndices(c.Common(), v) {
+		if h := panickingHandler(pass, c.Common(), i); h != nil {
+			return "passed to " + h.Name()
+		}
+		if e := elementPanics(pass, c.Common(), i); e != nil {
+			return "passed to " + e.Name()
+		}
+	}
+	s := c.Common().StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return ""
+	}
+	var fact panicArgs
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return ""
+	}
+	for _, i := range argIndices(c.Common(), v) {
+		if _, ok := fact[i]; ok && !isNilSafeRecv(pass, factObject(s), i) {
+			return "passed to " + s.Name()
+		}
+	}
+	return ""
+}
+
+// sameValue reports whether a and b are the same value, treating
+// repeated loads of the same variable or the same field of the same
+// value as equal.
+func sameValue(a, b ssa.Value) bool {
+	if a == b {
+		return true
+	}
+	switch a := a.(type) {
+	case *ssa.Field:
+		b, ok := b.(*ssa.Field)
+		return ok && a.X == b.X && a.Field == b.Field
+	case *ssa.UnOp:
+		b, ok := b.(*ssa.UnOp)
+		if !ok || a.Op != token.MUL || b.Op != token.MUL {
+			return false
+		}
+		if a.X == b.X {
+			return true
+		}
+		fa, ok := a.X.(*ssa.FieldAddr)
+		if !ok {
+			return false
+		}
+		fb, ok := b.X.(*ssa.FieldAddr)
+		return ok && fa.X == fb.X && fa.Field == fb.Field
+	}
+	return false
+}
+
+// checkFieldArgs reports the call c if an argument for which the
+// callee has a panicFields fact is a struct literal built in the
+// caller that omits one of the fields or sets it to nil.
+func checkFieldArgs(pass *analysis.Pass, c *ssa.Call) {
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return
+	}
+	var fact panicFields
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return
+	}
+	for i, names := range fact {
+		if i >= len(c.Call.Args) {
+			continue
+		}
+		alloc := literalOf(c.Call.Args[i], c)
+		if alloc == nil {
+			continue
+		}
+		var missing []string
+		for name := range names {
+			if !isFieldSet(alloc, name) {
+				missing = append(missing, name)
+			}
+		}
+		sort.Strings(missing)
+		for _, name := range missing {
+			report(pass, c.Pos(), "this call can cause panic: field %s is nil", name)
+		}
+	}
+}
+
+// literalOf returns the allocation of the struct literal passed as v
+// to the call c, or nil if v isn't a local struct literal whose fields
+// can only be set in the caller.
+func literalOf(v ssa.Value, c *ssa.Call) *ssa.Alloc {
+	if load, ok := v.(*ssa.UnOp); ok && load.Op == token.MUL {
+		v = load.X
+	}
+	alloc, ok := v.(*ssa.Alloc)
+	if !ok || structOf(alloc.Type()) == nil || alloc.Referrers() == nil {
+		return nil
+	}
+	for _, r := range *alloc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.FieldAddr, *ssa.DebugRef:
+		case *ssa.UnOp:
+			if r.Op != token.MUL {
+				return nil
+			}
+		default:
+			if r != ssa.Instruction(c) {
+				// The address escapes and the fields can be set
+				// elsewhere.
+				return nil
+			}
+		}
+	}
+	return alloc
+}
+
+// isFieldSet reports whether the field name of the struct allocated by
+// alloc is set to a value other than the nil constant.
+func isFieldSet(alloc *ssa.Alloc, name string) bool {
+	for _, r := range *alloc.Referrers() {
+		fa, ok := r.(*ssa.FieldAddr)
+		if !ok || structOf(alloc.Type()).Field(fa.Field).Name() != name || fa.Referrers() == nil {
+			continue
+		}
+		for _, fr := range *fa.Referrers() {
+			if st, ok := fr.(*ssa.Store); ok && st.Addr == fa && !isNil(st.Val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nilField reports whether v is a field of a local struct variable which
+// is never set to anything but nil, as cfg.Logger in
+//
+//	cfg := Config{Logger: nil}
+//	run(cfg.Logger)
+//
+// The variable must not escape, so that the field can't be set
+// elsewhere.
+func nilField(v ssa.Value) bool {
+	var x ssa.Value
+	var field int
+	switch v := v.(type) {
+	case *ssa.UnOp:
+		fa, ok := v.X.(*ssa.FieldAddr)
+		if !ok || v.Op != token.MUL {
+			return false
+		}
+		x, field = fa.X, fa.Field
+	case *ssa.Field:
+		load, ok := v.X.(*ssa.UnOp)
+		if !ok || load.Op != token.MUL {
+			return false
+		}
+		x, field = load.X, v.Field
+	default:
+		return false
+	}
+	alloc, ok := x.(*ssa.Alloc)
+	if !ok || !isNillable(v.Type()) || !isLocalStruct(alloc) {
+		return false
+	}
+	return !isFieldSet(alloc, structOf(alloc.Type()).Field(field).Name())
+}
+
+// isLocalStruct reports whether alloc is a struct variable whose address
+// is only used to select its fields and load it, and whose fields are
+// only stored to and loaded.
+func isLocalStruct(alloc *ssa.Alloc) bool {
+	if structOf(alloc.Type()) == nil || alloc.Referrers() == nil {
+		return false
+	}
+	for _, r := range *alloc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.DebugRef:
+		case *ssa.UnOp:
+			if r.Op != token.MUL {
+				return false
+			}
+		case *ssa.FieldAddr:
+			if r.Referrers() == nil {
+				continue
+			}
+			for _, fr := range *r.Referrers() {
+				switch fr := fr.(type) {
+				case *ssa.Store:
+					if fr.Addr != ssa.Value(r) {
+						return false
+					}
+				case *ssa.UnOp:
+					if fr.Op != token.MUL {
+						return false
+					}
+				case *ssa.DebugRef:
+				default:
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package nilarg
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// runPattern restricts the diagnostics to the functions whose names
+// match it, like the -run flag of go test.
+var runPattern regexpFlag
+
+func init() {
+	Analyzer.Flags.Var(&runPattern, "run", "report only in functions whose names match `regexp`; methods are named T.M")
+}
+
+// regexpFlag is a flag.Value of a regular expression which is nil
+// unless set.
+type regexpFlag struct{ *regexp.Regexp }
+
+func (f *regexpFlag) String() string {
+	if f.Regexp == nil {
+		return ""
+	}
+	return f.Regexp.String()
+}
+
+func (f *regexpFlag) Set(s string) error {
+	if s == "" {
+		f.Regexp = nil
+		return nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	f.Regexp = re
+	return nil
+}
+
+// matchesRun reports whether pos is in a function selected by
+// runPattern.
+func matchesRun(pass *analysis.Pass, pos token.Pos) bool {
+	if runPattern.Regexp == nil {
+		return true
+	}
+	name, ok := enclosingFunc(pass, pos)
+	return ok && runPattern.MatchString(name)
+}
+
+// enclosingFunc returns the name of the function declaration enclosing
+// pos, if any.
+func enclosingFunc(pass *analysis.Pass, pos token.Pos) (string, bool) {
+	_, path := enclosingPath(pass, pos)
+	for _, n := range path {
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			return funcName(decl), true
+		}
+	}
+	return "", false
+}
+
+// funcName returns the name of decl, qualified by the receiver type
+// name for methods.
+func funcName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	t := decl.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + decl.Name.Name
+	}
+	return decl.Name.Name
+}
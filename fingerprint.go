@@ -0,0 +1,43 @@
+package nilarg
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fingerprints makes the analyzer set the category of each diagnostic
+// to its fingerprint, which the JSON output of the driver includes.
+var fingerprints bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&fingerprints, "fingerprints", false,
+		"set the category of diagnostics to a fingerprint stable across changes of line numbers")
+}
+
+// fingerprint returns an identifier of d which doesn't depend on the
+// position of d, so that findings can be tracked across commits moving
+// them. It hashes the package, the enclosing function, the message and
+// the source of the innermost expression at d, such as the call with the
+// callee and the arguments. Identical findings in the same
+// function share the fingerprint.
+func fingerprint(pass *analysis.Pass, d analysis.Diagnostic) string {
+	parts := []string{pass.Pkg.Path(), "", d.Message, ""}
+	_, path := enclosingPath(pass, d.Pos)
+	for _, n := range path {
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			parts[1] = funcName(decl)
+			break
+		}
+	}
+	if len(path) > 0 {
+		if x, ok := path[0].(ast.Expr); ok {
+			parts[3] = types.ExprString(x)
+		}
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(parts, "\x00"))))[:16]
+}
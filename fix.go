@@ -0,0 +1,360 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+// fixAlternatives makes the analyzer attach all the alternative fixes
+// of a call to its diagnostic instead of only the first one, for the
+// hosts letting the user pick one of them such as LSP clients. Drivers
+// applying every fix of a diagnostic, like -fix, would combine them.
+var fixAlternatives bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&fixAlternatives, "fix-alternatives", false,
+		"suggest all the alternative fixes of each call instead of the first one, for hosts letting the user pick one")
+}
+
+// callFixes returns the fixes for the call c passing a nil value as the
+// i-th argument of the callee s: wrapping the call in a nil check,
+// returning an error instead, passing a non-nil zero value or making
+// the callee return early. Only the first one is returned unless
+// fixAlternatives is set.
+func callFixes(pass *analysis.Pass, c ssa.CallInstruction, s *ssa.Function, i int) []analysis.SuggestedFix {
+	file, path := enclosingPath(pass, c.Pos())
+	if file == nil {
+		return nil
+	}
+	call, ok := path[0].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	arg := argExpr(pass, call, s, i)
+	if arg == nil {
+		return nil
+	}
+	var fixes []analysis.SuggestedFix
+	if !isNilIdent(pass, arg) {
+		x := types.ExprString(arg)
+		if stmt, ok := enclosingStmt(path).(*ast.ExprStmt); ok {
+			fixes = append(fixes, analysis.SuggestedFix{
+				Message: fmt.Sprintf("Call only if %s is not nil", x),
+				TextEdits: []analysis.TextEdit{
+					{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(fmt.Sprintf("if %s != nil {\n", x))},
+					{Pos: stmt.End(), End: stmt.End(), NewText: []byte("\n}")},
+				},
+			})
+		}
+		if stmt := enclosingStmt(path); stmt != nil {
+			if vals, ok := zeroResults(pass, enclosingSig(pass, path)); ok {
+				vals = append(vals, fmt.Sprintf("errors.New(%q)", x+" is nil"))
+				ret := fmt.Sprintf("if %s == nil {\nreturn %s\n}\n", x, strings.Join(vals, ", "))
+				edits := []analysis.TextEdit{{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(ret)}}
+				edits = append(edits, addImport(pass, file, "errors")...)
+				fixes = append(fixes, analysis.SuggestedFix{
+					Message:   fmt.Sprintf("Return an error if %s is nil", x),
+					TextEdits: edits,
+				})
+			}
+		}
+	}
+	if i < len(s.Params) {
+		if zero := nonNilZero(pass, s.Params[i].Type()); zero != "" {
+			fixes = append(fixes, analysis.SuggestedFix{
+				Message:   fmt.Sprintf("Pass %s instead", zero),
+				TextEdits: []analysis.TextEdit{{Pos: arg.Pos(), End: arg.End(), NewText: []byte(zero)}},
+			})
+		}
+		if fix := calleeFix(pass, s, i); fix != nil {
+			fixes = append(fixes, *fix)
+		}
+	}
+	if !fixAlternatives && len(fixes) > 1 {
+		fixes = fixes[:1]
+	}
+	return fixes
+}
+
+// calleeFix returns the fix making the callee s accept nil as its i-th
+// parameter instead of fixing the caller, by beginning s with an early
+// return of the zero values of its results, with an error as the last
+// one if s returns an error. It returns nil if s isn't declared in the
+// package.
+func calleeFix(pass *analysis.Pass, s *ssa.Function, i int) *analysis.SuggestedFix {
+	file, decl := funcDecl(pass, s)
+	fp := s.Params[i]
+	if decl == nil || decl.Body == nil || fp.Name() == "_" || fp.Object() == nil {
+		return nil
+	}
+	var vals []string
+	res := s.Signature.Results()
+	for j := 0; j < res.Len(); j++ {
+		vals = append(vals, zeroValue(pass, res.At(j).Type()))
+	}
+	var edits []analysis.TextEdit
+	if zeros, ok := zeroResults(pass, s.Signature); ok {
+		vals = append(zeros, fmt.Sprintf("errors.New(%q)", fp.Name()+" is nil"))
+		edits = addImport(pass, file, "errors")
+	}
+	ret := "return"
+	if len(vals) > 0 {
+		ret += " " + strings.Join(vals, ", ")
+	}
+	pos := decl.Body.Lbrace + 1
+	edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(fmt.Sprintf("\nif %s == nil {\n%s\n}", fp.Name(), ret))})
+	return &analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Return early from %s if %s is nil", s.Name(), fp.Name()),
+		TextEdits: edits,
+	}
+}
+
+// enclosingPath returns the file containing pos and the path of nodes
+// enclosing pos, innermost first.
+func enclosingPath(pass *analysis.Pass, pos token.Pos) (*ast.File, []ast.Node) {
+	for _, f := range pass.Files {
+		if f.Pos() <= pos && pos < f.End() {
+			path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+			return f, path
+		}
+	}
+	return nil, nil
+}
+
+// funcDecl returns the declaration of fn and the file declaring it, or
+// nil if fn isn't declared by a function declaration.
+func funcDecl(pass *analysis.Pass, fn *ssa.Function) (*ast.File, *ast.FuncDecl) {
+	file, path := enclosingPath(pass, fn.Pos())
+	for _, n := range path {
+		if decl, ok := n.(*ast.FuncDecl); ok && decl.Name.Pos() == fn.Pos() {
+			return file, decl
+		}
+	}
+	return nil, nil
+}
+
+// argExpr returns the expression of the i-th argument of the call to s,
+// counting the receiver of a method call as the argument 0.
+func argExpr(pass *analysis.Pass, call *ast.CallExpr, s *ssa.Function, i int) ast.Expr {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && s.Signature.Recv() != nil {
+		if selection, ok := pass.TypesInfo.Selections[sel]; ok && selection.Kind() == types.MethodVal {
+			if i == 0 {
+				return sel.X
+			}
+			i--
+		}
+	}
+	// The variadic arguments are packed into a slice.
+	if i >= len(call.Args) || s.Signature.Variadic() && !call.Ellipsis.IsValid() && i == s.Signature.Params().Len()-1 {
+		return nil
+	}
+	return call.Args[i]
+}
+
+// isNilIdent reports whether x is the predeclared nil.
+func isNilIdent(pass *analysis.Pass, x ast.Expr) bool {
+	id, ok := astutil.Unparen(x).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = pass.TypesInfo.Uses[id].(*types.Nil)
+	return ok
+}
+
+// enclosingStmt returns the innermost statement of path which is an
+// element of a block.
+func enclosingStmt(path []ast.Node) ast.Stmt {
+	for i, n := range path {
+		stmt, ok := n.(ast.Stmt)
+		if !ok || i+1 >= len(path) {
+			continue
+		}
+		switch path[i+1].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return stmt
+		}
+	}
+	return nil
+}
+
+// enclosingSig returns the signature of the innermost function
+// enclosing path.
+func enclosingSig(pass *analysis.Pass, path []ast.Node) *types.Signature {
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if obj, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+				return obj.Type().(*types.Signature)
+			}
+			return nil
+		case *ast.FuncLit:
+			sig, _ := pass.TypesInfo.TypeOf(n).(*types.Signature)
+			return sig
+		}
+	}
+	return nil
+}
+
+// zeroResults returns the zero values of the results of sig other than
+// the last one, if sig returns an error as its last result.
+func zeroResults(pass *analysis.Pass, sig *types.Signature) ([]string, bool) {
+	if sig == nil || sig.Results().Len() == 0 {
+		return nil, false
+	}
+	res := sig.Results()
+	if !types.Identical(res.At(res.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		return nil, false
+	}
+	var vals []string
+	for i := 0; i < res.Len()-1; i++ {
+		vals = append(vals, zeroValue(pass, res.At(i).Type()))
+	}
+	return vals, true
+}
+
+// addImport returns the edits adding the import of path to file, or nil
+// if file already imports it. A new import declaration begins the line
+// after the last import declaration, or after the package clause, so
+// that the comments ending their lines stay with them.
+func addImport(pass *analysis.Pass, file *ast.File, path string) []analysis.TextEdit {
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, "`\"") == path {
+			return nil
+		}
+	}
+	var last ast.Node = file.Name
+	text := fmt.Sprintf("\nimport %q\n", path)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			pos := gen.Lparen + 1
+			return []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte(fmt.Sprintf("\n\t%q", path))}}
+		}
+		last, text = gen, fmt.Sprintf("import %q\n", path)
+	}
+	tf := pass.Fset.File(last.End())
+	line := tf.Line(last.End())
+	if line == tf.LineCount() {
+		// The declaration ends the file, without a newline.
+		pos := token.Pos(tf.Base() + tf.Size())
+		return []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte("\n" + text)}}
+	}
+	pos := tf.LineStart(line + 1)
+	return []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte(text)}}
+}
+
+// zeroValue returns the expression of the zero value of t.
+func zeroValue(pass *analysis.Pass, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, types.RelativeTo(pass.Pkg)) + "{}"
+	}
+	return "nil"
+}
+
+// nonNilZero returns the expression of a non-nil zero value of t such
+// as &T{} or make(map[K]V), or "" if there is no such expression.
+func nonNilZero(pass *analysis.Pass, t types.Type) string {
+	qf := types.RelativeTo(pass.Pkg)
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		switch u.Elem().Underlying().(type) {
+		case *types.Struct, *types.Array:
+			return "&" + types.TypeString(u.Elem(), qf) + "{}"
+		}
+	case *types.Map:
+		return "make(" + types.TypeString(t, qf) + ")"
+	}
+	return ""
+}
+
+// guards makes the analyzer report only the exported functions with
+// panicArgs facts, suggesting guard clauses for the flagged parameters.
+var guards bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&guards, "guards", false,
+		"report only flagged exported functions, suggesting guard clauses for them")
+}
+
+// guardCategory is the category of the diagnostics reported with
+// guards.
+const guardCategory = "guard"
+
+// suggestGuards reports the exported functions of fns which have a
+// panicArgs fact, with a fix beginning the function with guard clauses
+// that return an error when the function returns one and panic with a
+// clear message otherwise.
+func suggestGuards(pass *analysis.Pass, fns []*ssa.Function) {
+	if !guards {
+		return
+	}
+	for _, fn := range fns {
+		file, decl := funcDecl(pass, fn)
+		if decl == nil || decl.Body == nil || !decl.Name.IsExported() {
+			continue
+		}
+		var fact panicArgs
+		if !pass.ImportObjectFact(factObject(fn), &fact) {
+			continue
+		}
+		vals, retErr := zeroResults(pass, fn.Signature)
+		var names []string
+		var text strings.Builder
+		nillable := 0
+		for i, fp := range fn.Params {
+			if i == 0 && fn.Signature.Recv() != nil || fp.Name() == "_" || fp.Object() == nil {
+				continue
+			}
+			if isNillable(fp.Type()) {
+				nillable++
+			}
+			if _, ok := fact[i]; !ok {
+				continue
+			}
+			names = append(names, fp.Name())
+			msg := fmt.Sprintf("%s must not be nil", fp.Name())
+			if retErr {
+				fmt.Fprintf(&text, "\nif %s == nil {\nreturn %s\n}", fp.Name(), strings.Join(append(vals, fmt.Sprintf("fmt.Errorf(%q)", msg)), ", "))
+			} else {
+				fmt.Fprintf(&text, "\nif %s == nil {\npanic(%q)\n}", fp.Name(), msg)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		pos := decl.Body.Lbrace + 1
+		edits := []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte(text.String())}}
+		if retErr {
+			edits = append(edits, addImport(pass, file, "fmt")...)
+		}
+		reportDiag(pass, analysis.Diagnostic{
+			Pos:      decl.Name.Pos(),
+			Category: guardCategory,
+			Message:  fmt.Sprintf("exported function %s panics when %s is nil", decl.Name.Name, paramList(names, nillable)),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Add guard clauses",
+				TextEdits: edits,
+			}},
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// funcFields enables panicArgs facts for the struct fields of function
+// type whose implementations are all known in their packages.
+var funcFields bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&funcFields, "func-fields", false,
+		"check calls of struct fields of function type, such as the dependencies in config structs, as calls of the functions assigned to them in their packages")
+}
+
+// fieldFuncs maps the passes being run to the struct fields of function
+// type declared in their packages with the functions assigned to them,
+// leaving out the fields also assigned other values, such as closures or
+// parameters.
+var fieldFuncs = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[*types.Var][]*ssa.Function
+}{m: make(map[*analysis.Pass]map[*types.Var][]*ssa.Function)}
+
+// collectFieldFuncs records the functions which fns assign to the struct
+// fields of function type declared in the package, as in
+//
+//	deps := &Deps{Fetch: fetch}
+//
+// It returns a function which forgets the fields.
+func collectFieldFuncs(pass *analysis.Pass, fns []*ssa.Function) func() {
+	if !funcFields {
+		return func() {}
+	}
+	fields := make(map[*types.Var][]*ssa.Function)
+	unknown := make(map[*types.Var]bool)
+	for _, fn := range allFuncs(fns) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				st, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				fa, ok := st.Addr.(*ssa.FieldAddr)
+				if !ok {
+					continue
+				}
+				f := field(fa)
+				if _, ok := f.Type().Underlying().(*types.Signature); !ok || f.Pkg() != pass.Pkg {
+					continue
+				}
+				if impl, ok := st.Val.(*ssa.Function); ok && factObject(impl) != nil {
+					fields[f] = append(fields[f], impl)
+				} else {
+					unknown[f] = true
+				}
+			}
+		}
+	}
+	for f := range unknown {
+		delete(fields, f)
+	}
+	fieldFuncs.Lock()
+	defer fieldFuncs.Unlock()
+	fieldFuncs.m[pass] = fields
+	return func() {
+		fieldFuncs.Lock()
+		defer fieldFuncs.Unlock()
+		delete(fieldFuncs.m, pass)
+	}
+}
+
+// allFuncs returns fns and their anonymous functions.
+func allFuncs(fns []*ssa.Function) []*ssa.Function {
+	var all []*ssa.Function
+	for _, fn := range fns {
+		all = append(all, fn)
+		all = append(all, allFuncs(fn.AnonFuncs)...)
+	}
+	return all
+}
+
+// checkFieldFuncs exports panicArgs for the fields recorded for pass,
+// with the parameters on which all the functions assigned to them panic,
+// and reports whether any of the facts changed.
+func checkFieldFuncs(pass *analysis.Pass) bool {
+	fieldFuncs.Lock()
+	fields := fieldFuncs.m[pass]
+	fieldFuncs.Unlock()
+	changed := false
+	for f, impls := range fields {
+		fact := panicArgs{}
+		for k, impl := range impls {
+			var ifact panicArgs
+			pass.ImportObjectFact(factObject(impl), &ifact)
+			if k == 0 {
+				// Copy the fact, which is shared with impl.
+				for i := range ifact {
+					fact[i] = struct{}{}
+				}
+				continue
+			}
+			for i := range fact {
+				if _, ok := ifact[i]; !ok {
+					delete(fact, i)
+				}
+			}
+		}
+		var old panicArgs
+		pass.ImportObjectFact(f, &old)
+		if len(fact) == 0 || reflect.DeepEqual(fact, old) {
+			continue
+		}
+		pass.ExportObjectFact(f, &fact)
+		changed = true
+	}
+	return changed
+}
+
+// calledField returns the struct field called by common, if any.
+func calledField(common *ssa.CallCommon) *types.Var {
+	switch v := common.Value.(type) {
+	case *ssa.UnOp:
+		if fa, ok := v.X.(*ssa.FieldAddr); ok && v.Op == token.MUL {
+			return field(fa)
+		}
+	case *ssa.Field:
+		return v.X.Type().Underlying().(*types.Struct).Field(v.Field)
+	}
+	return nil
+}
+
+// fieldImpls names the functions assigned to the field f in the package
+// of pass, or returns "" for the fields of other packages.
+func fieldImpls(pass *analysis.Pass, f *types.Var) string {
+	fieldFuncs.Lock()
+	impls := fieldFuncs.m[pass][f]
+	fieldFuncs.Unlock()
+	var names []string
+	for _, impl := range impls {
+		names = append(names, impl.Name())
+	}
+	sort.Strings(names)
+	return strings.Join(names, " and ")
+}
+
+// checkFieldFuncCall reports the call c of a struct field of function
+// type if it passes nil where the functions assigned to the field panic.
+func checkFieldFuncCall(pass *analysis.Pass, c ssa.CallInstruction, stack []fact) {
+	f := calledField(c.Common())
+	if f == nil {
+		return
+	}
+	var fact panicArgs
+	if !pass.ImportObjectFact(f, &fact) {
+		return
+	}
+	for i, arg := range c.Common().Args {
+		if _, ok := fact[i]; !ok || argSuppressed(pass, c, i) || nilnessOf(pass, stack, arg) != isnil {
+			continue
+		}
+		if impls := fieldImpls(pass, f); impls != "" {
+			report(pass, c.Pos(), "this call can cause panic: %s is assigned %s", f.Name(), impls)
+		} else {
+			report(pass, c.Pos(), "this call can cause panic: %s panics on nil", f.Name())
+		}
+		return
+	}
+}
@@ -0,0 +1,155 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// elementCalls has the information about slices of functions passed as
+// arguments whose elements are called with other arguments, as in
+//
+//	func apply(fs []func(*T), p *T) {
+//		for _, f := range fs {
+//			f(p)
+//		}
+//	}
+//
+// The key is the index of the slice argument and the values are the
+// pairs of the index of the argument of the element calls and the index
+// of the argument passed as it.
+type elementCalls map[int][][2]int
+
+func (*elementCalls) AFact() {}
+
+func (f *elementCalls) String() string {
+	var idx []int
+	for i := range *f {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var calls []string
+	for _, i := range idx {
+		calls = append(calls, fmt.Sprintf("%d:%v", i, (*f)[i]))
+	}
+	return fmt.Sprintf("elementCalls%v", calls)
+}
+
+// checkElementCalls exports elementCalls for fn if it calls elements of
+// a slice of functions parameter with nillable parameters.
+func checkElementCalls(pass *analysis.Pass, fn *ssa.Function) {
+	if factObject(fn) == nil {
+		return
+	}
+	params := make(map[ssa.Value]int)
+	for i, fp := range fn.Params {
+		params[fp] = i
+	}
+	fact := elementCalls{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			c, ok := instr.(*ssa.Call)
+			if !ok || c.Call.IsInvoke() {
+				continue
+			}
+			ia := elementAddr(c.Call.Value)
+			if ia == nil {
+				continue
+			}
+			k, ok := params[ia.X]
+			if !ok {
+				continue
+			}
+			for a, arg := range c.Call.Args {
+				if j, ok := params[arg]; ok && isNillable(arg.Type()) && !isNilChecked(arg, b) {
+					fact[k] = append(fact[k], [2]int{a, j})
+				}
+			}
+		}
+	}
+	if len(fact) > 0 {
+		pass.ExportObjectFact(factObject(fn), &fact)
+	}
+}
+
+// elementPanics returns a function in a slice literal passed by the
+// call common whose elements the callee calls with the j-th argument,
+// if the function panics when the argument is nil.
+func elementPanics(pass *analysis.Pass, common *ssa.CallCommon, j int) *ssa.Function {
+	s := common.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return nil
+	}
+	var fact elementCalls
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return nil
+	}
+	for k, calls := range fact {
+		if k >= len(common.Args) {
+			continue
+		}
+		for _, call := range calls {
+			if call[1] != j {
+				continue
+			}
+			for _, e := range literalFuncs(common.Args[k]) {
+				var efact panicArgs
+				if factObject(e) == nil || !pass.ImportObjectFact(factObject(e), &efact) {
+					continue
+				}
+				if _, ok := efact[call[0]]; ok {
+					return e
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// literalFuncs returns the functions in the slice literal v, including
+// the slice built for variadic arguments.
+func literalFuncs(v ssa.Value) []*ssa.Function {
+	slice, ok := v.(*ssa.Slice)
+	if !ok {
+		return nil
+	}
+	lit, ok := slice.X.(*ssa.Alloc)
+	if !ok || lit.Referrers() == nil {
+		return nil
+	}
+	arr, ok := lit.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array)
+	if !ok {
+		return nil
+	}
+	var fns []*ssa.Function
+	for i := int64(0); i < arr.Len(); i++ {
+		elem := literalElement(lit, i)
+		if elem == nil || elem.Referrers() == nil {
+			continue
+		}
+		for _, r := range *elem.Referrers() {
+			st, ok := r.(*ssa.Store)
+			if !ok || st.Addr != ssa.Value(elem) {
+				continue
+			}
+			if f, ok := st.Val.(*ssa.Function); ok {
+				fns = append(fns, f)
+			}
+		}
+	}
+	return fns
+}
+
+// checkElementArgs reports the call c if it passes nil as an argument
+// with which the callee calls a function in a slice literal passed by
+// c, and the function panics on it.
+func checkElementArgs(pass *analysis.Pass, c *ssa.Call, stack []fact) {
+	for j, arg := range c.Call.Args {
+		if e := elementPanics(pass, c.Common(), j); e != nil && nilnessOf(pass, stack, arg) == isnil {
+			report(pass, c.Pos(), "this call can cause panic: %s in the slice panics on nil", e.Name())
+		}
+	}
+}
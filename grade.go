@@ -0,0 +1,89 @@
+package nilarg
+
+import (
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// reportGrade makes the analyzer report the nil-safety grade of each
+// package.
+var reportGrade bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&reportGrade, "grade", false,
+		"report the nil-safety grade of each package")
+}
+
+// Grade is an aggregate of the nil safety of a package, which can be
+// tracked over time.
+type Grade struct {
+	// Guarded and Unguarded are the numbers of the dereferences of
+	// nillable parameters with and without a dominating nil check.
+	Guarded, Unguarded int
+
+	// Contracts is the number of the exported functions which panic
+	// when some of their arguments are nil.
+	Contracts int
+}
+
+// Letter returns the letter grade from A to F by the ratio of the
+// guarded dereferences.
+func (g *Grade) Letter() string {
+	total := g.Guarded + g.Unguarded
+	if total == 0 {
+		return "A"
+	}
+	r := float64(g.Guarded) / float64(total)
+	switch {
+	case r >= 0.9:
+		return "A"
+	case r >= 0.75:
+		return "B"
+	case r >= 0.5:
+		return "C"
+	case r >= 0.25:
+		return "D"
+	}
+	return "F"
+}
+
+// grade computes the grade of the package of fns.
+func grade(pass *analysis.Pass, fns []*ssa.Function) *Grade {
+	g := new(Grade)
+	paramDerefs(pass, fns, func(_ string, guarded bool) {
+		if guarded {
+			g.Guarded++
+		} else {
+			g.Unguarded++
+		}
+	})
+	for _, fn := range fns {
+		var fact panicArgs
+		if obj := factObject(fn); obj != nil && obj.Exported() && pass.ImportObjectFact(obj, &fact) {
+			g.Contracts++
+		}
+	}
+	if reportGrade && len(pass.Files) > 0 {
+		report(pass, pass.Files[0].Package, "nil-safety grade %s: %d of %d dereferences of nillable parameters are guarded, %d exported functions panic on nil arguments",
+			g.Letter(), g.Guarded, g.Guarded+g.Unguarded, g.Contracts)
+	}
+	return g
+}
+
+// paramDerefs calls f for each instruction in fns which panics when a
+// nillable parameter is nil, with the description of the panic and
+// whether a nil check dominates the instruction.
+func paramDerefs(pass *analysis.Pass, fns []*ssa.Function, f func(what string, guarded bool)) {
+	for _, fn := range fns {
+		for _, fp := range fn.Params {
+			if !isNillable(fp.Type()) || fp.Referrers() == nil {
+				continue
+			}
+			for _, r := range *fp.Referrers() {
+				if what := panicReason(pass, r, fp); what != "" {
+					f(what, isNilChecked(fp, r.Block()))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// guardStyle makes the analyzer report nil checks written in forms which
+// it doesn't understand, suggesting the comparison with nil.
+var guardStyle bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&guardStyle, "guard-style", false,
+		"report nil checks by reflection or against typed nil, suggesting x == nil")
+}
+
+// checkGuardStyle reports the nil checks in the files of pass written as
+// reflect.ValueOf(x).IsNil() or as a comparison of x with a conversion
+// of nil to the type of x, such as x == (*T)(nil), suggesting x == nil
+// or x != nil instead. The checks of interfaces are left alone, because
+// they mean other things.
+func checkGuardStyle(pass *analysis.Pass) {
+	if !guardStyle {
+		return
+	}
+	for _, f := range pass.Files {
+		negated := make(map[ast.Expr]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.UnaryExpr:
+				if x := reflectNilCheck(pass, n.X); n.Op == token.NOT && x != nil {
+					suggestNilCheck(pass, n, x, token.NEQ)
+					negated[astutil.Unparen(n.X)] = true
+				}
+			case *ast.CallExpr:
+				if x := reflectNilCheck(pass, n); x != nil && !negated[n] {
+					suggestNilCheck(pass, n, x, token.EQL)
+				}
+			case *ast.BinaryExpr:
+				if n.Op != token.EQL && n.Op != token.NEQ {
+					break
+				}
+				if typedNil(pass, n.Y, pass.TypesInfo.TypeOf(n.X)) {
+					suggestNilCheck(pass, n, n.X, n.Op)
+				} else if typedNil(pass, n.X, pass.TypesInfo.TypeOf(n.Y)) {
+					suggestNilCheck(pass, n, n.Y, n.Op)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// reflectNilCheck returns x if e is reflect.ValueOf(x).IsNil() for x of
+// a nillable type other than interfaces, or nil.
+func reflectNilCheck(pass *analysis.Pass, e ast.Expr) ast.Expr {
+	call, ok := astutil.Unparen(e).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "IsNil" {
+		return nil
+	}
+	inner, ok := astutil.Unparen(sel.X).(*ast.CallExpr)
+	if !ok || len(inner.Args) != 1 {
+		return nil
+	}
+	var id *ast.Ident
+	switch fun := astutil.Unparen(inner.Fun).(type) {
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	case *ast.Ident:
+		id = fun
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" || fn.Name() != "ValueOf" {
+		return nil
+	}
+	x := inner.Args[0]
+	if t := pass.TypesInfo.TypeOf(x); t == nil || types.IsInterface(t) || !isNillable(t) && !isFunc(t) && !isChan(t) {
+		return nil
+	}
+	return x
+}
+
+// typedNil reports whether e converts nil to the type t, which isn't an
+// interface.
+func typedNil(pass *analysis.Pass, e ast.Expr, t types.Type) bool {
+	call, ok := astutil.Unparen(e).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !isNilIdent(pass, call.Args[0]) {
+		return false
+	}
+	if tv, ok := pass.TypesInfo.Types[call.Fun]; !ok || !tv.IsType() {
+		return false
+	}
+	return t != nil && !types.IsInterface(t) && types.Identical(pass.TypesInfo.TypeOf(call), t)
+}
+
+// suggestNilCheck reports the nil check n of x, suggesting to replace it
+// with the comparison of x with nil by op.
+func suggestNilCheck(pass *analysis.Pass, n ast.Node, x ast.Expr, op token.Token) {
+	check := fmt.Sprintf("%s %s nil", types.ExprString(x), op)
+	reportDiag(pass, analysis.Diagnostic{
+		Pos:     n.Pos(),
+		Message: fmt.Sprintf("write this nil check as %s", check),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Replace with " + check,
+			TextEdits: []analysis.TextEdit{{Pos: n.Pos(), End: n.End(), NewText: []byte(check)}},
+		}},
+	})
+}
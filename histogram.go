@@ -0,0 +1,75 @@
+package nilarg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// reportHistogram makes the analyzer report the histogram of the kinds
+// of the dereferences of nillable parameters of each package.
+var reportHistogram bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&reportHistogram, "histogram", false,
+		"report the numbers of guarded and unguarded dereferences of nillable parameters by kind for each package")
+}
+
+// Histogram counts the dereferences of nillable parameters of a package
+// by the kind of the operation, e.g. "map write" or "call".
+type Histogram map[string]*Count
+
+// Count is the number of the dereferences of a kind with and without a
+// dominating nil check.
+type Count struct {
+	Guarded, Unguarded int
+}
+
+// String formats h as kind guarded/total pairs sorted by the kind.
+func (h Histogram) String() string {
+	var kinds []string
+	for kind, c := range h {
+		kinds = append(kinds, fmt.Sprintf("%s %d/%d", kind, c.Guarded, c.Guarded+c.Unguarded))
+	}
+	sort.Strings(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+// derefKinds maps the descriptions of panics returned by dereference to
+// the kinds of the operations.
+var derefKinds = map[string]string{
+	"dereferenced":            "pointer dereference",
+	"indexed":                 "index",
+	"type asserted":           "type assertion",
+	"bound to a method value": "method value",
+	"sliced":                  "slice",
+	"stored through":          "store",
+	"written to":              "map write",
+}
+
+// histogram computes the histogram of the package of fns.
+func histogram(pass *analysis.Pass, fns []*ssa.Function) Histogram {
+	h := make(Histogram)
+	paramDerefs(pass, fns, func(what string, guarded bool) {
+		kind, ok := derefKinds[what]
+		if !ok {
+			// Passed to a function with a panicArgs fact.
+			kind = "call"
+		}
+		if h[kind] == nil {
+			h[kind] = new(Count)
+		}
+		if guarded {
+			h[kind].Guarded++
+		} else {
+			h[kind].Unguarded++
+		}
+	})
+	if reportHistogram && len(pass.Files) > 0 && len(h) > 0 {
+		report(pass, pass.Files[0].Package, "dereferences of nillable parameters by kind (guarded/total): %s", h)
+	}
+	return h
+}
@@ -0,0 +1,197 @@
+// Package diff computes the line diffs the nilarg command prints to
+// preview suggested fixes, in the unified format of diff -u.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of an edit of a line.
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+// Edit is an edit of a line, which keeps, deletes or inserts it.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Lines returns the shortest edit script turning the lines a into b by
+// the algorithm of Myers, "An O(ND) Difference Algorithm and Its
+// Variations", deletions coming before insertions in each change.
+func Lines(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	// v[k+max] is the furthest x on the diagonal k = x-y, and trace has
+	// v before each step d.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+	d := 0
+search:
+	for ; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[k-1+max] < v[k+1+max] {
+				x = v[k+1+max]
+			} else {
+				x = v[k-1+max] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+max] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace back from the end.
+	var edits []Edit
+	x, y := n, m
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prev int
+		if k == -d || k != d && v[k-1+max] < v[k+1+max] {
+			prev = k + 1
+		} else {
+			prev = k - 1
+		}
+		px := v[prev+max]
+		py := px - prev
+		for x > px && y > py {
+			x--
+			y--
+			edits = append(edits, Edit{Equal, a[x]})
+		}
+		if x == px {
+			y--
+			edits = append(edits, Edit{Insert, b[y]})
+		} else {
+			x--
+			edits = append(edits, Edit{Delete, a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		edits = append(edits, Edit{Equal, a[x]})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// context is the number of unchanged lines around the changes in hunks.
+const context = 3
+
+// Unified returns the unified diff turning the text a of the file from
+// into the text b of the file to, or "" if they are equal.
+func Unified(from, to, a, b string) string {
+	edits := Lines(splitLines(a), splitLines(b))
+	var out strings.Builder
+	// Find the hunks, the runs of edits whose changes are at most
+	// 2*context unchanged lines apart, with their context.
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			eq := end
+			for eq < len(edits) && edits[eq].Op == Equal {
+				eq++
+			}
+			if eq == len(edits) || eq-end > 2*context {
+				end += context
+				if end > eq {
+					end = eq
+				}
+				break
+			}
+			end = eq
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+		}
+		writeHunk(&out, edits, start, end)
+		i = end
+	}
+	return out.String()
+}
+
+// writeHunk writes the hunk of edits[start:end].
+func writeHunk(out *strings.Builder, edits []Edit, start, end int) {
+	// The lines before the hunk in a and b.
+	la, lb := 0, 0
+	for _, e := range edits[:start] {
+		if e.Op != Insert {
+			la++
+		}
+		if e.Op != Delete {
+			lb++
+		}
+	}
+	na, nb := 0, 0
+	for _, e := range edits[start:end] {
+		if e.Op != Insert {
+			na++
+		}
+		if e.Op != Delete {
+			nb++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(la, na), hunkRange(lb, nb))
+	for _, e := range edits[start:end] {
+		out.WriteByte(byte(e.Op))
+		out.WriteString(e.Line)
+		if !strings.HasSuffix(e.Line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of n lines after the line before as diff
+// -u does, which numbers an empty range by the line before it.
+func hunkRange(before, n int) string {
+	if n == 1 {
+		return fmt.Sprint(before + 1)
+	}
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+// splitLines splits s into lines, keeping their newlines.
+func splitLines(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexByte(s, '\n') + 1
+		if i == 0 {
+			i = len(s)
+		}
+		lines = append(lines, s[:i])
+		s = s[i:]
+	}
+	return lines
+}
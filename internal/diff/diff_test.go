@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// lines returns the lines of the small alphabet picked by bs, so that
+// random texts have many common lines.
+func lines(bs []byte) []string {
+	var ls []string
+	for _, b := range bs {
+		ls = append(ls, string('a'+b%4)+"\n")
+	}
+	return ls
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []string) int {
+	t := make([][]int, len(a)+1)
+	for i := range t {
+		t[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				t[i][j] = t[i+1][j+1] + 1
+			case t[i+1][j] > t[i][j+1]:
+				t[i][j] = t[i+1][j]
+			default:
+				t[i][j] = t[i][j+1]
+			}
+		}
+	}
+	return t[0][0]
+}
+
+var config = &quick.Config{MaxCount: 2000}
+
+func TestLines(t *testing.T) {
+	f := func(ba, bb []byte) bool {
+		a, b := lines(ba), lines(bb)
+		var ga, gb []string
+		changes := 0
+		for _, e := range Lines(a, b) {
+			if e.Op != Insert {
+				ga = append(ga, e.Line)
+			}
+			if e.Op != Delete {
+				gb = append(gb, e.Line)
+			}
+			if e.Op != Equal {
+				changes++
+			}
+		}
+		return reflect.DeepEqual(ga, a) && reflect.DeepEqual(gb, b) && changes == len(a)+len(b)-2*lcs(a, b)
+	}
+	if err := quick.Check(f, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	b := "1\n2\n3\nx\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\ny\n"
+	want := `--- a/f.go
++++ b/f.go
+@@ -1,6 +1,7 @@
+ 1
+ 2
+ 3
++x
+ 4
+ 5
+ 6
+@@ -13,4 +14,4 @@
+ 13
+ 14
+ 15
+-16
++y
+`
+	if got := Unified("a/f.go", "b/f.go", a, b); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified("a", "b", a, a); got != "" {
+		t.Errorf("Unified() of equal texts = %q, want \"\"", got)
+	}
+	if got := Unified("a", "b", "x", "y"); !strings.Contains(got, "-x\n\\ No newline at end of file\n+y\n\\ No newline at end of file\n") {
+		t.Errorf("Unified() without newlines =\n%s", got)
+	}
+}
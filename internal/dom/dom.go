@@ -0,0 +1,149 @@
+// Package dom answers the dominance questions the nilarg analyzer asks
+// about control-flow graphs, such as whether a block is only reached
+// through the branch of a nil check.
+//
+// The blocks of a graph are numbered from 0, which is the entry block.
+package dom
+
+// Graph is a control-flow graph.
+type Graph interface {
+	// Len returns the number of blocks.
+	Len() int
+	// Succs and Preds return the successors and the predecessors of
+	// the block b. An edge appears as many times as it is in the
+	// graph, as in a branch whose both successors are the same block.
+	Succs(b int) []int
+	Preds(b int) []int
+}
+
+// Tree is a dominator tree, mapping each block to its immediate
+// dominator. The entry block and the blocks unreachable from it have
+// no immediate dominator, which is -1.
+type Tree []int
+
+// Idoms computes the dominator tree of g by the iterative algorithm of
+// Cooper, Harvey and Kennedy, "A Simple, Fast Dominance Algorithm".
+func Idoms(g Graph) Tree {
+	n := g.Len()
+	t := make(Tree, n)
+	for b := range t {
+		t[b] = -1
+	}
+	if n == 0 {
+		return t
+	}
+	// Number the reachable blocks in reverse postorder.
+	order := make([]int, n)
+	for b := range order {
+		order[b] = -1
+	}
+	var post []int
+	seen := make([]bool, n)
+	var visit func(b int)
+	visit = func(b int) {
+		seen[b] = true
+		for _, s := range g.Succs(b) {
+			if !seen[s] {
+				visit(s)
+			}
+		}
+		post = append(post, b)
+	}
+	visit(0)
+	rpo := make([]int, len(post))
+	for k, b := range post {
+		rpo[len(post)-1-k] = b
+		order[b] = len(post) - 1 - k
+	}
+
+	intersect := func(a, b int) int {
+		for a != b {
+			for order[a] > order[b] {
+				a = t[a]
+			}
+			for order[b] > order[a] {
+				b = t[b]
+			}
+		}
+		return a
+	}
+	t[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for _, b := range rpo[1:] {
+			idom := -1
+			for _, p := range g.Preds(b) {
+				if order[p] < 0 || t[p] < 0 {
+					// unreachable or not processed yet
+					continue
+				}
+				if idom < 0 {
+					idom = p
+				} else {
+					idom = intersect(p, idom)
+				}
+			}
+			if t[b] != idom {
+				t[b] = idom
+				changed = true
+			}
+		}
+	}
+	t[0] = -1
+	return t
+}
+
+// Dominates reports whether the block a dominates the block b, that is,
+// every path from the entry to b goes through a. Every block reachable
+// from the entry dominates itself.
+func (t Tree) Dominates(a, b int) bool {
+	if b != 0 && t[b] < 0 {
+		return false
+	}
+	for ; b >= 0; b = t[b] {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+// EdgeDominates reports whether the edge from the block from to the
+// block to dominates the block b, that is, every path from the entry to
+// b goes through the edge. This is the case when to dominates b and is
+// only entered through the edge, except from the blocks it dominates,
+// such as the latches of a loop whose header is to.
+func (t Tree) EdgeDominates(g Graph, from, to, b int) bool {
+	if !t.Dominates(to, b) || !t.Dominates(0, from) {
+		return false
+	}
+	edges := 0
+	for _, p := range g.Preds(to) {
+		if p == from {
+			edges++
+			continue
+		}
+		if t.Dominates(0, p) && !t.Dominates(to, p) {
+			return false
+		}
+	}
+	// An edge taken twice from the same block, as in a branch whose
+	// both successors are to, doesn't tell which way was taken. A
+	// block dominated by to can't enter it through the edge either.
+	return edges == 1 && !t.Dominates(to, from)
+}
+
+// Guards calls f for the edges from the strict dominators of the block b
+// to their successors which dominate b, from the innermost dominator,
+// until f returns true, and reports whether it did.
+func (t Tree) Guards(g Graph, b int, f func(from, to int) bool) bool {
+	for d := b; t[d] >= 0; d = t[d] {
+		u := t[d]
+		for _, s := range g.Succs(u) {
+			if t.EdgeDominates(g, u, s, b) && f(u, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package dom
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// graph is a random control-flow graph for the property tests.
+type graph struct {
+	succs [][]int
+	preds [][]int
+}
+
+func (g *graph) Len() int          { return len(g.succs) }
+func (g *graph) Succs(b int) []int { return g.succs[b] }
+func (g *graph) Preds(b int) []int { return g.preds[b] }
+
+// Generate makes graphs of up to 12 blocks with up to 2 successors each,
+// as branches have, including loops, parallel edges and unreachable
+// blocks.
+func (*graph) Generate(r *rand.Rand, size int) reflect.Value {
+	n := 1 + r.Intn(12)
+	g := &graph{succs: make([][]int, n), preds: make([][]int, n)}
+	for b := 0; b < n; b++ {
+		for k := r.Intn(3); k > 0; k-- {
+			s := r.Intn(n)
+			g.succs[b] = append(g.succs[b], s)
+			g.preds[s] = append(g.preds[s], b)
+		}
+	}
+	return reflect.ValueOf(g)
+}
+
+// reachable reports whether b is reachable from the entry without going
+// through the block avoid or the k-th successor edge of the block from.
+func (g *graph) reachable(b, avoid, from, k int) bool {
+	seen := make([]bool, g.Len())
+	stack := []int{0}
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if x == avoid || seen[x] {
+			continue
+		}
+		seen[x] = true
+		for j, s := range g.succs[x] {
+			if x != from || j != k {
+				stack = append(stack, s)
+			}
+		}
+	}
+	return seen[b]
+}
+
+// dominates is the reference definition of dominance.
+func (g *graph) dominates(a, b int) bool {
+	return g.reachable(b, -1, -1, -1) && (a == b || !g.reachable(b, a, -1, -1))
+}
+
+// edgeDominates is the reference definition of the dominance of the
+// k-th successor edge of the block from.
+func (g *graph) edgeDominates(from, k, b int) bool {
+	return g.reachable(b, -1, -1, -1) && !g.reachable(b, -1, from, k)
+}
+
+var config = &quick.Config{MaxCount: 2000}
+
+func TestDominates(t *testing.T) {
+	f := func(g *graph) bool {
+		tree := Idoms(g)
+		for a := 0; a < g.Len(); a++ {
+			for b := 0; b < g.Len(); b++ {
+				if tree.Dominates(a, b) != g.dominates(a, b) {
+					t.Logf("Dominates(%d, %d) of %v: %v", a, b, g.succs, tree)
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIdoms(t *testing.T) {
+	f := func(g *graph) bool {
+		tree := Idoms(g)
+		for b, idom := range tree {
+			if idom < 0 {
+				continue
+			}
+			// The immediate dominator is a strict dominator which
+			// every other strict dominator dominates.
+			if idom == b || !g.dominates(idom, b) {
+				return false
+			}
+			for a := 0; a < g.Len(); a++ {
+				if a != b && g.dominates(a, b) && !g.dominates(a, idom) {
+					return false
+				}
+			}
+		}
+		return tree[0] == -1
+	}
+	if err := quick.Check(f, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEdgeDominates(t *testing.T) {
+	f := func(g *graph) bool {
+		tree := Idoms(g)
+		for from := 0; from < g.Len(); from++ {
+			for k, to := range g.succs[from] {
+				for b := 0; b < g.Len(); b++ {
+					if tree.EdgeDominates(g, from, to, b) != g.edgeDominates(from, k, b) {
+						t.Logf("EdgeDominates(%d, %d, %d) of %v: %v", from, to, b, g.succs, tree)
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGuards(t *testing.T) {
+	f := func(g *graph) bool {
+		tree := Idoms(g)
+		for b := 0; b < g.Len(); b++ {
+			want := 0
+			for from := 0; from < g.Len(); from++ {
+				for k := range g.succs[from] {
+					if g.edgeDominates(from, k, b) {
+						want++
+					}
+				}
+			}
+			got, last := 0, b
+			tree.Guards(g, b, func(from, to int) bool {
+				// Dominators are visited from the innermost.
+				if !tree.Dominates(from, last) {
+					got = -1
+					return true
+				}
+				last = from
+				got++
+				return false
+			})
+			if got != want {
+				t.Logf("Guards(%d) of %v: %d edges, want %d", b, g.succs, got, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGuardsStop(t *testing.T) {
+	// 0 -> 1 -> 2, where both 0 -> 1 and 1 -> 2 dominate 2.
+	g := &graph{succs: [][]int{{1}, {2}, {}}, preds: [][]int{{}, {0}, {1}}}
+	var calls [][2]int
+	ok := Idoms(g).Guards(g, 2, func(from, to int) bool {
+		calls = append(calls, [2]int{from, to})
+		return true
+	})
+	if !ok || !reflect.DeepEqual(calls, [][2]int{{1, 2}}) {
+		t.Errorf("Guards = %v, %v; want true, [[1 2]]", ok, calls)
+	}
+}
@@ -0,0 +1,122 @@
+package nilarg
+
+import (
+	"bufio"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+// lang overrides the Go language version of the analyzed code, which is
+// otherwise read from the go directive of the go.mod file of the module.
+var lang string
+
+func init() {
+	Analyzer.Flags.StringVar(&lang, "lang", "",
+		"Go language version of the analyzed code such as go1.21, overriding the go directive of go.mod")
+}
+
+// langVersion returns the minor version of the Go language the package
+// of pass is written in, or 0 if it isn't known, as for packages outside
+// modules.
+func langVersion(pass *analysis.Pass) int {
+	v := strings.TrimPrefix(lang, "go")
+	if v == "" && len(pass.Files) > 0 {
+		v = goDirective(filepath.Dir(pass.Fset.Position(pass.Files[0].Pos()).Filename))
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0
+	}
+	return minor
+}
+
+// goDirective returns the version in the go directive of the go.mod file
+// in dir or its closest parent having one.
+func goDirective(dir string) string {
+	for {
+		f, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				fields := strings.Fields(s.Text())
+				if len(fields) == 2 && fields[0] == "go" {
+					return fields[1]
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// sharedLoopVars reports whether the variables declared by the init
+// statement of a for loop are shared by the iterations, as they are
+// before Go 1.22 and outside modules.
+func sharedLoopVars(pass *analysis.Pass) bool {
+	return langVersion(pass) < 22
+}
+
+// loopEndsNil reports whether the loop enclosing the goroutine launch c
+// runs while the variable alloc captured by the goroutine is not nil,
+// so that the goroutine can observe the nil value left by the last
+// iteration, as in
+//
+//	for p := first; p != nil; p = p.next {
+//		go func() { p.f() }()
+//	}
+//
+// It also reports whether alloc is declared by the loop, in which case
+// this only happens before Go 1.22.
+func loopEndsNil(pass *analysis.Pass, alloc *ssa.Alloc, c ssa.Instruction) (ok, declared bool) {
+	var file *ast.File
+	for _, f := range pass.Files {
+		if f.Pos() <= c.Pos() && c.Pos() < f.End() {
+			file = f
+		}
+	}
+	if file == nil {
+		return false, false
+	}
+	path, _ := astutil.PathEnclosingInterval(file, c.Pos(), c.Pos())
+	for _, n := range path {
+		loop, isLoop := n.(*ast.ForStmt)
+		if !isLoop || loop.Body.Pos() > c.Pos() || !whileNotNil(pass, loop.Cond, alloc.Pos()) {
+			continue
+		}
+		declared = loop.Init != nil && loop.Init.Pos() <= alloc.Pos() && alloc.Pos() < loop.Init.End()
+		return !declared || sharedLoopVars(pass), declared
+	}
+	return false, false
+}
+
+// whileNotNil reports whether cond is v != nil for the variable v
+// declared at pos.
+func whileNotNil(pass *analysis.Pass, cond ast.Expr, pos token.Pos) bool {
+	bin, ok := astutil.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return false
+	}
+	x, y := bin.X, bin.Y
+	if isNilIdent(pass, x) {
+		x, y = y, x
+	}
+	id, ok := astutil.Unparen(x).(*ast.Ident)
+	return ok && isNilIdent(pass, y) && pass.TypesInfo.Uses[id] != nil && pass.TypesInfo.Uses[id].Pos() == pos
+}
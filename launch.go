@@ -0,0 +1,124 @@
+package nilarg
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// launchers are the methods which run the function passed to them in a
+// new goroutine.
+var launchers = map[string]bool{
+	"(*sync.WaitGroup).Go":                      true,
+	"(*golang.org/x/sync/errgroup.Group).Go":    true,
+	"(*golang.org/x/sync/errgroup.Group).TryGo": true,
+}
+
+// checkLauncher reports the go statement or the call of a goroutine
+// launcher c if the function literal submitted to it captures a
+// variable which is nil in the caller and dereferences it, as in
+//
+//	var p *T
+//	g.Go(func() error { return p.f() })
+//
+// or in a go statement with sync.WaitGroup:
+//
+//	go func() { defer wg.Done(); p.f() }()
+func checkLauncher(pass *analysis.Pass, c ssa.CallInstruction, stack []fact) {
+	var mc *ssa.MakeClosure
+	switch c := c.(type) {
+	case *ssa.Go:
+		mc, _ = c.Call.Value.(*ssa.MakeClosure)
+	case *ssa.Call:
+		s := c.Call.StaticCallee()
+		if s == nil || len(c.Call.Args) != 2 {
+			return
+		}
+		if f, ok := s.Object().(*types.Func); !ok || !launchers[f.FullName()] {
+			return
+		}
+		mc, _ = c.Call.Args[1].(*ssa.MakeClosure)
+	}
+	if mc == nil {
+		return
+	}
+	for k, bv := range mc.Bindings {
+		fv := mc.Fn.(*ssa.Function).FreeVars[k]
+		v := capturedValue(bv, c)
+		if v == nil {
+			checkLoopCapture(pass, c, bv, fv)
+			continue
+		}
+		if nilnessOf(pass, stack, v) != isnil {
+			continue
+		}
+		if _, what := freeVarUse(pass, fv); what != "" {
+			report(pass, c.Pos(), "this goroutine can panic: %s is nil and %s in the submitted function", fv.Name(), what)
+		}
+	}
+}
+
+// checkLoopCapture reports the goroutine launch c if the variable bv
+// captured as fv is shared by the iterations of a loop which ends when
+// it is nil, and the goroutine dereferences it.
+func checkLoopCapture(pass *analysis.Pass, c ssa.CallInstruction, bv ssa.Value, fv *ssa.FreeVar) {
+	alloc, ok := bv.(*ssa.Alloc)
+	if !ok {
+		return
+	}
+	ok, declared := loopEndsNil(pass, alloc, c)
+	if !ok {
+		return
+	}
+	if _, what := freeVarUse(pass, fv); what != "" {
+		if declared {
+			report(pass, c.Pos(), "this goroutine can panic: the iterations share %s before Go 1.22 and it is nil when the loop ends", fv.Name())
+			return
+		}
+		report(pass, c.Pos(), "this goroutine can panic: the iterations share %s and it is nil when the loop ends", fv.Name())
+	}
+}
+
+// capturedValue returns the value of the variable bv captured by a
+// closure at the call c, if the variable is assigned at most once in
+// a block dominating c.
+func capturedValue(bv ssa.Value, c ssa.Instruction) ssa.Value {
+	alloc, ok := bv.(*ssa.Alloc)
+	if !ok || alloc.Referrers() == nil {
+		return nil
+	}
+	var store *ssa.Store
+	for _, r := range *alloc.Referrers() {
+		if st, ok := r.(*ssa.Store); ok && st.Addr == alloc {
+			if store != nil {
+				return nil
+			}
+			store = st
+		}
+	}
+	if store == nil {
+		// The variable has the zero value.
+		if isNillable(alloc.Type().Underlying().(*types.Pointer).Elem()) {
+			return ssa.NewConst(nil, alloc.Type().Underlying().(*types.Pointer).Elem())
+		}
+		return nil
+	}
+	if !store.Block().Dominates(c.Block()) || store.Block() == c.Block() && !precedes(store, c) {
+		return nil
+	}
+	return store.Val
+}
+
+// precedes reports whether the instruction a precedes b in their block.
+func precedes(a, b ssa.Instruction) bool {
+	for _, instr := range a.Block().Instrs {
+		switch instr {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
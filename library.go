@@ -0,0 +1,88 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+var (
+	// assumeExported makes the analyzer treat every exported function
+	// as called by users of the package with nil for any nillable
+	// argument.
+	assumeExported bool
+
+	// contractsOnly makes the analyzer report only the contracts of all
+	// the functions panicking on nil arguments, for nilarg triage.
+	contractsOnly bool
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&assumeExported, "exported-callers", false,
+		"report the panics of exported functions which callers outside the package can trigger with nil arguments")
+	Analyzer.Flags.BoolVar(&contractsOnly, "contracts", false,
+		"report only the contracts of the parameters of all functions which panic on nil, with functions named T.M")
+}
+
+// contractCategory is the category of the diagnostics reported with
+// -contracts.
+const contractCategory = "contract"
+
+// reportExported reports the parameters of the exported functions of
+// fns for which the functions have panicArgs facts, regardless of the
+// callers in the package, with the contracts describing the panics.
+// With -contracts, the parameters of all the functions are reported.
+func reportExported(pass *analysis.Pass, fns []*ssa.Function, contracts map[token.Pos]string) {
+	if !assumeExported && !contractsOnly {
+		return
+	}
+	for _, fn := range fns {
+		obj := factObject(fn)
+		if obj == nil || !contractsOnly && !isExported(obj) {
+			continue
+		}
+		var fact panicArgs
+		if !pass.ImportObjectFact(obj, &fact) {
+			continue
+		}
+		for i, fp := range fn.Params {
+			if _, ok := fact[i]; !ok || isNilSafeRecv(pass, obj, i) {
+				continue
+			}
+			c := contracts[fp.Pos()]
+			if c == "" {
+				c = "must not be nil"
+			}
+			if contractsOnly {
+				reportDiag(pass, analysis.Diagnostic{
+					Pos:      fp.Pos(),
+					Category: contractCategory,
+					Message:  fmt.Sprintf("callers of %s can cause panic by passing nil %s: %s", objName(obj), fp.Name(), c),
+				})
+				continue
+			}
+			report(pass, fp.Pos(), "callers of %s can cause panic by passing nil %s: %s", fn.Name(), fp.Name(), c)
+		}
+	}
+}
+
+// isExported reports whether obj can be called from other packages,
+// that is, obj is exported and so is the receiver type of a method.
+func isExported(obj types.Object) bool {
+	if !obj.Exported() {
+		return false
+	}
+	sig, ok := obj.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return true
+	}
+	t := sig.Recv().Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return !ok || named.Obj().Exported()
+}
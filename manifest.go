@@ -0,0 +1,181 @@
+package nilarg
+
+import (
+	"encoding/json"
+	"go/build"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// manifestName is the name of the manifest file in the directory of a
+// package.
+const manifestName = "nilarg.json"
+
+// writeManifest makes the analyzer write the manifest of each package
+// to its directory, e.g. from a go:generate directive, so that it is
+// published with the module.
+var writeManifest bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&writeManifest, "write-manifest", false,
+		"write the nil contracts of the exported functions of each package to "+manifestName+" in its directory")
+}
+
+// Manifest lists the nil contracts of the exported functions of a
+// package. It is read for the calls to the functions of packages which
+// have no facts, e.g. when their source can't be analyzed.
+type Manifest struct {
+	Package   string
+	Contracts []ManifestContract
+}
+
+// ManifestContract is a parameter of Func, named as in -run, which
+// must not be nil. Param is the index of the parameter, counting the
+// receiver of a method as the first.
+type ManifestContract struct {
+	Func     string
+	Param    int
+	Name     string
+	Contract string
+}
+
+// saveManifest writes the manifest of the exported functions of fns
+// declared in the non-test files of the package.
+func saveManifest(pass *analysis.Pass, fns []*ssa.Function, contracts map[token.Pos]string) error {
+	if !writeManifest || len(pass.Files) == 0 {
+		return nil
+	}
+	m := Manifest{Package: pass.Pkg.Path(), Contracts: []ManifestContract{}}
+	for _, fn := range fns {
+		obj := factObject(fn)
+		if obj == nil || !isExported(obj) || strings.HasSuffix(pass.Fset.Position(fn.Pos()).Filename, "_test.go") {
+			continue
+		}
+		var fact panicArgs
+		if !pass.ImportObjectFact(obj, &fact) {
+			continue
+		}
+		for i, fp := range fn.Params {
+			if _, ok := fact[i]; !ok || isNilSafeRecv(pass, obj, i) {
+				continue
+			}
+			c := contracts[fp.Pos()]
+			if c == "" {
+				c = "must not be nil"
+			}
+			m.Contracts = append(m.Contracts, ManifestContract{objName(obj), i, fp.Name(), c})
+		}
+	}
+	sort.Slice(m.Contracts, func(i, j int) bool {
+		a, b := m.Contracts[i], m.Contracts[j]
+		return a.Func < b.Func || a.Func == b.Func && a.Param < b.Param
+	})
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(pass.Fset.Position(pass.Files[0].Pos()).Filename)
+	return ioutil.WriteFile(filepath.Join(dir, manifestName), append(data, '\n'), 0644)
+}
+
+// manifests caches the manifests read by the import path of their
+// packages and the fact files, with nil for the packages without one.
+var manifests = struct {
+	sync.Mutex
+	m map[string]*Manifest
+}{m: make(map[string]*Manifest)}
+
+// manifestOf returns the manifest of the package of obj, or nil if there
+// is none. The manifest is looked up in the directory of the file
+// declaring obj, which is recorded in the export data, and then in the
+// directory of the package found by go/build. The manifest takes
+// precedence over the manifests of the package in the fact files.
+func manifestOf(pass *analysis.Pass, obj types.Object) *Manifest {
+	path := obj.Pkg().Path()
+	key := factFiles + "\x00" + path
+	manifests.Lock()
+	defer manifests.Unlock()
+	if m, ok := manifests.m[key]; ok {
+		return m
+	}
+	var dirs []string
+	if posn := pass.Fset.Position(obj.Pos()); posn.IsValid() {
+		dirs = append(dirs, filepath.Dir(posn.Filename))
+	}
+	if p, err := build.Import(path, "", build.FindOnly); err == nil {
+		dirs = append(dirs, p.Dir)
+	}
+	var m *Manifest
+	for _, dir := range dirs {
+		f, err := os.Open(filepath.Join(dir, manifestName))
+		if err != nil {
+			continue
+		}
+		var mm Manifest
+		err = json.NewDecoder(f).Decode(&mm)
+		f.Close()
+		if err == nil && mm.Package == path {
+			m = &mm
+			break
+		}
+	}
+	if db, _ := loadFactDB(); db != nil && db.Manifest(path) != nil {
+		merged := NewDB(*db.Manifest(path))
+		if m != nil {
+			merged.Add(*m)
+		}
+		m = merged.Manifest(path)
+	}
+	manifests.m[key] = m
+	return m
+}
+
+// importPanicArgs imports the panicArgs fact of obj into fact, or builds
+// it from the manifest of the package of obj when obj has no facts, e.g.
+// when the package was not analyzed or obj is implemented in assembly.
+func importPanicArgs(pass *analysis.Pass, obj types.Object, fact *panicArgs) bool {
+	if pass.ImportObjectFact(obj, fact) {
+		return true
+	}
+	if obj.Pkg() == pass.Pkg {
+		return false
+	}
+	m := manifestOf(pass, obj)
+	if m == nil {
+		return false
+	}
+	name := objName(obj)
+	*fact = panicArgs{}
+	for _, c := range m.Contracts {
+		if c.Func == name {
+			(*fact)[c.Param] = struct{}{}
+		}
+	}
+	return len(*fact) > 0
+}
+
+// objName returns the name of obj, qualified by the receiver type name
+// for methods as in -run.
+func objName(obj types.Object) string {
+	sig, ok := obj.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return obj.Name()
+	}
+	t := sig.Recv().Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name() + "." + obj.Name()
+	}
+	return obj.Name()
+}
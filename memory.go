@@ -0,0 +1,51 @@
+package nilarg
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+var (
+	// releaseSSA drops the SSA function bodies of a package once the
+	// analyzer is done with them. The bodies belong to the buildssa
+	// result, so this is only safe when no other analyzer uses it.
+	releaseSSA bool
+
+	// stream writes diagnostics to the standard error as soon as they
+	// are found instead of recording them for the results and -dry-run.
+	// They are still reported to the driver, which decides the exit
+	// status by them.
+	stream bool
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&releaseSSA, "release-ssa", false,
+		"drop SSA function bodies after analyzing a package (unsafe if other analyzers use buildssa)")
+	Analyzer.Flags.BoolVar(&stream, "stream", false,
+		"write diagnostics to stderr as soon as they are found instead of recording them, still reporting them to the driver")
+}
+
+// release drops the bodies of fns and their anonymous functions.
+func release(fns []*ssa.Function) {
+	if !releaseSSA {
+		return
+	}
+	for _, fn := range fns {
+		release(fn.AnonFuncs)
+		fn.Blocks = nil
+		fn.Locals = nil
+		fn.Recover = nil
+	}
+}
+
+// streamDiag writes d to the standard error.
+func streamDiag(pass *analysis.Pass, d analysis.Diagnostic) {
+	if fingerprints {
+		fmt.Fprintf(os.Stderr, "%s: %s [%s]\n", pass.Fset.Position(d.Pos), d.Message, d.Category)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n", pass.Fset.Position(d.Pos), d.Message)
+}
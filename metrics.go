@@ -0,0 +1,43 @@
+package nilarg
+
+import (
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Metrics counts the work of the fixpoint computing the facts of a
+// package, which checks all its functions in rounds until no fact
+// changes, so that changes of the analysis can be checked not to slow
+// down its convergence.
+type Metrics struct {
+	// Package is the path of the package.
+	Package string
+	// Funcs is the number of the functions of the package.
+	Funcs int
+	// Rounds is the number of the rounds over the functions, including
+	// the last one which changed nothing.
+	Rounds int
+	// Checks is the number of the checks of functions in all the
+	// rounds, and Changes the number of the facts they changed.
+	Checks, Changes int
+}
+
+// OnMetrics, if not nil, is called with the metrics of each package when
+// its fixpoint ends. The calls are serialized even when the driver
+// analyzes packages in parallel.
+var OnMetrics func(Metrics)
+
+var metricsMu sync.Mutex
+
+// reportMetrics reports m, the metrics of the package of pass, to
+// OnMetrics.
+func reportMetrics(pass *analysis.Pass, m Metrics) {
+	if OnMetrics == nil {
+		return
+	}
+	m.Package = pass.Pkg.Path()
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	OnMetrics(m)
+}
@@ -0,0 +1,67 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// missingFacts reports the dependencies which weren't analyzed, whose
+// contracts are unknown.
+var missingFacts bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&missingFacts, "missing-facts", false,
+		"report once per package the dependencies without facts or manifests, whose calls are not checked")
+}
+
+const missingFactsCategory = "missing-facts"
+
+// missingDeps maps the passes being run to the dependencies without facts
+// which their functions call, with the first of the calls.
+var missingDeps = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[*types.Package]token.Pos
+}{m: make(map[*analysis.Pass]map[*types.Package]token.Pos)}
+
+// noteMissingDep records that the call at pos calls a function of the
+// dependency pkg, which has no facts.
+func noteMissingDep(pass *analysis.Pass, pkg *types.Package, pos token.Pos) {
+	missingDeps.Lock()
+	defer missingDeps.Unlock()
+	deps := missingDeps.m[pass]
+	if deps == nil {
+		deps = make(map[*types.Package]token.Pos)
+		missingDeps.m[pass] = deps
+	}
+	if p, ok := deps[pkg]; !ok || pos < p {
+		deps[pkg] = pos
+	}
+}
+
+// reportMissingDeps reports the dependencies recorded for pass with
+// -missing-facts, at their first calls, and forgets them.
+func reportMissingDeps(pass *analysis.Pass) {
+	missingDeps.Lock()
+	deps := missingDeps.m[pass]
+	delete(missingDeps.m, pass)
+	missingDeps.Unlock()
+	if !missingFacts {
+		return
+	}
+	pkgs := make([]*types.Package, 0, len(deps))
+	for pkg := range deps {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path() < pkgs[j].Path() })
+	for _, pkg := range pkgs {
+		reportDiag(pass, analysis.Diagnostic{
+			Pos:      deps[pkg],
+			Category: missingFactsCategory,
+			Message:  "calls to " + pkg.Path() + " are not checked: it was not analyzed; analyze it or pass its manifest with -facts",
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package nilarg
 
 import (
+	"fmt"
+	"go/constant"
 	"go/token"
 	"go/types"
-	"math/big"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/buildssa"
@@ -29,11 +34,68 @@ instructions that refer the arguments are not reachable.
 `
 
 var Analyzer = &analysis.Analyzer{
-	Name:      "nilarg",
-	Doc:       Doc,
-	Run:       run,
-	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
-	FactTypes: []analysis.Fact{new(panicArgs), new(pkgDone)},
+	Name:     "nilarg",
+	Doc:      Doc,
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{
+		new(panicArgs),
+		new(pkgDone),
+		new(nilSafeRecv),
+		new(nilReturns),
+		new(panicFields),
+		new(intentionalArgs),
+		new(accessor),
+		new(identityReturns),
+		new(correlatedReturns),
+		new(nonNilOnSuccess),
+		new(conditionalArgs),
+		new(optionFields),
+		new(requiredOptions),
+		new(elementCalls),
+		new(stringerCalls),
+		new(validatedArgs),
+		new(variadicElems),
+		new(typedNilChecks),
+	},
+	ResultType: reflect.TypeOf(new(Result)),
+}
+
+// factsOnly makes the analyzer a pure fact provider: facts and the
+// result are still exported, but no diagnostics are reported.
+var factsOnly bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&factsOnly, "factsonly", false,
+		"export facts and the result without reporting diagnostics")
+}
+
+// Result is the result of the nilarg Analyzer.
+type Result struct {
+	// PanicArgs maps each function in the package to the sorted
+	// indices of its parameters that cause panic when they are nil.
+	PanicArgs map[*types.Func][]int
+
+	// Contracts maps the position of each such parameter to a
+	// human-readable contract, e.g. "must not be nil: dereferenced
+	// at foo.go:42", suitable for showing on hover.
+	Contracts map[token.Pos]string
+
+	// Grade is the nil-safety grade of the package.
+	Grade *Grade
+
+	// Histogram counts the dereferences of nillable parameters by
+	// kind.
+	Histogram Histogram
+
+	// Findings are the diagnostics reported in the package.
+	Findings []Finding
+
+	// Files maps each file of the package to the functions declared
+	// in it, and Callers maps each function to the functions of the
+	// package calling it. Functions are named as in -run.
+	Files   map[string][]string
+	Callers map[string][]string
 }
 
 // panicArgs has the information about arguments which causes panic on
@@ -46,166 +108,1052 @@ type pkgDone struct{}
 
 func (*pkgDone) AFact() {}
 
+// nilSafeRecv marks methods that explicitly handle nil receivers, such
+// as String methods beginning with if x == nil { return "<nil>" }.
+type nilSafeRecv struct{}
+
+func (*nilSafeRecv) AFact() {}
+
+// nilReturns has the indices of the results which can be nil, because
+// the function returns a nil literal or one of its unchecked nillable
+// parameters there. Results of error types are left out.
+type nilReturns map[int]struct{}
+
+func (*nilReturns) AFact() {}
+
+func (r *nilReturns) String() string {
+	idx := make([]int, 0, len(*r))
+	for i := range *r {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return fmt.Sprintf("nilReturns%v", idx)
+}
+
+// identityReturns maps the indices of the results which are always one
+// of the parameters returned unchanged to the indices of the parameters,
+// as in
+//
+//	func id(p *T) *T { return p }
+type identityReturns map[int]int
+
+func (*identityReturns) AFact() {}
+
+func (r *identityReturns) String() string {
+	idx := make([]int, 0, len(*r))
+	for i := range *r {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	pairs := make([]string, len(idx))
+	for k, i := range idx {
+		pairs[k] = fmt.Sprintf("%d:%d", i, (*r)[i])
+	}
+	return fmt.Sprintf("identityReturns%v", pairs)
+}
+
+// correlatedReturns has the pairs of the results which are either both
+// nil or both non-nil at every return, as in
+//
+//	func lookup(k string) (*T, *U) {
+//		if k == "" {
+//			return nil, nil
+//		}
+//		return &T{}, &U{}
+//	}
+type correlatedReturns [][2]int
+
+func (*correlatedReturns) AFact() {}
+
+func (r *correlatedReturns) String() string {
+	return fmt.Sprintf("correlatedReturns%v", [][2]int(*r))
+}
+
+// nonNilOnSuccess has the indices of the results which are non-nil
+// whenever the last result of type error is nil, as in
+//
+//	func open(name string) (*File, error) {
+//		if name == "" {
+//			return nil, errors.New("empty name")
+//		}
+//		return &File{name}, nil
+//	}
+type nonNilOnSuccess map[int]struct{}
+
+func (*nonNilOnSuccess) AFact() {}
+
+func (r *nonNilOnSuccess) String() string {
+	idx := make([]int, 0, len(*r))
+	for i := range *r {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return fmt.Sprintf("nonNilOnSuccess%v", idx)
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if _, err := loadFactDB(); err != nil {
+		return nil, err
+	}
+	if err := loadBaseline(); err != nil {
+		return nil, err
+	}
+	defer startPackage(pass)()
+	defer printFixes(pass)
+	defer flushDiags(pass)
+	defer collectRegistries(pass, ssainput.Pkg, ssainput.SrcFuncs)()
+	defer collectScope(pass, ssainput.SrcFuncs)()
+	defer collectArgDirectives(pass)()
+	checkIgnoreDirectives(pass)
+	defer collectFieldFuncs(pass, ssainput.SrcFuncs)()
+	defer forgetAnonFacts(pass)
+	defer forgetGraphs(ssainput.Pkg)
+	contracts := make(map[token.Pos]string)
+	reasons := make(map[token.Pos]string)
+	for _, fn := range ssainput.SrcFuncs {
+		checkRecv(pass, fn)
+		checkReturns(pass, fn)
+		checkAccessor(pass, fn)
+		checkPreconditions(pass, fn)
+		checkPanicMessages(pass, fn)
+		checkElementCalls(pass, fn)
+		checkTypedNilChecks(pass, fn)
+	}
+	checkNilReturns(pass, ssainput.SrcFuncs)
+	checkValidators(pass, ssainput.SrcFuncs)
+	checkStringerCalls(pass, ssainput.SrcFuncs)
+	loadCheckpoint(pass, ssainput.SrcFuncs)
+	var deadline time.Time
+	if fixpointTimeout > 0 {
+		deadline = time.Now().Add(fixpointTimeout)
+	}
+	metrics := Metrics{Funcs: len(ssainput.SrcFuncs)}
+fixpoint:
 	for {
 		cc := 0
+		metrics.Rounds++
 		for _, fn := range ssainput.SrcFuncs {
-			if changed := checkFunc(pass, fn); changed {
+			metrics.Checks++
+			if changed := checkFunc(pass, fn, contracts, reasons); changed {
 				cc++
 			}
+			if checkVariadicElems(pass, fn) {
+				cc++
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				// Report the partial results and resume from them
+				// on the next run.
+				if err := saveCheckpoint(pass, ssainput.SrcFuncs); err != nil {
+					return nil, err
+				}
+				pass.ExportPackageFact(&pkgDone{})
+				break fixpoint
+			}
+		}
+		if checkFieldFuncs(pass) {
+			cc++
 		}
+		metrics.Changes += cc
 		if cc == 0 {
+			removeCheckpoint(pass)
 			pass.ExportPackageFact(&pkgDone{})
 			break
 		}
 	}
+	reportMetrics(pass, metrics)
+	reportMissingDeps(pass)
+	if err := saveManifest(pass, ssainput.SrcFuncs, contracts); err != nil {
+		return nil, err
+	}
+	validated := validatedFields(pass, ssainput.SrcFuncs)
+	for _, fn := range ssainput.SrcFuncs {
+		checkFields(pass, fn, validated)
+		checkOptions(pass, fn)
+	}
+
+	defer collectBoundFields(pass, ssainput.SrcFuncs)()
 
 	// Push the information about nilness of values like nilness and
 	// if calls are called with nil value and they can cause panic
 	// with nil arguments, report the call.
 	for _, fn := range ssainput.SrcFuncs {
-		runFunc(pass, fn)
+		if matchesSymbol(pass, fn.Pos()) {
+			runFunc(pass, fn, reasons)
+		}
 	}
+	adviseSignatures(pass, ssainput.SrcFuncs)
+	checkGuardStyle(pass)
+	suggestGuards(pass, ssainput.SrcFuncs)
+	suggestTodos(pass, ssainput.SrcFuncs)
+	reportExported(pass, ssainput.SrcFuncs, contracts)
+	checkChannels(pass, ssainput.SrcFuncs)
+	checkEmbedded(pass, ssainput.SrcFuncs)
 
-	return nil, nil
+	res := newResult(pass, ssainput.SrcFuncs)
+	res.Contracts = contracts
+	res.Grade = grade(pass, ssainput.SrcFuncs)
+	res.Histogram = histogram(pass, ssainput.SrcFuncs)
+	// The findings include the diagnostics held back by -combine.
+	flushDiags(pass)
+	res.Findings = takeFindings(pass)
+	callGraph(pass, res, ssainput.SrcFuncs)
+	release(ssainput.SrcFuncs)
+	return res, nil
+}
+
+// newResult collects the panicArgs facts of fns exported by this pass.
+func newResult(pass *analysis.Pass, fns []*ssa.Function) *Result {
+	res := &Result{PanicArgs: make(map[*types.Func][]int)}
+	for _, fn := range fns {
+		f, ok := factObject(fn).(*types.Func)
+		if !ok {
+			continue
+		}
+		var fact panicArgs
+		if !pass.ImportObjectFact(f, &fact) {
+			continue
+		}
+		idx := make([]int, 0, len(fact))
+		for i := range fact {
+			idx = append(idx, i)
+		}
+		sort.Ints(idx)
+		res.PanicArgs[f] = idx
+	}
+	return res
+}
+
+// report reports a diagnostic at pos unless the analyzer is running
+// as a fact provider.
+func report(pass *analysis.Pass, pos token.Pos, format string, args ...interface{}) {
+	reportDiag(pass, analysis.Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// reportDiag reports d unless the analyzer is running as a fact
+// provider.
+func reportDiag(pass *analysis.Pass, d analysis.Diagnostic) {
+	if factsOnly || guards && d.Category != guardCategory || contractsOnly && d.Category != contractCategory || todos && d.Category != todoCategory || !matchesRun(pass, d.Pos) || !matchesSymbol(pass, d.Pos) || !matchesReanalysis(pass, d.Pos) {
+		return
+	}
+	fp := fingerprint(pass, d)
+	if suppressed(pass, &d, fp) {
+		return
+	}
+	if fingerprints {
+		d.Category = fp
+	}
+	if combine {
+		holdDiag(pass, d)
+		return
+	}
+	emitDiag(pass, d)
+}
+
+// emitDiag counts, records and reports d. With stream, d is written
+// out at once and reported without being recorded, so that the driver
+// still exits with the status of the findings.
+func emitDiag(pass *analysis.Pass, d analysis.Diagnostic) {
+	countFinding(pass)
+	if stream {
+		streamDiag(pass, d)
+	} else {
+		recordFinding(pass, d)
+		holdFix(pass, d)
+	}
+	pass.Report(d)
 }
 
 // This function checkFunc checks all the nillable type arguments of
 // the function fn and instructions in fn that refer the arguments.
 // If those instructions cause panic when the referred argument is nil,
 // then this function exports the information as the ObjectFact of fn
-// using panicArgs type.
-func checkFunc(pass *analysis.Pass, fn *ssa.Function) bool {
+// using panicArgs type, and records a human-readable contract for the
+// argument in contracts and the operation which panics in reasons.
+func checkFunc(pass *analysis.Pass, fn *ssa.Function, contracts, reasons map[token.Pos]string) bool {
 	fact := panicArgs{}
+	conds := conditionalArgs{}
 	for i, fp := range fn.Params {
 		// If the argument fp can't be nil or there are no referrers
 		// of fp in fn, skip check.
-		if !isNillable(fp.Type()) {
+		if !isNillable(fp.Type()) && !isFunc(fp.Type()) && !isChan(fp.Type()) {
 			continue
 		}
 		if fp.Referrers() == nil {
 			continue
 		}
+		addFact := func(instr ssa.Instruction, what string) {
+			fact[i] = struct{}{}
+			contracts[fp.Pos()] = contract(pass, instr, what)
+			reasons[fp.Pos()] = what
+		}
+		// conditional records the condition on the other parameters
+		// under which instr panics, if any, and reports whether it did.
+		conditional := func(instr ssa.Instruction) bool {
+			cond, ok := panicCondition(fn, instr.Block())
+			if !ok || conds.complements(i, cond) {
+				return false
+			}
+			// fp is never nil under a condition on its length
+			// which doesn't hold for 0.
+			if !cond.Len || cond.Param != i || cond.holds(0) {
+				conds[i] = append(conds[i], cond)
+			}
+			return true
+		}
 
+		if instr, what := closureUse(pass, fp); instr != nil {
+			addFact(instr, what)
+			continue
+		}
+
+		// Check all the referrers of fp and of the values derived from
+		// it, and if the instruction cause panic when fp is nil, add
+		// fact of it and break this loop.
+		vals := derivedValues(fp)
+		guarded := func(instr ssa.Instruction) bool {
+			return anyNilChecked(pass, vals, instr) || recovered(fn, instr)
+		}
 	refLoop:
-		// Check all the referrers and if the instruction cause panic when
-		// fp is nil, add fact of it and break this loop.
-		for _, fpr := range *fp.Referrers() {
-			start := big.NewInt(0)
-			switch instr := fpr.(type) {
-			case ssa.CallInstruction:
-				if !instr.Common().IsInvoke() {
-					ffact := panicArgs{}
-					if instr.Common().StaticCallee() == nil || instr.Common().StaticCallee().Object() == nil {
+		for _, v := range vals {
+			for _, fpr := range *v.Referrers() {
+				switch instr := fpr.(type) {
+				case ssa.CallInstruction:
+					common := instr.Common()
+					for _, fi := range argIndices(common, v) {
+						if argSuppressed(pass, instr, fi) {
+							continue
+						}
+						if h := panickingHandler(pass, common, fi); h != nil && !guarded(instr) {
+							addFact(instr, "passed to "+h.Name())
+							break refLoop
+						}
+						if e := elementPanics(pass, common, fi); e != nil && !guarded(instr) {
+							addFact(instr, "passed to "+e.Name())
+							break refLoop
+						}
+					}
+					// Closing a nil channel panics.
+					if b, ok := common.Value.(*ssa.Builtin); ok && b.Name() == "close" && common.Args[0] == v && !guarded(instr) {
+						if conditional(instr) {
+							continue
+						}
+						addFact(instr, "closed")
+						break refLoop
+					}
+					// Calling a method of a nil interface always panics,
+					// and so does calling a nil function.
+					if common.Value == v && !guarded(instr) {
+						if conditional(instr) {
+							continue
+						}
+						if common.IsInvoke() {
+							addFact(instr, "the receiver of "+common.Method.Name())
+						} else {
+							addFact(instr, "called")
+						}
+						break refLoop
+					}
+					if m, _ := boundCallee(common); m != nil && factObject(m) != nil {
+						// a call of a method value, whose closure takes
+						// the arguments of the method after the receiver
+						mfact := panicArgs{}
+						if !importPanicArgs(pass, factObject(m), &mfact) {
+							continue
+						}
+						for _, fi := range argIndices(common, v) {
+							if _, ok := mfact[fi+1]; ok && !argSuppressed(pass, instr, fi) && !guarded(instr) {
+								addFact(instr, "passed to "+m.Name())
+								break refLoop
+							}
+						}
+						continue
+					}
+					if f := calledField(common); f != nil {
+						// a call of a struct field with the functions
+						// assigned to it known
+						ffact := panicArgs{}
+						if !pass.ImportObjectFact(f, &ffact) {
+							continue
+						}
+						for _, fi := range argIndices(common, v) {
+							if _, ok := ffact[fi]; ok && !argSuppressed(pass, instr, fi) && !guarded(instr) {
+								addFact(instr, "passed to "+f.Name())
+								break refLoop
+							}
+						}
+						continue
+					}
+					if common.IsInvoke() || common.StaticCallee() == nil {
 						// a builtin or dynamically dispatched function call
 						continue
 					}
-					f := instr.Common().StaticCallee().Object()
-					if f.Pkg() != pass.Pkg {
-						if !pass.ImportPackageFact(f.Pkg(), &pkgDone{}) {
-							// not changed but can change later
-							return true
+					s := common.StaticCallee()
+					f := factObject(s)
+					if f != nil && f.Pkg() != pass.Pkg && !pass.ImportPackageFact(f.Pkg(), &pkgDone{}) && manifestOf(pass, f) == nil {
+						// The dependency wasn't analyzed, and its facts
+						// won't appear while this package is.
+						noteMissingDep(pass, f.Pkg(), instr.Pos())
+						continue
+					}
+					ffact := panicArgs{}
+					if !calleePanicArgs(pass, s, &ffact) {
+						continue
+					}
+					what := "passed to a function literal"
+					if f != nil {
+						what = "passed to " + f.Name()
+					}
+					// fp can be passed as any argument of the callee, so
+					// map the callee's indices to fp by position.
+					for _, fi := range argIndices(common, v) {
+						if argSuppressed(pass, instr, fi) {
+							continue
 						}
-						if pass.ImportObjectFact(f, &ffact) {
-							for fi := range ffact {
-
-								if i >= len(instr.Common().Args) {
-									continue
-								}
-
-								if instr.Common().Args[fi] == fp && !isNilChecked(fp, instr.Block(), start) {
-									fact[i] = struct{}{}
+						if _, ok := ffact[fi]; ok && (f == nil || !isNilSafeRecv(pass, f, fi)) && !guarded(instr) {
+							addFact(instr, what)
+							break refLoop
+						}
+					}
+				default:
+					// fp can be one of the variadic arguments of a
+					// call, which are stored into a slice for it.
+					if st, ok := instr.(*ssa.Store); ok && st.Val == v {
+						if c, k := varargCall(st); c != nil && !guarded(c) {
+							if s := varargPanics(pass, c, k); s != nil {
+								addFact(c, "passed to "+s.Name())
+								break refLoop
+							}
+						}
+					}
+					// fp can be the receiver bound to a method value,
+					// which dereferences it when the value is called.
+					if mc, ok := instr.(*ssa.MakeClosure); ok && len(mc.Bindings) == 1 && mc.Bindings[0] == v {
+						if m := boundFunc(mc); m != nil && factObject(m) != nil {
+							mfact := panicArgs{}
+							if c := boundCall(pass, mc); c != nil && importPanicArgs(pass, factObject(m), &mfact) && !guarded(c) {
+								if _, ok := mfact[0]; ok && !isNilSafeRecv(pass, factObject(m), 0) {
+									addFact(c, "the receiver of "+m.Name())
 									break refLoop
 								}
 							}
 						}
 					}
-					if pass.ImportObjectFact(f, &ffact) {
-						for fi := range ffact {
-
-							if i >= len(instr.Common().Args) {
-								continue
-							}
-
-							if instr.Common().Args[fi] == fp && !isNilChecked(fp, instr.Block(), start) {
-								fact[i] = struct{}{}
-								break refLoop
-							}
+					if x, what := dereference(instr); x == v && !guarded(instr) {
+						if conditional(instr) {
+							continue
 						}
+						addFact(instr, what)
+						break refLoop
 					}
 				}
-			case *ssa.FieldAddr:
-				// the address of fp.field
-				if instr.X == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
-				}
-			case *ssa.Field:
-				// fp.field
-				if instr.X == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
+			}
+		}
+	}
+	if preconditions {
+		for i, pn := range entryGuards(fn) {
+			fact[i] = struct{}{}
+			contracts[fn.Params[i].Pos()] = contract(pass, pn, "checked with panic")
+			reasons[fn.Params[i].Pos()] = "checked with panic"
+		}
+	}
+	for i := range fact {
+		delete(conds, i)
+	}
+	if len(conds) > 0 && factObject(fn) != nil {
+		pass.ExportObjectFact(factObject(fn), &conds)
+	}
+	if isAnon(fn) {
+		return setAnonFact(pass, fn, fact)
+	}
+	// If no argument cause panic, skip exporting the fact.
+	if len(fact) > 0 && factObject(fn) != nil {
+		// A new fact changes as much as a grown one, as the callers
+		// checked before fn in the round haven't seen it.
+		var oldFact panicArgs
+		if !pass.ImportObjectFact(factObject(fn), &oldFact) || !reflect.DeepEqual(oldFact, fact) {
+			pass.ExportObjectFact(factObject(fn), &fact)
+			return true
+		}
+	}
+	return false
+}
+
+// closureUse returns the instruction of a closure created in the
+// function of fp which panics when the captured parameter fp is nil,
+// and describes it. Only closures returned or called by the function
+// are considered, as in
+//
+//	func A(p *T) func() { return func() { use(p) } }
+func closureUse(pass *analysis.Pass, fp *ssa.Parameter) (ssa.Instruction, string) {
+	for _, r := range *fp.Referrers() {
+		st, ok := r.(*ssa.Store)
+		if !ok || st.Val != fp {
+			continue
+		}
+		alloc, ok := st.Addr.(*ssa.Alloc)
+		if !ok || alloc.Referrers() == nil {
+			continue
+		}
+		for _, ar := range *alloc.Referrers() {
+			mc, ok := ar.(*ssa.MakeClosure)
+			if !ok || !isReturnedOrCalled(mc) {
+				continue
+			}
+			for k, bv := range mc.Bindings {
+				if bv != alloc {
+					continue
 				}
-			case *ssa.IndexAddr:
-				// fp[i]
-				if instr.X == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
+				if instr, what := freeVarUse(pass, mc.Fn.(*ssa.Function).FreeVars[k]); instr != nil {
+					return instr, what + " in a closure"
 				}
-			case *ssa.TypeAssert:
-				// Only the 1-result type assertion panics.
-				//
-				// _ = fp.(someType)
-				if instr.X == fp && !instr.CommaOk && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
+			}
+		}
+	}
+	return nil, ""
+}
+
+// freeVarUse returns the instruction which panics when the variable
+// captured as fv is nil, and describes it.
+func freeVarUse(pass *analysis.Pass, fv *ssa.FreeVar) (ssa.Instruction, string) {
+	if fv.Referrers() == nil {
+		return nil, ""
+	}
+	for _, fr := range *fv.Referrers() {
+		load, ok := fr.(*ssa.UnOp)
+		if !ok || load.Op != token.MUL || load.Referrers() == nil {
+			continue
+		}
+		for _, lr := range *load.Referrers() {
+			if what := panicReason(pass, lr, load); what != "" && !isNilChecked(load, lr.Block()) {
+				return lr, what
+			}
+		}
+	}
+	return nil, ""
+}
+
+// isReturnedOrCalled reports whether the closure mc is returned or
+// called by the function creating it.
+func isReturnedOrCalled(mc *ssa.MakeClosure) bool {
+	if mc.Referrers() == nil {
+		return false
+	}
+	for _, r := range *mc.Referrers() {
+		switch r := r.(type) {
+		case *ssa.Return:
+			return true
+		case *ssa.Call:
+			if r.Call.Value == mc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// derivedValues returns fp and the values of its function which are fp
+// under another type or another name: the conversions of fp, such as
+// (*U)(unsafe.Pointer(p)) or an interface p converted to another
+// interface, and the phis into which they flow on an edge where they
+// aren't checked against nil, as p in
+//
+//	if cond {
+//		p = &T{}
+//	}
+//	p.x
+//
+// The phis of an edge where the value is checked, as in
+//
+//	if p == nil {
+//		p = &T{}
+//	}
+//
+// are left out. Local copies such as q := p are fp itself in SSA form,
+// and the loads of fp from its variable when its address is taken are
+// included as addrAliases finds them.
+func derivedValues(fp *ssa.Parameter) []ssa.Value {
+	vals := []ssa.Value{fp}
+	seen := map[ssa.Value]bool{fp: true}
+	add := func(v ssa.Value) {
+		if !seen[v] {
+			seen[v] = true
+			vals = append(vals, v)
+		}
+	}
+	for k := 0; k < len(vals); k++ {
+		v := vals[k]
+		if v.Referrers() == nil {
+			continue
+		}
+		for _, r := range *v.Referrers() {
+			switch r := r.(type) {
+			case *ssa.ChangeType, *ssa.ChangeInterface:
+				add(r.(ssa.Value))
+			case *ssa.Convert:
+				if isNillable(r.Type()) || types.Identical(r.Type().Underlying(), types.Typ[types.UnsafePointer]) {
+					add(r)
 				}
-			case *ssa.Slice:
-				// Slice operation to a pointer fp cause nil pointer
-				// dereference iff fp is nil.
-				//
-				// fp[:]
-				if _, ok := instr.X.Type().Underlying().(*types.Pointer); ok && instr.X == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
+			case *ssa.Phi:
+				for j, e := range r.Edges {
+					if e == v && !edgeNilChecked(v, r.Block().Preds[j], r.Block()) {
+						add(r)
+						break
+					}
 				}
 			case *ssa.Store:
-				// *fp = v
-				if instr.Addr == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
-				}
-			case *ssa.MapUpdate:
-				// *fp[x] = y
-				if instr.Map == fp && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
-				}
-			case *ssa.UnOp:
-				// *fp
-				if instr.X == fp && instr.Op == token.MUL && !isNilChecked(fp, instr.Block(), start) {
-					fact[i] = struct{}{}
-					break refLoop
+				for _, l := range addrAliases(r, v) {
+					add(l)
 				}
 			}
 		}
 	}
-	// If no argument cause panic, skip exporting the fact.
-	if len(fact) > 0 && fn.Object() != nil {
-		var oldFact panicArgs
-		if pass.ImportObjectFact(fn.Object(), &oldFact) && !reflect.DeepEqual(oldFact, fact) {
-			pass.ExportObjectFact(fn.Object(), &fact)
+	return vals
+}
+
+// anyNilChecked reports whether any of vals is checked against nil before
+// the instruction instr as isNilChecked does, validated, or asserted not
+// to be nil.
+func anyNilChecked(pass *analysis.Pass, vals []ssa.Value, instr ssa.Instruction) bool {
+	for _, v := range vals {
+		if isNilChecked(v, instr.Block()) || argValidated(pass, v, instr.Block()) || assertedBefore(v, instr) {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeNilChecked reports whether v is checked not to be nil on the edge
+// from the block pred to the block succ.
+func edgeNilChecked(v ssa.Value, pred, succ *ssa.BasicBlock) bool {
+	return nonNilOnEdge(v, pred, succ, make(map[*ssa.Phi]bool))
+}
+
+// argIndices returns the indices of the arguments of the call which
+// are v. The receiver of a static method call is the argument 0.
+func argIndices(common *ssa.CallCommon, v ssa.Value) []int {
+	var idx []int
+	for i, arg := range common.Args {
+		if arg == v {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// dereference returns the operand of instr which causes panic when it
+// is nil, and describes the operation. It returns a nil value if instr
+// doesn't panic on a nil operand.
+func dereference(instr ssa.Instruction) (ssa.Value, string) {
+	switch instr := instr.(type) {
+	case *ssa.FieldAddr:
+		// the address of x.field
+		return instr.X, "dereferenced"
+	case *ssa.Field:
+		// x.field
+		return instr.X, "dereferenced"
+	case *ssa.IndexAddr:
+		// x[i]
+		return instr.X, "indexed"
+	case *ssa.TypeAssert:
+		// Only the 1-result type assertion panics.
+		//
+		// _ = x.(someType)
+		//
+		// The builder checks the receivers of method values of
+		// interfaces with assertions to their own types, which are
+		// described by the method values themselves.
+		if !instr.CommaOk && !(instr.Pos() == token.NoPos && types.Identical(instr.AssertedType, instr.X.Type())) {
+			return instr.X, "type asserted"
+		}
+	case *ssa.MakeClosure:
+		// Creating a method value of a nil interface panics before
+		// the method is called.
+		//
+		// f := x.Method
+		if len(instr.Bindings) == 1 && types.IsInterface(instr.Bindings[0].Type()) && strings.HasSuffix(instr.Fn.Name(), "$bound") {
+			return instr.Bindings[0], "bound to a method value"
+		}
+	case *ssa.Slice:
+		// Slice operation to a pointer x cause nil pointer
+		// dereference iff x is nil, whatever the bounds are, because
+		// x[:0] is (*x)[:0] by the spec.
+		//
+		// x[:]
+		//
+		// len(x), cap(x) and ranging over x with only the index don't
+		// dereference x, and are built as constants without referring
+		// to x.
+		if _, ok := instr.X.Type().Underlying().(*types.Pointer); ok {
+			return instr.X, "sliced"
+		}
+	case *ssa.Store:
+		// *x = v
+		return instr.Addr, "stored through"
+	case *ssa.MapUpdate:
+		// x[k] = v
+		return instr.Map, "written to"
+	case *ssa.UnOp:
+		// *x
+		if instr.Op == token.MUL {
+			return instr.X, "dereferenced"
+		}
+	}
+	return nil, ""
+}
+
+// contract describes why the argument referred by instr must not be nil.
+func contract(pass *analysis.Pass, instr ssa.Instruction, what string) string {
+	if !instr.Pos().IsValid() {
+		return "must not be nil: " + what
+	}
+	posn := pass.Fset.Position(instr.Pos())
+	return fmt.Sprintf("must not be nil: %s at %s:%d", what, filepath.Base(posn.Filename), posn.Line)
+}
+
+// checkRecv exports nilSafeRecv for fn if fn is a method whose body
+// begins with if x == nil { ... return } on its receiver x.
+func checkRecv(pass *analysis.Pass, fn *ssa.Function) {
+	if fn.Signature.Recv() == nil || factObject(fn) == nil || len(fn.Blocks) == 0 || len(fn.Params) == 0 {
+		return
+	}
+	recv := fn.Params[0]
+	if !isNillable(recv.Type()) {
+		return
+	}
+	binop, tsucc, _ := eq(fn.Blocks[0])
+	if binop == nil {
+		return
+	}
+	if !(isNil(binop.X) && binop.Y == recv || isNil(binop.Y) && binop.X == recv) {
+		return
+	}
+	if _, ok := tsucc.Instrs[len(tsucc.Instrs)-1].(*ssa.Return); ok {
+		pass.ExportObjectFact(factObject(fn), &nilSafeRecv{})
+	}
+}
+
+// checkReturns exports nilReturns for fn if fn can return nil, and
+// identityReturns if fn returns some of its parameters unchanged. It
+// reports whether nilReturns or nonNilOnSuccess changed, as they depend
+// on the facts of the functions fn returns the results of.
+func checkReturns(pass *analysis.Pass, fn *ssa.Function) bool {
+	if factObject(fn) == nil {
+		return false
+	}
+	fact := nilReturns{}
+	ident := identityReturns{}
+	var rets []*ssa.Return
+	for _, b := range fn.Blocks {
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+			rets = append(rets, ret)
+		}
+	}
+	for i := 0; len(rets) > 0 && i < len(rets[0].Results); i++ {
+		if k := returnedParam(fn, rets, i); k >= 0 {
+			// The nilness of the result is the nilness of the
+			// argument at each call site.
+			ident[i] = k
+			continue
+		}
+		if types.Implements(fn.Signature.Results().At(i).Type(), errorType) {
+			// Errors are nil on success, which callers check for
+			// rather than dereference.
+			continue
+		}
+		for _, ret := range rets {
+			v := ret.Results[i]
+			if isNil(v) {
+				fact[i] = struct{}{}
+			}
+			if p, ok := v.(*ssa.Parameter); ok && isNillable(p.Type()) && !isNilChecked(p, ret.Block()) {
+				fact[i] = struct{}{}
+			}
+			if _, ok := mayReturnNil(pass, v); ok && !isNilChecked(v, ret.Block()) {
+				fact[i] = struct{}{}
+			}
+		}
+	}
+	changed := false
+	if len(fact) > 0 {
+		var old nilReturns
+		changed = !pass.ImportObjectFact(factObject(fn), &old) || !reflect.DeepEqual(old, fact)
+		pass.ExportObjectFact(factObject(fn), &fact)
+	}
+	if len(ident) > 0 {
+		pass.ExportObjectFact(factObject(fn), &ident)
+	}
+	if corr := correlate(pass, rets); len(corr) > 0 {
+		pass.ExportObjectFact(factObject(fn), &corr)
+	}
+	if succ := successResults(pass, fn, rets); len(succ) > 0 {
+		var old nonNilOnSuccess
+		if !pass.ImportObjectFact(factObject(fn), &old) || !reflect.DeepEqual(old, succ) {
+			changed = true
+		}
+		pass.ExportObjectFact(factObject(fn), &succ)
+	}
+	return changed
+}
+
+// checkNilReturns checks the returns of fns again until their facts
+// don't change, so that the functions returning the results of the ones
+// declared after them can return nil, as wrap in
+//
+//	func wrap() *T { return find() }
+//
+//	func find() *T { return nil }
+func checkNilReturns(pass *analysis.Pass, fns []*ssa.Function) {
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range fns {
+			if checkReturns(pass, fn) {
+				changed = true
+			}
+		}
+	}
+}
+
+// successResults returns the indices of the nillable results which rets
+// return as non-nil wherever the error result can be nil.
+func successResults(pass *analysis.Pass, fn *ssa.Function, rets []*ssa.Return) nonNilOnSuccess {
+	res := fn.Signature.Results()
+	if len(rets) == 0 || res.Len() < 2 || !types.Identical(res.At(res.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		return nil
+	}
+	last := res.Len() - 1
+	succ := nonNilOnSuccess{}
+	for i := 0; i < last; i++ {
+		if !isNillable(res.At(i).Type()) {
+			continue
+		}
+		succ[i] = struct{}{}
+		for _, ret := range rets {
+			// An error which is non-nil here doesn't constrain the
+			// result.
+			if returnedNilness(pass, ret, ret.Results[last]) == isnonnil || isNilChecked(ret.Results[last], ret.Block()) || isNewError(ret.Results[last]) {
+				continue
+			}
+			if returnedNilness(pass, ret, ret.Results[i]) != isnonnil && !successAt(pass, ret, i) {
+				delete(succ, i)
+				break
+			}
+		}
+	}
+	return succ
+}
+
+// errorFuncs are the functions of the standard library which always
+// return new errors.
+var errorFuncs = map[string]bool{
+	"errors.New": true,
+	"fmt.Errorf": true,
+}
+
+// isNewError reports whether v is a result of a call of errorFuncs.
+func isNewError(v ssa.Value) bool {
+	c, ok := v.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	s := c.Call.StaticCallee()
+	return s != nil && errorFuncs[s.String()]
+}
+
+// successAt reports whether the i-th result of ret is a result of a call
+// which is non-nil when the error result of the call is nil, and either
+// the error is returned along with it or ret is only reached when the
+// error is nil, as in
+//
+//	f, err := open(name)
+//	if err != nil {
+//		return nil, err
+//	}
+//	return f, nil
+func successAt(pass *analysis.Pass, ret *ssa.Return, i int) bool {
+	v := ret.Results[i]
+	if isSuccessResult(pass, ret.Results[len(ret.Results)-1], v) {
+		return true
+	}
+	ev, ok := v.(*ssa.Extract)
+	if !ok || ev.Tuple.Referrers() == nil {
+		return false
+	}
+	for _, r := range *ev.Tuple.Referrers() {
+		if err, ok := r.(*ssa.Extract); ok && isSuccessResult(pass, err, v) && isNilAt(err, ret.Block()) {
 			return true
 		}
-		pass.ExportObjectFact(fn.Object(), &fact)
 	}
 	return false
 }
 
+// isNilAt reports whether the block b is dominated by a check of the
+// condition v == nil.
+func isNilAt(v ssa.Value, b *ssa.BasicBlock) bool {
+	return dominatingBranches(b, func(If *ssa.If, succ int) bool {
+		cmp, ok := If.Cond.(*ssa.BinOp)
+		if !ok || !(cmp.X == v && isNil(cmp.Y) || cmp.Y == v && isNil(cmp.X)) {
+			return false
+		}
+		return cmp.Op == token.EQL && succ == 0 || cmp.Op == token.NEQ && succ == 1
+	})
+}
+
+// correlate returns the pairs of the nillable results which rets return
+// as both nil or both non-nil.
+func correlate(pass *analysis.Pass, rets []*ssa.Return) correlatedReturns {
+	var corr correlatedReturns
+	if len(rets) == 0 {
+		return nil
+	}
+	n := len(rets[0].Results)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if !isNillable(rets[0].Results[i].Type()) || !isNillable(rets[0].Results[j].Type()) {
+				continue
+			}
+			ok := true
+			for _, ret := range rets {
+				ni := returnedNilness(pass, ret, ret.Results[i])
+				if ni == unknown || ni != returnedNilness(pass, ret, ret.Results[j]) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				corr = append(corr, [2]int{i, j})
+			}
+		}
+	}
+	return corr
+}
+
+// returnedNilness returns the nilness of the value v returned by ret.
+func returnedNilness(pass *analysis.Pass, ret *ssa.Return, v ssa.Value) nilness {
+	if p, ok := v.(*ssa.Parameter); ok && isNilChecked(p, ret.Block()) {
+		return isnonnil
+	}
+	return nilnessOf(pass, nil, v)
+}
+
+// returnedParam returns the index of the nillable parameter of fn which
+// all of rets return as the i-th result, or -1.
+func returnedParam(fn *ssa.Function, rets []*ssa.Return, i int) int {
+	p, ok := rets[0].Results[i].(*ssa.Parameter)
+	if !ok || !isNillable(p.Type()) {
+		return -1
+	}
+	for _, ret := range rets[1:] {
+		if ret.Results[i] != p {
+			return -1
+		}
+	}
+	for k, fp := range fn.Params {
+		if fp == p {
+			return k
+		}
+	}
+	return -1
+}
+
+// mayReturnNil reports whether v is a result of a static call which
+// can return nil there.
+func mayReturnNil(pass *analysis.Pass, v ssa.Value) (*ssa.Function, bool) {
+	i := 0
+	if e, ok := v.(*ssa.Extract); ok {
+		v, i = e.Tuple, e.Index
+	}
+	c, ok := v.(*ssa.Call)
+	if !ok {
+		return nil, false
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return nil, false
+	}
+	var fact nilReturns
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return nil, false
+	}
+	_, ok = fact[i]
+	return s, ok
+}
+
+// identityArg returns the callee and the argument if v is a result of
+// a static call returning the argument unchanged.
+func identityArg(pass *analysis.Pass, v ssa.Value) (*ssa.Function, ssa.Value) {
+	i := 0
+	if e, ok := v.(*ssa.Extract); ok {
+		v, i = e.Tuple, e.Index
+	}
+	c, ok := v.(*ssa.Call)
+	if !ok {
+		return nil, nil
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return nil, nil
+	}
+	var fact identityReturns
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return nil, nil
+	}
+	k, ok := fact[i]
+	if !ok || k >= len(c.Call.Args) {
+		return nil, nil
+	}
+	return s, c.Call.Args[k]
+}
+
+// isNilSafeRecv reports whether the i-th argument of a call to f is the
+// receiver of a method which handles nil receivers.
+func isNilSafeRecv(pass *analysis.Pass, f types.Object, i int) bool {
+	if i != 0 {
+		return false
+	}
+	if sig, ok := f.Type().(*types.Signature); !ok || sig.Recv() == nil {
+		return false
+	}
+	return pass.ImportObjectFact(f, new(nilSafeRecv))
+}
+
+// factObject returns the object which the facts of fn are keyed by, or
+// nil if fn has no such object.
+//
+// This is the single place to map functions sharing facts to one
+// object, e.g. instantiations of a generic function or method to their
+// generic origin so that their identical facts are stored once. The
+// SSA builder used by this analyzer predates type parameters and never
+// creates instantiations, but the objects of generic calls in the
+// packages type checked by newer versions of go/types are instances,
+// which origin maps.
+//
+// The synthetic wrappers of a method share its object too. The wrappers
+// of method sets, such as (*T).M wrapping T.M or methods promoted from
+// embedded fields, and the thunks of method expressions take the
+// receiver as the argument 0 like the method, so they use its facts.
+// The closures of bound method values such as t.M don't take the
+// receiver but bind it as a free variable, so their argument indices
+// don't match the facts and they have no object; boundCallee maps
+// their calls to the method instead, shifting the indices by the
+// receiver. The methods of imported packages are synthetic too, without
+// parameters until they are built, and keep their object.
+func factObject(fn *ssa.Function) types.Object {
+	if fn.Synthetic != "" && len(fn.FreeVars) > 0 {
+		return nil
+	}
+	if fn.Object() == nil {
+		return nil
+	}
+	return origin(fn.Object())
+}
+
 // isNillable returns true when the values of t can be nil
-// and cause nil pointer dereference.
+// and cause nil pointer dereference, or are of a kind of -nillable.
 func isNillable(t types.Type) bool {
+	if nillable, ok := typeParamNillable(t); ok {
+		return nillable
+	}
 	switch t.Underlying().(type) {
 	case *types.Slice,
 		*types.Interface,
@@ -213,42 +1161,129 @@ func isNillable(t types.Type) bool {
 		*types.Pointer:
 		return true
 	default:
-		return false
+		return nillableKind(t.Underlying())
 	}
 }
 
+// isFunc reports whether t is a function type, whose nil values panic
+// when they are called.
+func isFunc(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Signature)
+	return ok
+}
+
+// isChan reports whether t is a channel type. Sending to and receiving
+// from nil channels block, but closing them panics.
+func isChan(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Chan)
+	return ok
+}
+
 // isNilChecked reports whether block b is dominated by a check
-// of the condition v != nil.
-func isNilChecked(v *ssa.Parameter, b *ssa.BasicBlock, visited *big.Int) bool {
-	vis := big.NewInt(1)
-	vis.Lsh(vis, uint(b.Index))
-	if vis.Or(visited, vis) == visited {
-		return false
-	}
-	// We could be more precise with full dataflow
-	// analysis of control-flow joins.
-	bi := b.Idom()
-	if bi == nil {
-		return false
+// of the condition v != nil, that is, by the edge from the check to its
+// successor where v isn't nil. The edge only dominates the successor if
+// it's the sole incoming edge, as in
+//
+//	if p == nil {
+//		handleNil()
+//	} else {
+//		*p
+//	}
+//
+// while the join block of an if statement without else is reached from
+// both branches. Short-circuit conditions such as p != nil && q != nil
+// are checks of each operand, even when their value is stored first, and
+// len(m) > 0 is a check of a map or a slice m.
+func isNilChecked(v ssa.Value, b *ssa.BasicBlock) bool {
+	return nonNilAt(v, b, make(map[*ssa.Phi]bool))
+}
+
+// nonNilAt is isNilChecked with the phis of short-circuit conditions
+// already seen, which can't tell anything more in loops.
+func nonNilAt(v ssa.Value, b *ssa.BasicBlock, seen map[*ssa.Phi]bool) bool {
+	return dominatingBranches(b, func(If *ssa.If, succ int) bool {
+		return impliesNonNil(If.Cond, succ == 0, v, seen)
+	})
+}
+
+// nonNilOnEdge reports whether v isn't nil on the edge from the block
+// pred to the block succ, because the edge is the branch of a check or
+// pred is only reached when v isn't nil.
+func nonNilOnEdge(v ssa.Value, pred, succ *ssa.BasicBlock, seen map[*ssa.Phi]bool) bool {
+	if If, ok := pred.Instrs[len(pred.Instrs)-1].(*ssa.If); ok && pred.Succs[0] != pred.Succs[1] {
+		if impliesNonNil(If.Cond, succ == pred.Succs[0], v, seen) {
+			return true
+		}
 	}
-	var binop *ssa.BinOp
-	// IfInstruction is unique and last instruction if any in block.
-	if If, ok := bi.Instrs[len(bi.Instrs)-1].(*ssa.If); ok {
-		if binop, ok = If.Cond.(*ssa.BinOp); ok {
-			switch binop.Op {
-			case token.EQL:
-				if isNil(binop.X) && binop.Y == v || isNil(binop.Y) && binop.X == v {
-					return b == bi.Succs[1]
-				}
-			case token.NEQ:
-				if isNil(binop.X) && binop.Y == v || isNil(binop.Y) && binop.X == v {
-					return b == bi.Succs[0]
-				}
+	return nonNilAt(v, pred, seen)
+}
+
+// impliesNonNil reports whether the boolean cond being holds means that
+// v isn't nil. cond is a comparison of v with nil, a call of a function
+// of -nil-checks with v, its negation, or the phi into which a
+// short-circuit condition is compiled, as in
+//
+//	ok := p != nil && q != nil
+//
+// where ok is false from the block checking p and q != nil from the
+// block where p isn't nil.
+func impliesNonNil(cond ssa.Value, holds bool, v ssa.Value, seen map[*ssa.Phi]bool) bool {
+	switch cond := cond.(type) {
+	case *ssa.BinOp:
+		if x := positiveLen(cond, holds); x != nil {
+			return sameValue(x, v)
+		}
+		if !(isNil(cond.X) && sameValue(cond.Y, v) || isNil(cond.Y) && sameValue(cond.X, v)) {
+			return false
+		}
+		return cond.Op == token.EQL && !holds || cond.Op == token.NEQ && holds
+	case *ssa.UnOp:
+		return cond.Op == token.NOT && impliesNonNil(cond.X, !holds, v, seen)
+	case *ssa.Call:
+		c, nilOnTrue, ok := nilCheckCall(cond)
+		return ok && holds != nilOnTrue && hasArg(c.Common(), v)
+	case *ssa.Phi:
+		if seen[cond] {
+			return false
+		}
+		seen[cond] = true
+		for k, e := range cond.Edges {
+			if c, ok := e.(*ssa.Const); ok && c.Value != nil && constant.BoolVal(c.Value) != holds {
+				// cond can't hold through this edge.
+				continue
+			}
+			if !impliesNonNil(e, holds, v, seen) && !nonNilOnEdge(v, cond.Block().Preds[k], cond.Block(), seen) {
+				return false
 			}
 		}
+		return true
 	}
-	visited = vis
-	return isNilChecked(v, bi, visited)
+	return false
+}
+
+// panicDetail describes the operation which panics in s when its i-th
+// argument is nil, such as ": m is written to in set", for callees in
+// the package of the pass which recorded reasons. Reading from a nil map
+// doesn't panic, so maps are only reported when they are written to.
+// It returns "" for callees in other packages.
+func panicDetail(s *ssa.Function, i int, reasons map[token.Pos]string) string {
+	if i >= len(s.Params) {
+		return ""
+	}
+	what, ok := reasons[s.Params[i].Pos()]
+	if !ok || s.Params[i].Name() == "" {
+		return ""
+	}
+	return fmt.Sprintf(": %s is %s in %s", s.Params[i].Name(), what, s.Name())
+}
+
+// paramReason returns the operation which panics in s when its i-th
+// argument is nil, as recorded in reasons, or "".
+func paramReason(s *ssa.Function, i int, reasons map[token.Pos]string) string {
+	if i >= len(s.Params) {
+		return ""
+	}
+	return reasons[s.Params[i].Pos()]
 }
 
 // isNil returns true when the value is a constant nil.
@@ -257,7 +1292,7 @@ func isNil(value ssa.Value) bool {
 	return ok && v.IsNil()
 }
 
-func runFunc(pass *analysis.Pass, fn *ssa.Function) {
+func runFunc(pass *analysis.Pass, fn *ssa.Function, reasons map[token.Pos]string) {
 	seen := make([]bool, len(fn.Blocks))
 	var visit func(b *ssa.BasicBlock, stack []fact)
 	visit = func(b *ssa.BasicBlock, stack []fact) {
@@ -266,26 +1301,43 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 		}
 		seen[b.Index] = true
 
-		// Report calls that can cause panic.
+		// Report dereferences of call results which can be nil, and calls
+		// that can cause panic. A value which was dereferenced isn't nil
+		// in the rest of the block and the blocks it dominates, because
+		// the dereference would have panicked, and nothing after the
+		// dereference of a nil value is reached.
 		for _, instr := range b.Instrs {
-			if c, ok := instr.(*ssa.Call); ok {
-				s := c.Call.StaticCallee()
-				if s == nil || s.Object() == nil {
-					continue
+			if v, _ := dereference(instr); v != nil {
+				n := nilnessOf(pass, stack, v)
+				if s, ok := mayReturnNil(pass, v); ok && n != isnonnil {
+					_, what := dereference(instr)
+					reportIn(pass, fn, what, analysis.Diagnostic{Pos: instr.Pos(), Message: fmt.Sprintf("the result of %s can be nil", s.Name())})
+				} else if s, x := identityArg(pass, v); x != nil && n == isnil {
+					report(pass, instr.Pos(), "the result of %s is nil", s.Name())
 				}
-				var fact panicArgs
-				if pass.ImportObjectFact(s.Object(), &fact) {
-					for i := range fact {
-
-						if i >= len(c.Common().Args) {
-							continue
-						}
-
-						if nilnessOf(stack, c.Common().Args[i]) == isnil {
-							pass.Reportf(c.Pos(), "this call can cause panic")
-						}
-					}
+				if n == isnil {
+					return
 				}
+				stack = append(stack, fact{v, isnonnil})
+			}
+			if g, ok := instr.(*ssa.Go); ok {
+				checkLauncher(pass, g, stack)
+				checkCallArgs(pass, fn, g, stack, reasons)
+			}
+			if c, ok := instr.(*ssa.Call); ok {
+				checkFieldArgs(pass, c)
+				checkConditional(pass, c, stack)
+				checkOptionArgs(pass, c)
+				checkLauncher(pass, c, stack)
+				checkDispatch(pass, c, stack)
+				checkElementArgs(pass, c, stack)
+				checkStringerArgs(pass, c, stack)
+				checkBoundFieldCall(pass, c)
+				checkCallArgs(pass, fn, c, stack, reasons)
+				checkTypedNil(pass, c, stack)
+			}
+			if d, ok := instr.(*ssa.Defer); ok {
+				checkCallArgs(pass, fn, d, stack, reasons)
 			}
 		}
 
@@ -293,8 +1345,8 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 		// is degenerate, and push a nilness fact on the stack when
 		// visiting its true and false successor blocks.
 		if binop, tsucc, fsucc := eq(b); binop != nil {
-			xnil := nilnessOf(stack, binop.X)
-			ynil := nilnessOf(stack, binop.Y)
+			xnil := nilnessOf(pass, stack, binop.X)
+			ynil := nilnessOf(pass, stack, binop.Y)
 			if ynil != unknown && xnil != unknown && (xnil == isnil || ynil == isnil) {
 				// If tsucc's or fsucc's sole incoming edge is impossible,
 				// it is unreachable.  Prune traversal of it and
@@ -348,6 +1400,32 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 			}
 		}
 
+		// A map or a slice of positive length isn't nil.
+		if x, succ := lenGuard(b); x != nil {
+			for _, d := range b.Dominees() {
+				s := stack
+				if d == succ && len(d.Preds) == 1 {
+					s = append(s, fact{x, isnonnil})
+				}
+				visit(d, s)
+			}
+			return
+		}
+
+		// The value of a comma-ok expression is nil where it failed.
+		if v, tsucc, fsucc, onTrue, onFalse := commaOk(b); v != nil {
+			for _, d := range b.Dominees() {
+				s := stack
+				if d == tsucc && len(d.Preds) == 1 && onTrue != unknown {
+					s = append(s, fact{v, onTrue})
+				} else if d == fsucc && len(d.Preds) == 1 && onFalse != unknown {
+					s = append(s, fact{v, onFalse})
+				}
+				visit(d, s)
+			}
+			return
+		}
+
 		for _, d := range b.Dominees() {
 			visit(d, stack)
 		}
@@ -358,6 +1436,80 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 	}
 }
 
+// checkCallArgs reports the call c in fn if it calls a nil parameter or
+// passes nil where the callee panics on it. Deferred calls and the calls
+// of go statements are checked where they are made, because their
+// arguments are evaluated there, and a panicking goroutine crashes the
+// program.
+func checkCallArgs(pass *analysis.Pass, fn *ssa.Function, c ssa.CallInstruction, stack []fact, reasons map[token.Pos]string) {
+	checkFieldFuncCall(pass, c, stack)
+	if p, ok := c.Common().Value.(*ssa.Parameter); ok && nilnessOf(pass, stack, p) == isnil {
+		report(pass, c.Pos(), "this call can cause panic: %s is nil", p.Name())
+	}
+	// args are the arguments passed as the parameters of s, and shift is
+	// the number of them missing from the call itself.
+	s := c.Common().StaticCallee()
+	args, shift := c.Common().Args, 0
+	if m, recv := boundCallee(c.Common()); m != nil {
+		s, args, shift = m, append([]ssa.Value{recv}, args...), 1
+	}
+	if s == nil {
+		return
+	}
+	obj := factObject(s)
+	if obj != nil {
+		checkVarargs(pass, c, s, stack)
+	}
+	var fact panicArgs
+	if !calleePanicArgs(pass, s, &fact) {
+		return
+	}
+	for i := range fact {
+
+		if i < shift || i >= len(args) {
+			// The receiver of a method value was checked where it
+			// was bound.
+			continue
+		}
+
+		if obj != nil && isNilSafeRecv(pass, obj, i) {
+			continue
+		}
+
+		if argSuppressed(pass, c, i-shift) {
+			continue
+		}
+
+		n := nilnessOf(pass, stack, args[i])
+		if r, ok := mayReturnNil(pass, args[i]); ok && n == unknown && !isIntentional(pass, s, i) && !anyNilChecked(pass, []ssa.Value{args[i]}, c) {
+			reportIn(pass, fn, paramReason(s, i, reasons), analysis.Diagnostic{
+				Pos:     c.Pos(),
+				Message: fmt.Sprintf("this call can cause panic: the result of %s can be nil%s", r.Name(), panicDetail(s, i, reasons)),
+			})
+			continue
+		}
+		if n == isnil {
+			if isIntentional(pass, s, i) {
+				reportViolation(pass, c, s)
+			} else {
+				var fixes []analysis.SuggestedFix
+				if shift == 0 && obj != nil {
+					fixes = callFixes(pass, c, s, i)
+				}
+				reportIn(pass, fn, paramReason(s, i, reasons), analysis.Diagnostic{
+					Pos:            c.Pos(),
+					Message:        "this call can cause panic" + panicDetail(s, i, reasons),
+					SuggestedFixes: fixes,
+				})
+			}
+		} else if isTestFunc(pass, fn) {
+			if tc := nilTestCase(args[i]); tc != "" {
+				report(pass, c.Pos(), "this call can cause panic: %s", tc)
+			}
+		}
+	}
+}
+
 // A fact records that a block is dominated
 // by the condition v == nil or v != nil.
 type fact struct {
@@ -380,8 +1532,18 @@ var nilnessStrings = []string{"non-nil", "unknown", "nil"}
 func (n nilness) String() string { return nilnessStrings[n+1] }
 
 // nilnessOf reports whether v is definitely nil, definitely not nil,
-// or unknown given the dominating stack of facts.
-func nilnessOf(stack []fact, v ssa.Value) nilness {
+// or unknown given the dominating stack of facts. The calls of accessors
+// share the facts of the fields they return, and the correlated results
+// of a call share their facts. The results which are non-nil on success
+// are non-nil where the error of the call is nil.
+func nilnessOf(pass *analysis.Pass, stack []fact, v ssa.Value) nilness {
+	return nilnessIn(pass, stack, v, nil)
+}
+
+// nilnessIn is nilnessOf with the literals whose elements are being
+// checked by nonNilElem, which can't tell anything more about their own
+// elements stored into them, as in a[0], a[1] = a[1], a[0].
+func nilnessIn(pass *analysis.Pass, stack []fact, v ssa.Value, seen map[*ssa.Alloc]bool) nilness {
 	// Is value intrinsically nil or non-nil?
 	switch v := v.(type) {
 	case *ssa.Alloc,
@@ -404,15 +1566,93 @@ func nilnessOf(stack []fact, v ssa.Value) nilness {
 		}
 	}
 
+	// Is value an element of a literal of non-nil elements?
+	if nonNilElem(pass, v, seen) {
+		return isnonnil
+	}
+
+	// Is value a field of a local struct only ever set to nil?
+	if nilField(v) {
+		return isnil
+	}
+
+	// Is value an argument returned unchanged?
+	if _, x := identityArg(pass, v); x != nil {
+		if n := nilnessIn(pass, stack, x, seen); n != unknown {
+			return n
+		}
+	}
+
 	// Search dominating control-flow facts.
 	for _, f := range stack {
-		if f.value == v {
+		if sameAccess(pass, f.value, v) || isCorrelated(pass, f.value, v) {
 			return f.nilness
 		}
+		if f.nilness == isnil && isSuccessResult(pass, f.value, v) {
+			return isnonnil
+		}
 	}
 	return unknown
 }
 
+// isSuccessResult reports whether v is a result of a call which is
+// non-nil when the error result err of the same call is nil.
+func isSuccessResult(pass *analysis.Pass, err, v ssa.Value) bool {
+	ee, ok := err.(*ssa.Extract)
+	if !ok {
+		return false
+	}
+	ev, ok := v.(*ssa.Extract)
+	if !ok || ee.Tuple != ev.Tuple || ee.Index != ee.Tuple.Type().(*types.Tuple).Len()-1 {
+		return false
+	}
+	c, ok := ee.Tuple.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return false
+	}
+	var fact nonNilOnSuccess
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return false
+	}
+	_, ok = fact[ev.Index]
+	return ok
+}
+
+// isCorrelated reports whether a and b are results of the same call
+// which are either both nil or both non-nil.
+func isCorrelated(pass *analysis.Pass, a, b ssa.Value) bool {
+	ea, ok := a.(*ssa.Extract)
+	if !ok {
+		return false
+	}
+	eb, ok := b.(*ssa.Extract)
+	if !ok || ea.Tuple != eb.Tuple {
+		return false
+	}
+	c, ok := ea.Tuple.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return false
+	}
+	var fact correlatedReturns
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return false
+	}
+	for _, pair := range fact {
+		if pair == [2]int{ea.Index, eb.Index} || pair == [2]int{eb.Index, ea.Index} {
+			return true
+		}
+	}
+	return false
+}
+
 // If b ends with an equality comparison, eq returns the operation and
 // its true (equal) and false (not equal) successors.
 func eq(b *ssa.BasicBlock) (op *ssa.BinOp, tsucc, fsucc *ssa.BasicBlock) {
@@ -1,13 +1,1115 @@
 package nilarg_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	"github.com/Matts966/nilarg"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, nilarg.Analyzer, "a")
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "a")
+	wantPanicArgs(t, results, map[string][]int{
+		"f":    {1, 3},
+		"f2":   {0, 1, 2, 3},
+		"f3":   {0},
+		"f7":   {0},
+		"f8":   {0},
+		"f10":  {0},
+		"s.At": {0},
+		"f12":  {1},
+	})
+}
+
+// wantPanicArgs checks that the PanicArgs of the Result of the package
+// analyzed by analysistest.Run are want, which names methods T.M, so
+// that a test asserts the facts and the diagnostics checked by
+// analysistest and the Result of a single run together.
+func wantPanicArgs(t *testing.T, results []*analysistest.Result, want map[string][]int) {
+	t.Helper()
+	for _, r := range results {
+		res, ok := r.Result.(*nilarg.Result)
+		if !ok {
+			t.Errorf("Result = %v, want *nilarg.Result", r.Result)
+			continue
+		}
+		got := make(map[string][]int)
+		for f, idx := range res.PanicArgs {
+			got[qualifiedName(f)] = idx
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("PanicArgs of %s = %v, want %v", r.Pass.Pkg.Path(), got, want)
+		}
+	}
+}
+
+// qualifiedName returns the name of f, qualified by the receiver type
+// name for methods.
+func qualifiedName(f *types.Func) string {
+	recv := f.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return f.Name()
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name() + "." + f.Name()
+	}
+	return f.Name()
+}
+
+// setFlags sets the flags of the analyzer given as name=value and returns
+// a function which restores their values.
+func setFlags(t *testing.T, flags ...string) func() {
+	t.Helper()
+	var restore []func()
+	for _, f := range flags {
+		i := strings.Index(f, "=")
+		if i < 0 {
+			t.Fatalf("flag %q: want name=value", f)
+		}
+		name, value := f[:i], f[i+1:]
+		old := nilarg.Analyzer.Flags.Lookup(name).Value.String()
+		if err := nilarg.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		restore = append(restore, func() {
+			if err := nilarg.Analyzer.Flags.Set(name, old); err != nil {
+				t.Errorf("restoring -%s: %v", name, err)
+			}
+		})
+	}
+	return func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+	}
+}
+
+// TestFlags runs the packages of testdata whose expectations need only
+// the flags of the analyzer set.
+func TestFlags(t *testing.T) {
+	testdata := analysistest.TestData()
+	facts := filepath.Join(testdata, "facts", "std.json") + "," + filepath.Join(testdata, "facts", "annotations.json")
+	for _, test := range []struct {
+		name  string
+		flags []string
+		pkgs  []string
+	}{
+		{"Preconditions", []string{"preconditions=true"}, []string{"precondition"}},
+		{"Signatures", []string{"signatures=true"}, []string{"signature"}},
+		{"ExportedCallers", []string{"exported-callers=true"}, []string{"library"}},
+		{"PanicMessages", []string{"panic-messages=true"}, []string{"message"}},
+		{"Run", []string{"run=Selected$"}, []string{"filter"}},
+		{"Channels", []string{"channels=true"}, []string{"channel"}},
+		{"Stringers", []string{"stringers=true"}, []string{"stringer", "printer"}},
+		{"CombineGrade", []string{"combine=true", "grade=true"}, []string{"grade"}},
+		{"Symbol", []string{"func=scope.Run"}, []string{"scope"}},
+		{"ContractsOnly", []string{"contracts=true"}, []string{"contracts"}},
+		{"GuardStyle", []string{"guard-style=true"}, []string{"guardstyle"}},
+		{"FactFiles", []string{"facts=" + facts}, []string{"factsuse"}},
+		{"NilCheckHelpers", []string{"nil-checks=valid,isEmpty=nil,nilhelper.mustHave,*.NonNil"}, []string{"nilhelper"}},
+		{"LargeFacts", []string{"guards=true"}, []string{"generated"}},
+		{"FuncFields", []string{"func-fields=true"}, []string{"funcfield"}},
+		{"TrustRecover", []string{"trust-recover=true"}, []string{"recovered"}},
+		{"NoReturn", []string{"no-return=die"}, []string{"noreturn"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer setFlags(t, test.flags...)()
+			analysistest.Run(t, testdata, nilarg.Analyzer, test.pkgs...)
+		})
+	}
+}
+
+func TestFactsOnly(t *testing.T) {
+	defer setFlags(t, "factsonly=true")()
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "provider")
+	wantPanicArgs(t, results, map[string][]int{"deref": {0}})
+}
+
+func TestContracts(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "hover")
+	for _, r := range results {
+		res := r.Result.(*nilarg.Result)
+		got := make(map[string]string)
+		for pos, c := range res.Contracts {
+			posn := r.Pass.Fset.Position(pos)
+			got[filepath.Base(posn.Filename)+":"+strconv.Itoa(posn.Line)] = c
+		}
+		want := map[string]string{
+			"hover.go:3":  "must not be nil: dereferenced at hover.go:4",
+			"hover.go:7":  "must not be nil: written to at hover.go:8",
+			"hover.go:11": "must not be nil: passed to deref at hover.go:12",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Contracts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNilSafeRecv(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "recv")
+}
+
+func TestNilReturns(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "ret")
+}
+
+func TestPanicFields(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "fields")
+}
+
+func TestOnce(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "once")
+}
+
+func TestRemap(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "remap")
+}
+
+func TestClosure(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "closure")
+	for _, r := range results {
+		res := r.Result.(*nilarg.Result)
+		for f := range res.PanicArgs {
+			if f.Name() != "A" {
+				continue
+			}
+			c := res.Contracts[f.Type().(*types.Signature).Params().At(0).Pos()]
+			if want := "must not be nil: passed to use in a closure at closure.go:11"; c != want {
+				t.Errorf("contract of A = %q, want %q", c, want)
+			}
+		}
+	}
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	for _, tt := range []struct {
+		alternatives string
+		want         map[int][]string
+	}{
+		{"false", map[int][]string{
+			14: {"Pass &T{} instead"},
+			19: {"Call only if p is not nil"},
+			26: {"Call only if m is not nil"},
+		}},
+		{"true", map[int][]string{
+			14: {"Pass &T{} instead", "Return early from deref if p is nil"},
+			19: {"Call only if p is not nil", "Return an error if p is nil", "Pass &T{} instead", "Return early from deref if p is nil"},
+			26: {"Call only if m is not nil", "Pass make(map[string]int) instead", "Return early from set if m is nil"},
+		}},
+	} {
+		restore := setFlags(t, "fix-alternatives="+tt.alternatives)
+		testdata := analysistest.TestData()
+		results := analysistest.Run(t, testdata, nilarg.Analyzer, "fix")
+		restore()
+		for _, r := range results {
+			got := make(map[int][]string)
+			for _, d := range r.Diagnostics {
+				line := r.Pass.Fset.Position(d.Pos).Line
+				for _, f := range d.SuggestedFixes {
+					got[line] = append(got[line], f.Message)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fix-alternatives=%s: suggested fixes = %v, want %v", tt.alternatives, got, tt.want)
+			}
+			for _, d := range r.Diagnostics {
+				fixes := d.SuggestedFixes
+				edit := fixes[len(fixes)-1].TextEdits[0]
+				if tt.alternatives == "true" && r.Pass.Fset.Position(d.Pos).Line == 14 && string(edit.NewText) != "\nif p == nil {\nreturn 0\n}" {
+					t.Errorf("early return = %q, want the zero value of int", edit.NewText)
+				}
+			}
+		}
+	}
+}
+
+func TestGuards(t *testing.T) {
+	defer setFlags(t, "guards=true")()
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "guard")
+	for _, r := range results {
+		var got []string
+		for _, d := range r.Diagnostics {
+			for _, f := range d.SuggestedFixes {
+				for _, e := range f.TextEdits {
+					got = append(got, string(e.NewText))
+				}
+			}
+		}
+		want := []string{
+			"\nif p == nil {\npanic(\"p must not be nil\")\n}",
+			"\nif p == nil {\nreturn 0, fmt.Errorf(\"p must not be nil\")\n}\nif m == nil {\nreturn 0, fmt.Errorf(\"m must not be nil\")\n}",
+			"\nimport \"fmt\"\n",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("guard edits = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestLaunchers(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "launch")
+}
+
+// silent ignores the errors of analysistest runs with partial results.
+type silent struct{}
+
+func (silent) Errorf(format string, args ...interface{}) {}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nilarg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "closure.json")
+
+	testdata := analysistest.TestData()
+	defer setFlags(t, "checkpoint="+dir, "fixpoint-timeout=1ns")()
+	analysistest.Run(silent{}, testdata, nilarg.Analyzer, "closure")
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("checkpoint was not saved: %v", err)
+	}
+
+	// The next run resumes from the checkpoint and converges.
+	if err := nilarg.Analyzer.Flags.Set("fixpoint-timeout", "0"); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, testdata, nilarg.Analyzer, "closure")
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("checkpoint was not removed: %v", err)
+	}
+}
+
+func TestReleaseSSA(t *testing.T) {
+	defer setFlags(t, "release-ssa=true")()
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "remap")
+	for _, r := range results {
+		for _, fn := range r.Pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA).SrcFuncs {
+			if fn.Blocks != nil {
+				t.Errorf("the body of %s was not released", fn)
+			}
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	defer setFlags(t, "grade=true")()
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "grade")
+	for _, r := range results {
+		g := r.Result.(*nilarg.Result).Grade
+		if g.Guarded != 1 || g.Unguarded != 1 || g.Contracts != 1 || g.Letter() != "C" {
+			t.Errorf("Grade = %+v (%s), want 1 guarded, 1 unguarded and 1 contract (C)", *g, g.Letter())
+		}
+	}
+}
+
+func TestAccessor(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "accessor")
+}
+
+func TestBuildConstraints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "build")
+}
+
+func TestGuardChains(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "chain")
+}
+
+func TestConstructors(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "ctor")
+}
+
+func TestStaleCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nilarg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A checkpoint saved for other sources claims that D panics.
+	stale := []byte(`{"Key":"stale","Facts":{"closure.D":[0]}}`)
+	if err := ioutil.WriteFile(filepath.Join(dir, "closure.json"), stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	testdata := analysistest.TestData()
+	defer setFlags(t, "checkpoint="+dir)()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "closure")
+}
+
+func TestConditionalArgs(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "cond")
+}
+
+func TestOptions(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "option")
+}
+
+// sourceOnly ignores the package fact of the test main generated outside
+// testdata for packages with tests.
+type sourceOnly struct{ t *testing.T }
+
+func (s sourceOnly) Errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if filepath.IsAbs(msg) && !strings.HasPrefix(msg, analysistest.TestData()) && strings.HasSuffix(msg, ":1:1: unexpected fact: &{}") {
+		return
+	}
+	s.t.Error(msg)
+}
+
+func TestTableDrivenTests(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(sourceOnly{t}, testdata, nilarg.Analyzer, "tcase")
+}
+
+func TestFingerprints(t *testing.T) {
+	defer setFlags(t, "fingerprints=true")()
+	categories := func(testdata string) []string {
+		var got []string
+		for _, r := range analysistest.Run(t, testdata, nilarg.Analyzer, "fingerprint") {
+			for _, d := range r.Diagnostics {
+				got = append(got, d.Category)
+			}
+		}
+		return got
+	}
+	testdata := analysistest.TestData()
+	got := categories(testdata)
+	if len(got) != 1 || len(got[0]) != 16 {
+		t.Fatalf("fingerprints = %q, want one fingerprint", got)
+	}
+	if shifted := categories(filepath.Join(testdata, "shifted")); !reflect.DeepEqual(shifted, got) {
+		t.Errorf("fingerprints of moved findings = %q, want %q", shifted, got)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var got []nilarg.Progress
+	nilarg.OnProgress = func(p nilarg.Progress) { got = append(got, p) }
+	defer func() { nilarg.OnProgress = nil }()
+	analysistest.Run(t, analysistest.TestData(), nilarg.Analyzer, "fingerprint")
+	if len(got) != 3 {
+		t.Fatalf("progress = %+v, want start, finding and end", got)
+	}
+	start, end := got[0], got[len(got)-1]
+	if start.Package != "fingerprint" || start.Done {
+		t.Errorf("first progress = %+v, want the start of fingerprint", start)
+	}
+	if !end.Done || end.Packages != start.Packages+1 || end.Findings != start.Findings+1 {
+		t.Errorf("last progress = %+v, want one more package and finding than %+v", end, start)
+	}
+}
+
+func TestLoopVars(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), nilarg.Analyzer, "loopvar", "loopvar122")
+}
+
+func TestEmbeddedInterfaces(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), nilarg.Analyzer, "embedded")
+}
+
+// errorLog records the errors instead of failing the test.
+type errorLog []string
+
+func (l *errorLog) Errorf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+
+func TestReanalysis(t *testing.T) {
+	testdata := analysistest.TestData()
+	prev := analysistest.Run(t, testdata, nilarg.Analyzer, "reanalyze")[0].Result.(*nilarg.Result)
+	var changed []string
+	for file := range prev.Files {
+		if filepath.Base(file) == "a.go" {
+			changed = append(changed, file)
+		}
+	}
+	nilarg.Reanalyze = nilarg.NewReanalysis("reanalyze", prev, changed)
+	defer func() { nilarg.Reanalyze = nil }()
+	want := map[string]bool{"get": true, "viaGet": true, "callsViaGet": true}
+	if !reflect.DeepEqual(nilarg.Reanalyze.Funcs, want) {
+		t.Errorf("re-analyzed functions = %v, want %v", nilarg.Reanalyze.Funcs, want)
+	}
+
+	// The diagnostic in callsUnrelated isn't reported again.
+	var log errorLog
+	next := analysistest.Run(&log, testdata, nilarg.Analyzer, "reanalyze")[0].Result.(*nilarg.Result)
+	if len(log) != 1 || !strings.Contains(log[0], "b.go:16: no diagnostic") {
+		t.Errorf("errors of re-analysis = %q, want the missing diagnostic of callsUnrelated", log)
+	}
+	if len(next.Findings) != 1 || next.Findings[0].Func != "callsViaGet" {
+		t.Errorf("findings of re-analysis = %+v, want the one in callsViaGet", next.Findings)
+	}
+	if merged := nilarg.Reanalyze.Merge(prev, next); len(merged) != len(prev.Findings) {
+		t.Errorf("merged findings = %+v, want %+v", merged, prev.Findings)
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "suppress")
+
+	restore := setFlags(t, "fingerprints=true")
+	fp := analysistest.Run(t, testdata, nilarg.Analyzer, "fingerprint")[0].Diagnostics[0].Category
+	restore()
+
+	dir, err := ioutil.TempDir("", "nilarg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setFlags(t, "baseline=")()
+	for i, test := range []struct {
+		entry  string
+		errors int
+	}{
+		{fp, 1},
+		{fp + " until=2999-01-01", 1},
+		{fp + " until=2000-01-01", 0},
+	} {
+		baseline := filepath.Join(dir, strconv.Itoa(i))
+		if err := ioutil.WriteFile(baseline, []byte("# accepted findings\n"+test.entry+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := nilarg.Analyzer.Flags.Set("baseline", baseline); err != nil {
+			t.Fatal(err)
+		}
+		// The finding suppressed by the baseline is missing.
+		var log errorLog
+		analysistest.Run(&log, testdata, nilarg.Analyzer, "fingerprint")
+		if len(log) != test.errors {
+			t.Errorf("errors with baseline %q = %q, want %d", test.entry, log, test.errors)
+		}
+	}
+
+	// A missing or malformed baseline fails the analysis.
+	malformed := filepath.Join(dir, "malformed")
+	if err := ioutil.WriteFile(malformed, []byte(fp+" until=soon\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ baseline, err string }{
+		{filepath.Join(dir, "missing"), "no such file or directory"},
+		{malformed, "malformed:1: until=soon: want until=YYYY-MM-DD"},
+	} {
+		if err := nilarg.Analyzer.Flags.Set("baseline", test.baseline); err != nil {
+			t.Fatal(err)
+		}
+		var log errorLog
+		analysistest.Run(&log, testdata, nilarg.Analyzer, "fingerprint")
+		if !strings.Contains(strings.Join(log, "\n"), test.err) {
+			t.Errorf("errors with baseline %s = %q, want %q", test.baseline, log, test.err)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	defer setFlags(t, "histogram=true")()
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "histogram")
+	for _, r := range results {
+		h := r.Result.(*nilarg.Result).Histogram
+		if c := h["map write"]; c == nil || c.Guarded != 1 || c.Unguarded != 1 {
+			t.Errorf("map writes = %+v, want 1 guarded and 1 unguarded", c)
+		}
+	}
+}
+
+func TestRegistries(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "registry")
+}
+
+func TestFuncSlices(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "funcslice")
+}
+
+func TestMethodWrappers(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "wrapper")
+}
+
+func TestValidators(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "validator")
+}
+
+func TestMaps(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "readonly")
+}
+
+func TestCombine(t *testing.T) {
+	defer setFlags(t, "combine=true")()
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "combined")
+	res := results[0].Result.(*nilarg.Result)
+	if len(res.Findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(res.Findings))
+	}
+	if !res.Covers(res.Findings[0].Posn) {
+		t.Errorf("the result doesn't cover its finding at %v", res.Findings[0].Posn)
+	}
+}
+
+func TestManifests(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "manifestuse")
+
+	dir, err := ioutil.TempDir("", "nilarg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "src", "lib")
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := `package lib // want package:"&{}"
+
+type T struct{ x int }
+
+func (t *T) X() int { return t.x } // want X:"&map\\[0:{}\\]"
+
+func get(t *T) int { return t.x } // want get:"&map\\[0:{}\\]"
+`
+	if err := ioutil.WriteFile(filepath.Join(pkg, "lib.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer setFlags(t, "write-manifest=true")()
+	analysistest.Run(t, dir, nilarg.Analyzer, "lib")
+	data, err := ioutil.ReadFile(filepath.Join(pkg, "nilarg.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m nilarg.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := []nilarg.ManifestContract{{Func: "T.X", Param: 0, Name: "t", Contract: "must not be nil: dereferenced at lib.go:5"}}
+	if m.Package != "lib" || !reflect.DeepEqual(m.Contracts, want) {
+		t.Errorf("got manifest %+v, want the contracts %+v of lib", m, want)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "invoke")
+}
+
+func TestTodos(t *testing.T) {
+	defer setFlags(t, "todos=true")()
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "todo")
+	for _, r := range results {
+		var got []string
+		for _, d := range r.Diagnostics {
+			for _, f := range d.SuggestedFixes {
+				for _, e := range f.TextEdits {
+					got = append(got, fmt.Sprintf("%d: %s", r.Pass.Fset.Position(e.Pos).Line, e.NewText))
+				}
+			}
+		}
+		want := []string{
+			"6: // TODO(nilarg): p may be nil; add check\n\t",
+			"7: // TODO(nilarg): p may be nil; add check\n\t",
+			"8: // TODO(nilarg): p may be nil; add check\n\t\t",
+			"6: // TODO(nilarg): m may be nil; add check\n\t",
+			"17: // TODO(nilarg): p may be nil; add check\n\t",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("todo edits = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "callback")
+}
+
+func TestNode(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, nilarg.Analyzer, "node")
+	for _, r := range results {
+		got := make(map[string]string)
+		for _, fn := range r.Pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA).SrcFuncs {
+			if fn.Name() != "f" {
+				continue
+			}
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					n, path := nilarg.Node(r.Pass.Files, instr)
+					kind := strings.TrimPrefix(fmt.Sprintf("%T", instr), "*ssa.")
+					if _, ok := got[kind]; ok {
+						continue
+					}
+					switch {
+					case n == nil:
+						got[kind] = "nil"
+					case n != path[0]:
+						t.Errorf("the path of %s begins with %T, want %T", instr, path[0], n)
+					default:
+						if x, ok := n.(ast.Expr); ok {
+							got[kind] = types.ExprString(x)
+						} else {
+							got[kind] = fmt.Sprintf("%T", n)
+						}
+					}
+				}
+			}
+		}
+		want := map[string]string{
+			"FieldAddr":  "p.x",
+			"UnOp":       "p.x",
+			"MapUpdate":  `m["k"]`,
+			"IndexAddr":  "s[0]",
+			"Lookup":     `m["k"]`,
+			"BinOp":      `s[0] + m["k"]`,
+			"TypeAssert": "i.(error)",
+			"Call":       "g(p)",
+			"Return":     "*ast.ReturnStmt",
+		}
+		for kind, node := range want {
+			if got[kind] != node {
+				t.Errorf("the node of %s is %q, want %q", kind, got[kind], node)
+			}
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "closer")
+}
+
+func TestBoundFields(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "hooks")
+}
+
+func TestPhis(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "phi")
+}
+
+func TestAliases(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "alias")
+}
+
+func TestArrayPointers(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "arrayptr")
+}
+
+func TestLoopGuards(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "loopguard")
+}
+
+func TestShortCircuit(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "shortcircuit")
+}
+
+func TestDB(t *testing.T) {
+	testdata := analysistest.TestData()
+	db, err := nilarg.LoadDB(filepath.Join(testdata, "facts", "std.json"), filepath.Join(testdata, "facts", "annotations.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := db.Packages(), []string{"factslib", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Packages() = %v; want %v", got, want)
+	}
+	if got := db.Lookup("factslib", "Get"); len(got) != 1 || got[0].Param != 0 {
+		t.Errorf(`Lookup("factslib", "Get") = %v; want the contract of p`, got)
+	}
+	// The annotations replace the contracts of Put.
+	if got := db.Lookup("factslib", "Put"); len(got) != 1 || got[0].Name != "v" {
+		t.Errorf(`Lookup("factslib", "Put") = %v; want the contract of v only`, got)
+	}
+	if got := db.Lookup("factslib", "Free"); len(got) != 0 {
+		t.Errorf(`Lookup("factslib", "Free") = %v; want none`, got)
+	}
+	if m := db.Manifest("factslib"); m == nil || len(m.Contracts) != 2 {
+		t.Errorf(`Manifest("factslib") = %v; want the contracts of Get and Put`, m)
+	}
+	if m := db.Manifest("missing"); m != nil {
+		t.Errorf(`Manifest("missing") = %v; want nil`, m)
+	}
+
+	// Later manifests take precedence also when merged directly.
+	db = nilarg.NewDB(db.Manifests()...)
+	db.Add(nilarg.Manifest{Package: "factslib", Contracts: []nilarg.ManifestContract{{Func: "Get", Param: 0, Name: "p", Contract: "overridden"}}})
+	if got := db.Lookup("factslib", "Get"); len(got) != 1 || got[0].Contract != "overridden" {
+		t.Errorf(`Lookup("factslib", "Get") = %v; want the overridden contract`, got)
+	}
+
+	if _, err := nilarg.LoadManifests(filepath.Join(testdata, "facts", "missing.json")); err == nil {
+		t.Error("LoadManifests of a missing file succeeded")
+	}
+}
+
+func TestLenGuards(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "lenguard")
+}
+
+func TestArgComments(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "argcomment")
+}
+
+func TestDereferenceGuards(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "derefguard")
+}
+
+func TestMissingFacts(t *testing.T) {
+	defer setFlags(t, "missing-facts=true")()
+	// Leave missinglib unanalyzed as a driver running the analyzer over
+	// some packages only would.
+	a := *nilarg.Analyzer
+	a.Run = func(pass *analysis.Pass) (interface{}, error) {
+		if pass.Pkg.Path() == "missinglib" {
+			return new(nilarg.Result), nil
+		}
+		return nilarg.Analyzer.Run(pass)
+	}
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, &a, "missinguse")
+}
+
+func TestMethodValues(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "methodvalue")
+}
+
+func TestValidationIdioms(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "validation")
+}
+
+func TestDeferredCalls(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "deferred")
+}
+
+// TestStream checks that streamed diagnostics are still reported to the
+// driver, which decides the exit status by them.
+func TestStream(t *testing.T) {
+	defer setFlags(t, "stream=true")()
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "fix")
+}
+
+func TestGoStatements(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "gostmt")
+}
+
+func TestDryRun(t *testing.T) {
+	defer setFlags(t, "guards=true", "dry-run=true")()
+	// The guards are inserted after the braces, before the comments, and
+	// formatting is left to gofmt as with -fix. The imports are added on
+	// their own lines, leaving the comments of the package clauses, such
+	// as import comments, where they are.
+	for _, tt := range []struct {
+		pkg, want string
+	}{
+		{"guard", `--- a/testdata/src/guard/guard.go
++++ b/testdata/src/guard/guard.go
+@@ -1,12 +1,23 @@
+ package guard // want package:"&{}"
+ 
++import "fmt"
++
+ type T struct{ x int }
+ 
+-func Get(p *T) int { // want Get:"&map\\[0:{}\\]" "exported function Get panics when p is nil"
++func Get(p *T) int {
++if p == nil {
++panic("p must not be nil")
++} // want Get:"&map\\[0:{}\\]" "exported function Get panics when p is nil"
+ 	return p.x
+ }
+ 
+-func Load(p *T, m map[string]int) (int, error) { // want Load:"&map\\[0:{} 1:{}\\]" "exported function Load panics when p or m is nil"
++func Load(p *T, m map[string]int) (int, error) {
++if p == nil {
++return 0, fmt.Errorf("p must not be nil")
++}
++if m == nil {
++return 0, fmt.Errorf("m must not be nil")
++} // want Load:"&map\\[0:{} 1:{}\\]" "exported function Load panics when p or m is nil"
+ 	m["x"] = p.x
+ 	return 0, nil
+ }
+`},
+		{"guardimport", `--- a/testdata/src/guardimport/guardimport.go
++++ b/testdata/src/guardimport/guardimport.go
+@@ -1,7 +1,12 @@
+ // want package:"&{}"
+ package guardimport // import "guardimport"
+ 
+-func Parse(m map[string]int) error { // want Parse:"&map\\[0:{}\\]" "exported function Parse panics when m is nil"
++import "fmt"
++
++func Parse(m map[string]int) error {
++if m == nil {
++return fmt.Errorf("m must not be nil")
++} // want Parse:"&map\\[0:{}\\]" "exported function Parse panics when m is nil"
+ 	m["x"] = 1
+ 	return nil
+ }
+`},
+	} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		testdata := analysistest.TestData()
+		analysistest.Run(t, testdata, nilarg.Analyzer, tt.pkg)
+		os.Stdout = stdout
+		w.Close()
+		out, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tt.want {
+			t.Errorf("dry run of %s =\n%s\nwant\n%s", tt.pkg, out, tt.want)
+		}
+	}
+}
+
+func TestVariadicArgs(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "variadic", "varorder")
+}
+
+func TestAddrTaken(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "addrtaken")
+}
+
+func TestValidationPanics(t *testing.T) {
+	defer setFlags(t, "preconditions=true", "validation-panics=category")()
+	testdata := analysistest.TestData()
+	for _, r := range analysistest.Run(t, testdata, nilarg.Analyzer, "precondition") {
+		for _, d := range r.Diagnostics {
+			violation := strings.Contains(d.Message, "violates a precondition")
+			if violation != (d.Category == "nil-validation") {
+				t.Errorf("%q has the category %q", d.Message, d.Category)
+			}
+		}
+	}
+
+	if err := nilarg.Analyzer.Flags.Set("validation-panics", "off"); err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, r := range analysistest.Run(silent{}, testdata, nilarg.Analyzer, "precondition") {
+		for _, d := range r.Diagnostics {
+			msgs = append(msgs, d.Message)
+		}
+	}
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "can cause panic") {
+		t.Errorf("-validation-panics off reports %q; want only the dereference", msgs)
+	}
+}
+
+func TestTestify(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(sourceOnly{t}, testdata, nilarg.Analyzer, "testify")
+}
+
+func TestAnonFuncs(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "anon")
+}
+
+func TestBoundMethods(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "bound")
+}
+
+// TestNillableKinds checks that nil functions and channels returned or
+// stored in fields are only tracked when -nillable lists their kinds.
+func TestNillableKinds(t *testing.T) {
+	testdata := analysistest.TestData()
+	for _, r := range analysistest.Run(silent{}, testdata, nilarg.Analyzer, "nillablefunc") {
+		for _, d := range r.Diagnostics {
+			t.Errorf("%v: %s without -nillable", r.Pass.Fset.Position(d.Pos), d.Message)
+		}
+	}
+
+	defer setFlags(t, "nillable=chan,func,unsafe.Pointer")()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "nillable", "nillablefunc")
+
+	if err := nilarg.Analyzer.Flags.Set("nillable", "string"); err == nil {
+		t.Error("-nillable accepts string")
+	}
+}
+
+func TestTypedNil(t *testing.T) {
+	testdata := analysistest.TestData()
+	for _, r := range analysistest.Run(t, testdata, nilarg.Analyzer, "typednil", "typednillib", "typedniluse") {
+		for _, d := range r.Diagnostics {
+			if d.Category != "typed-nil" {
+				t.Errorf("%q has the category %q, want typed-nil", d.Message, d.Category)
+			}
+		}
+	}
+}
+
+// TestImportedMethods checks the calls of the methods of an imported
+// package, which the SSA builder only declares as synthetic functions.
+func TestImportedMethods(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "methoduse")
+}
+
+// TestConvergence bounds the rounds of the fixpoint over functions
+// calling each other in the worst order, so that changes of the analysis
+// can't make it converge slower unnoticed.
+func TestConvergence(t *testing.T) {
+	var got []nilarg.Metrics
+	nilarg.OnMetrics = func(m nilarg.Metrics) { got = append(got, m) }
+	defer func() { nilarg.OnMetrics = nil }()
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "convergence")
+	for _, m := range got {
+		if m.Package != "convergence" {
+			continue
+		}
+		t.Logf("%+v", m)
+		// One round for each of the 5 levels of the longest chain and
+		// the last one changing nothing.
+		if m.Rounds > 6 {
+			t.Errorf("the fixpoint took %d rounds; want at most 6", m.Rounds)
+		}
+		return
+	}
+	t.Error("no metrics were reported for convergence")
+}
+
+func TestNilResults(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "nilresult")
+}
+
+func TestSuccessResults(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "success")
+}
+
+func TestTestTypeAsserts(t *testing.T) {
+	testdata := analysistest.TestData()
+	defer setFlags(t, "test-type-asserts=category")()
+	for _, r := range analysistest.Run(sourceOnly{t}, testdata, nilarg.Analyzer, "testassert") {
+		for _, d := range r.Diagnostics {
+			asserted := strings.Contains(d.Message, "type asserted")
+			if asserted != (d.Category == "test-type-assert") {
+				t.Errorf("%q has the category %q", d.Message, d.Category)
+			}
+		}
+	}
+
+	if err := nilarg.Analyzer.Flags.Set("test-type-asserts", "off"); err != nil {
+		t.Fatal(err)
+	}
+	derefs := 0
+	for _, r := range analysistest.Run(silent{}, testdata, nilarg.Analyzer, "testassert") {
+		for _, d := range r.Diagnostics {
+			if strings.Contains(d.Message, "type asserted") {
+				t.Errorf("%q is reported with -test-type-asserts off", d.Message)
+			}
+			if strings.Contains(d.Message, "dereferenced") {
+				derefs++
+			}
+		}
+	}
+	if derefs == 0 {
+		t.Error("the dereference is not reported with -test-type-asserts off")
+	}
+
+	if err := nilarg.Analyzer.Flags.Set("test-type-asserts", "maybe"); err == nil {
+		t.Error("-test-type-asserts accepts maybe")
+	}
+}
+
+func TestNilFields(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "fieldnil")
+}
+
+func TestRangeLiterals(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "rangelit")
+}
+
+func TestCommaOk(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilarg.Analyzer, "commaok")
+}
+
+// TestCorpus runs the cases of testdata/src/corpus, each a package
+// reproducing a nil panic reported in real code with the findings it
+// should produce, and the fixed code which it shouldn't be reported in.
+// A fix of a false positive or a false negative adds a case there.
+func TestCorpus(t *testing.T) {
+	testdata := analysistest.TestData()
+	cases, err := ioutil.ReadDir(filepath.Join(testdata, "src", "corpus"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+		t.Run(c.Name(), func(t *testing.T) {
+			analysistest.Run(t, testdata, nilarg.Analyzer, "corpus/"+c.Name())
+		})
+	}
 }
@@ -0,0 +1,191 @@
+package nilarg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// nilCheckFuncs is the comma-separated list of the patterns of the
+// functions checking their arguments against nil, each followed by =nil
+// if the function reports true for nil arguments, as in
+//
+//	-nil-checks 'valid,isEmpty=nil,assert.NotNil'
+var nilCheckFuncs nilChecksFlag
+
+func init() {
+	Analyzer.Flags.Var(&nilCheckFuncs, "nil-checks",
+		"treat calls of the functions matching the comma-separated `patterns` as nil checks of their arguments: "+
+			"p for functions reporting true for non-nil arguments or panicking on nil ones, p=nil for functions reporting true for nil arguments")
+}
+
+// defaultNilChecks are the assertions of testify, which abort tests on
+// nil arguments with require and report whether they aren't nil with
+// assert, so that the dereferences after them are safe in tests. The
+// patterns of -nil-checks add to them.
+var defaultNilChecks = []nilCheckPattern{
+	{glob: "github.com/stretchr/testify/require.NotNil"},
+	{glob: "github.com/stretchr/testify/require.Assertions.NotNil"},
+	{glob: "github.com/stretchr/testify/assert.NotNil"},
+	{glob: "github.com/stretchr/testify/assert.Assertions.NotNil"},
+}
+
+// nilCheckPattern is an entry of -nil-checks.
+type nilCheckPattern struct {
+	// glob matches the names of functions as in -run, optionally
+	// qualified by the names or the paths of their packages, as in
+	// path.Match.
+	glob string
+	// nilOnTrue is set for functions reporting true for nil.
+	nilOnTrue bool
+}
+
+// nilChecksFlag is a flag.Value of the patterns of -nil-checks.
+type nilChecksFlag struct {
+	sync.Mutex
+	patterns []nilCheckPattern
+	s        string
+}
+
+func (f *nilChecksFlag) String() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.s
+}
+
+func (f *nilChecksFlag) Set(s string) error {
+	var patterns []nilCheckPattern
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		np := nilCheckPattern{glob: p}
+		if i := strings.LastIndex(p, "="); i >= 0 {
+			if p[i+1:] != "nil" {
+				return fmt.Errorf("%s: want pattern or pattern=nil", p)
+			}
+			np = nilCheckPattern{p[:i], true}
+		}
+		if _, err := path.Match(np.glob, ""); err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		patterns = append(patterns, np)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.patterns, f.s = patterns, s
+	return nil
+}
+
+// testifyPath is the prefix of the paths of the packages of
+// defaultNilChecks.
+const testifyPath = "github.com/stretchr/testify/"
+
+// nilCheckOf returns the pattern matching the callee of c, if any. The
+// defaults are only matched against the functions of testify, so that
+// the calls of the other functions aren't matched at all without
+// -nil-checks.
+func nilCheckOf(c *ssa.CallCommon) (nilCheckPattern, bool) {
+	nilCheckFuncs.Lock()
+	patterns := nilCheckFuncs.patterns
+	nilCheckFuncs.Unlock()
+	if fn := c.StaticCallee(); fn != nil && fn.Object() != nil && fn.Object().Pkg() != nil && strings.HasPrefix(fn.Object().Pkg().Path(), testifyPath) {
+		patterns = append(defaultNilChecks[:len(defaultNilChecks):len(defaultNilChecks)], patterns...)
+	}
+	if len(patterns) == 0 {
+		return nilCheckPattern{}, false
+	}
+	names := calleeNames(c)
+	for _, p := range patterns {
+		for _, n := range names {
+			if ok, _ := path.Match(p.glob, n); ok {
+				return p, true
+			}
+		}
+	}
+	return nilCheckPattern{}, false
+}
+
+// calleeNames returns the names the patterns of functions match for the
+// static callee of c: its name as in -run, and the name qualified by the
+// name and by the path of its package.
+func calleeNames(c *ssa.CallCommon) []string {
+	fn := c.StaticCallee()
+	if fn == nil || fn.Object() == nil || fn.Object().Pkg() == nil {
+		return nil
+	}
+	name := objName(fn.Object())
+	pkg := fn.Object().Pkg()
+	return []string{name, pkg.Name() + "." + name, pkg.Path() + "." + name}
+}
+
+// nilCheckCall returns the call of a function of -nil-checks reporting a
+// boolean if cond is one, with whether it reports true for nil.
+func nilCheckCall(cond ssa.Value) (*ssa.Call, bool, bool) {
+	c, ok := cond.(*ssa.Call)
+	if !ok {
+		return nil, false, false
+	}
+	p, ok := nilCheckOf(c.Common())
+	if !ok {
+		return nil, false, false
+	}
+	return c, p.nilOnTrue, true
+}
+
+// hasArg reports whether v is an argument of c, possibly converted to an
+// interface, which helpers such as assertions often take.
+func hasArg(c *ssa.CallCommon, v ssa.Value) bool {
+	for _, arg := range c.Args {
+		if mi, ok := arg.(*ssa.MakeInterface); ok {
+			arg = mi.X
+		}
+		if sameValue(arg, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// assertedBefore reports whether v is passed to a function of
+// -nil-checks without results, which panics on nil, before instr, that
+// is, earlier in the block of instr or in a block dominating it.
+func assertedBefore(v ssa.Value, instr ssa.Instruction) bool {
+	if v.Referrers() == nil {
+		return false
+	}
+	refs := *v.Referrers()
+	for _, r := range refs {
+		if mi, ok := r.(*ssa.MakeInterface); ok {
+			refs = append(refs[:len(refs):len(refs)], *mi.Referrers()...)
+		}
+	}
+	for _, r := range refs {
+		c, ok := r.(*ssa.Call)
+		if !ok || c.Call.Signature().Results().Len() != 0 || !hasArg(c.Common(), v) {
+			continue
+		}
+		if _, ok := nilCheckOf(c.Common()); !ok {
+			continue
+		}
+		if c.Block() == instr.Block() {
+			for _, i := range instr.Block().Instrs {
+				if i == instr {
+					break
+				}
+				if i == c {
+					return true
+				}
+			}
+			continue
+		}
+		if c.Block().Dominates(instr.Block()) {
+			return true
+		}
+	}
+	return false
+}
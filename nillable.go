@@ -0,0 +1,76 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+	"sync/atomic"
+)
+
+// nillableKinds are the kinds of types which isNillable treats as
+// nillable besides pointers, slices, maps and interfaces, given by
+// -nillable. Closing a nil channel and calling a nil function already
+// panic, but their nil values aren't otherwise tracked, e.g. as results,
+// fields or elements, unless they are listed.
+var nillableKinds nillableFlag
+
+func init() {
+	Analyzer.Flags.Var(&nillableKinds, "nillable",
+		"also treat the comma-separated `kinds` of types, chan, func and unsafe.Pointer, as nillable, e.g. tracking nil channels and functions returned or stored in fields")
+}
+
+// nillableFlag is a flag.Value of the kinds of -nillable. The kinds
+// are replaced as a whole by Set and loaded atomically, since
+// isNillable reads them on every check.
+type nillableFlag struct {
+	v atomic.Value // nillableSet
+}
+
+// nillableSet is a value of -nillable.
+type nillableSet struct {
+	chans, funcs, unsafePointers bool
+	s                            string
+}
+
+func (f *nillableFlag) load() nillableSet {
+	k, _ := f.v.Load().(nillableSet)
+	return k
+}
+
+func (f *nillableFlag) String() string {
+	return f.load().s
+}
+
+func (f *nillableFlag) Set(s string) error {
+	k := nillableSet{s: s}
+	for _, kind := range strings.Split(s, ",") {
+		switch strings.TrimSpace(kind) {
+		case "":
+		case "chan":
+			k.chans = true
+		case "func":
+			k.funcs = true
+		case "unsafe.Pointer":
+			k.unsafePointers = true
+		default:
+			return fmt.Errorf("unknown kind %q: want chan, func or unsafe.Pointer", kind)
+		}
+	}
+	f.v.Store(k)
+	return nil
+}
+
+// nillableKind reports whether -nillable lists the kind of the
+// underlying type u.
+func nillableKind(u types.Type) bool {
+	k := nillableKinds.load()
+	switch u := u.(type) {
+	case *types.Chan:
+		return k.chans
+	case *types.Signature:
+		return k.funcs
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer && k.unsafePointers
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package nilarg
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Node returns the node of files from which the instruction instr was
+// built, and the path from the node to the root of its file as returned
+// by astutil.PathEnclosingInterval. The node is the expression or the
+// statement which instr implements, such as the *ast.CallExpr of a call
+// or the *ast.IndexExpr of a map update, or the innermost node at the
+// position of instr if there is no such node. Instructions without a
+// position, such as jumps and the implicit loads of synthetic functions,
+// and instructions outside files have no node.
+func Node(files []*ast.File, instr ssa.Instruction) (ast.Node, []ast.Node) {
+	pos := instr.Pos()
+	if !pos.IsValid() {
+		return nil, nil
+	}
+	for _, f := range files {
+		if pos < f.Pos() || f.End() <= pos {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+		if len(path) == 0 {
+			return nil, nil
+		}
+		for k, n := range path {
+			if implements(instr, n) {
+				return n, path[k:]
+			}
+		}
+		return path[0], path
+	}
+	return nil, nil
+}
+
+// implements reports whether instr can be built from the node n.
+func implements(instr ssa.Instruction, n ast.Node) bool {
+	switch instr := instr.(type) {
+	case *ssa.Call, *ssa.Panic:
+		_, ok := n.(*ast.CallExpr)
+		return ok
+	case *ssa.Go:
+		_, ok := n.(*ast.GoStmt)
+		return ok
+	case *ssa.Defer:
+		_, ok := n.(*ast.DeferStmt)
+		return ok
+	case *ssa.FieldAddr, *ssa.Field:
+		_, ok := n.(*ast.SelectorExpr)
+		return ok
+	case *ssa.IndexAddr, *ssa.Index, *ssa.Lookup:
+		_, ok := n.(*ast.IndexExpr)
+		return ok
+	case *ssa.MapUpdate:
+		switch n.(type) {
+		case *ast.IndexExpr, *ast.KeyValueExpr:
+			return true
+		}
+	case *ssa.Store:
+		switch n.(type) {
+		case *ast.AssignStmt, *ast.IncDecStmt, *ast.KeyValueExpr, *ast.ValueSpec:
+			return true
+		}
+	case *ssa.UnOp:
+		if instr.Op == token.MUL {
+			switch n.(type) {
+			case *ast.StarExpr, *ast.SelectorExpr, *ast.IndexExpr:
+				return true
+			}
+			return false
+		}
+		_, ok := n.(*ast.UnaryExpr)
+		return ok
+	case *ssa.BinOp:
+		_, ok := n.(*ast.BinaryExpr)
+		return ok
+	case *ssa.TypeAssert:
+		_, ok := n.(*ast.TypeAssertExpr)
+		return ok
+	case *ssa.Slice:
+		_, ok := n.(*ast.SliceExpr)
+		return ok
+	case *ssa.Return:
+		_, ok := n.(*ast.ReturnStmt)
+		return ok
+	case *ssa.Send:
+		_, ok := n.(*ast.SendStmt)
+		return ok
+	case *ssa.Range, *ssa.Next:
+		_, ok := n.(*ast.RangeStmt)
+		return ok
+	case *ssa.MakeClosure:
+		_, ok := n.(*ast.FuncLit)
+		return ok
+	}
+	return false
+}
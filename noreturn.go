@@ -0,0 +1,104 @@
+package nilarg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// defaultNoReturns are the patterns of the functions of the standard
+// library which never return to their callers.
+var defaultNoReturns = []string{
+	"os.Exit",
+	"runtime.Goexit",
+	"log.Fatal*",
+	"log.Panic*",
+	"log.Logger.Fatal*",
+	"log.Logger.Panic*",
+	"testing.common.Fatal*",
+	"testing.common.FailNow",
+	"testing.common.Skip*",
+}
+
+// noReturnFuncs is the comma-separated list of the patterns of the
+// functions which never return, in addition to defaultNoReturns, as in
+//
+//	-no-return 'fatal,cli.Exit'
+var noReturnFuncs = noReturnFlag{patterns: defaultNoReturns}
+
+func init() {
+	Analyzer.Flags.Var(&noReturnFuncs, "no-return",
+		"treat calls of the functions matching the comma-separated `patterns`, in addition to os.Exit, log.Fatal and the like, as not returning, as in nil checks ending with them")
+}
+
+// noReturnFlag is a flag.Value of the patterns of -no-return. Its
+// patterns include defaultNoReturns.
+type noReturnFlag struct {
+	sync.Mutex
+	patterns []string
+	s        string
+}
+
+func (f *noReturnFlag) String() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.s
+}
+
+func (f *noReturnFlag) Set(s string) error {
+	patterns := append([]string(nil), defaultNoReturns...)
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.patterns, f.s = patterns, s
+	return nil
+}
+
+// isNoReturn reports whether the function called by c never returns.
+func isNoReturn(c *ssa.CallCommon) bool {
+	names := calleeNames(c)
+	if names == nil {
+		return false
+	}
+	noReturnFuncs.Lock()
+	patterns := noReturnFuncs.patterns
+	noReturnFuncs.Unlock()
+	for _, p := range patterns {
+		for _, n := range names {
+			if ok, _ := path.Match(p, n); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exits reports whether the block b ends by calling a function which
+// never returns, as in
+//
+//	if p == nil {
+//		log.Fatal("p is nil")
+//	}
+//
+// where the builder still jumps from the block to the code after the if
+// statement.
+func exits(b *ssa.BasicBlock) bool {
+	for _, instr := range b.Instrs {
+		if c, ok := instr.(*ssa.Call); ok && isNoReturn(c.Common()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// optionFields has the fields which the functional option returned by
+// the function sets, as in
+//
+//	func WithLogger(l *Logger) Option {
+//		return func(c *config) { c.log = l }
+//	}
+//
+// The fields are qualified by the struct type as in pkg.config.log.
+type optionFields []string
+
+func (*optionFields) AFact() {}
+
+func (f *optionFields) String() string { return fmt.Sprintf("optionFields%v", []string(*f)) }
+
+// requiredOptions has the fields which the function dereferences after
+// applying its variadic functional options to a struct it allocates,
+// without setting them itself. Calling the function causes panic unless
+// an option sets them.
+type requiredOptions []string
+
+func (*requiredOptions) AFact() {}
+
+func (r *requiredOptions) String() string { return fmt.Sprintf("requiredOptions%v", []string(*r)) }
+
+// qualifiedField returns the name of the i-th field of the struct type
+// pointed by t, qualified by the type.
+func qualifiedField(t types.Type, i int) string {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	return fmt.Sprintf("%s.%s", types.TypeString(t, nil), structOf(t).Field(i).Name())
+}
+
+// checkOptions exports optionFields for fn if fn returns a functional
+// option, and requiredOptions if fn applies functional options.
+func checkOptions(pass *analysis.Pass, fn *ssa.Function) {
+	if factObject(fn) == nil {
+		return
+	}
+	if fields, ok := optionSetter(fn); ok {
+		pass.ExportObjectFact(factObject(fn), &fields)
+	}
+	if required := optionsRequired(pass, fn); len(required) > 0 {
+		pass.ExportObjectFact(factObject(fn), &required)
+	}
+}
+
+// optionSetter returns the nillable fields set by the closure fn
+// returns, or false if fn doesn't return a closure taking a pointer to
+// struct.
+func optionSetter(fn *ssa.Function) (optionFields, bool) {
+	fields := optionFields{}
+	setter := false
+	for _, b := range fn.Blocks {
+		ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		v := ret.Results[0]
+		if conv, ok := v.(*ssa.ChangeType); ok {
+			v = conv.X
+		}
+		mc, ok := v.(*ssa.MakeClosure)
+		if !ok {
+			continue
+		}
+		opt := mc.Fn.(*ssa.Function)
+		if len(opt.Params) != 1 || structOf(opt.Params[0].Type()) == nil {
+			continue
+		}
+		setter = true
+		for _, ob := range opt.Blocks {
+			for _, instr := range ob.Instrs {
+				st, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				if fa, ok := st.Addr.(*ssa.FieldAddr); ok && fa.X == opt.Params[0] && isNillable(st.Val.Type()) && !isNil(st.Val) {
+					fields = append(fields, qualifiedField(fa.X.Type(), fa.Field))
+				}
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields, setter
+}
+
+// optionsRequired returns the nillable fields of the struct which fn
+// allocates and passes to its variadic options, and which fn
+// dereferences without setting or checking them.
+func optionsRequired(pass *analysis.Pass, fn *ssa.Function) requiredOptions {
+	if !fn.Signature.Variadic() || len(fn.Params) == 0 {
+		return nil
+	}
+	var required requiredOptions
+	seen := make(map[string]bool)
+	for _, alloc := range appliedOptions(fn) {
+		for _, r := range *alloc.Referrers() {
+			fa, ok := r.(*ssa.FieldAddr)
+			if !ok || isFieldSet(alloc, structOf(alloc.Type()).Field(fa.Field).Name()) {
+				continue
+			}
+			// Each use of the field has its own address.
+			field := qualifiedField(alloc.Type(), fa.Field)
+			if seen[field] {
+				continue
+			}
+			_, loads := loadField(fa)
+			for _, v := range loads {
+				if isNillable(v.Type()) && panicsOnAny(pass, v) {
+					required = append(required, field)
+					seen[field] = true
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(required)
+	return required
+}
+
+// appliedOptions returns the structs allocated by fn which fn passes to
+// the elements of its variadic parameter.
+func appliedOptions(fn *ssa.Function) []*ssa.Alloc {
+	opts := fn.Params[len(fn.Params)-1]
+	var allocs []*ssa.Alloc
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			c, ok := instr.(*ssa.Call)
+			if !ok || len(c.Call.Args) != 1 {
+				continue
+			}
+			load, ok := c.Call.Value.(*ssa.UnOp)
+			if !ok {
+				continue
+			}
+			ia, ok := load.X.(*ssa.IndexAddr)
+			if !ok || ia.X != opts {
+				continue
+			}
+			if alloc, ok := c.Call.Args[0].(*ssa.Alloc); ok && alloc.Referrers() != nil && structOf(alloc.Type()) != nil {
+				allocs = append(allocs, alloc)
+			}
+		}
+	}
+	return allocs
+}
+
+// checkOptionArgs reports the call c if the callee requires options
+// which none of the options passed by c sets.
+func checkOptionArgs(pass *analysis.Pass, c *ssa.Call) {
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil || len(c.Call.Args) == 0 {
+		return
+	}
+	var required requiredOptions
+	if !pass.ImportObjectFact(factObject(s), &required) {
+		return
+	}
+	set, ok := passedOptions(pass, c.Call.Args[len(c.Call.Args)-1])
+	if !ok {
+		return
+	}
+	for _, field := range required {
+		if !set[field] {
+			report(pass, c.Pos(), "this call can cause panic: no option sets %s", field)
+		}
+	}
+}
+
+// passedOptions returns the fields set by the options in the variadic
+// argument v, or false if some of the options are unknown.
+func passedOptions(pass *analysis.Pass, v ssa.Value) (map[string]bool, bool) {
+	set := make(map[string]bool)
+	if isNil(v) {
+		// No options are passed.
+		return set, true
+	}
+	slice, ok := v.(*ssa.Slice)
+	if !ok {
+		return nil, false
+	}
+	alloc, ok := slice.X.(*ssa.Alloc)
+	if !ok || alloc.Referrers() == nil {
+		return nil, false
+	}
+	for _, r := range *alloc.Referrers() {
+		ia, ok := r.(*ssa.IndexAddr)
+		if !ok || ia.Referrers() == nil {
+			continue
+		}
+		for _, ir := range *ia.Referrers() {
+			st, ok := ir.(*ssa.Store)
+			if !ok {
+				continue
+			}
+			oc, ok := st.Val.(*ssa.Call)
+			if !ok || oc.Call.StaticCallee() == nil || factObject(oc.Call.StaticCallee()) == nil {
+				return nil, false
+			}
+			var fields optionFields
+			if !pass.ImportObjectFact(factObject(oc.Call.StaticCallee()), &fields) {
+				return nil, false
+			}
+			for _, f := range fields {
+				set[f] = true
+			}
+		}
+	}
+	return set, true
+}
@@ -0,0 +1,179 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// preconditions makes the analyzer treat explicit nil checks followed
+// by panic at function entry as documented preconditions.
+var preconditions bool
+
+// panicMessages makes the analyzer check that the panics of the nil
+// checks at function entry mention the parameter or panic with an error.
+var panicMessages bool
+
+// validationPanics is how the calls passing nil to the parameters of the
+// preconditions are reported, as they panic on purpose, with a message,
+// rather than by accident.
+var validationPanics = reportMode("report")
+
+func init() {
+	Analyzer.Flags.BoolVar(&preconditions, "preconditions", false,
+		"treat nil checks followed by panic at function entry as preconditions")
+	Analyzer.Flags.BoolVar(&panicMessages, "panic-messages", false,
+		"check that panics on nil parameters at function entry mention the parameter or panic with an error")
+	Analyzer.Flags.Var(&validationPanics, "validation-panics",
+		"report the calls violating preconditions as the others (report), with the category "+validationCategory+" (category), or not at all (off)")
+}
+
+// validationCategory is the category of the calls violating
+// preconditions with -validation-panics category.
+const validationCategory = "nil-validation"
+
+// intentionalArgs has the indices of the arguments which the function
+// intentionally panics on when they are nil, because it begins with
+//
+//	if p == nil { panic("p must not be nil") }
+type intentionalArgs map[int]struct{}
+
+func (*intentionalArgs) AFact() {}
+
+func (a *intentionalArgs) String() string {
+	idx := make([]int, 0, len(*a))
+	for i := range *a {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return fmt.Sprintf("intentionalArgs%v", idx)
+}
+
+// entryGuards returns the panics of the nil checks of the parameters of
+// fn at its entry, keyed by the parameter index.
+func entryGuards(fn *ssa.Function) map[int]*ssa.Panic {
+	guards := make(map[int]*ssa.Panic)
+	if len(fn.Blocks) == 0 {
+		return guards
+	}
+	for b := fn.Blocks[0]; ; {
+		binop, tsucc, fsucc := eq(b)
+		if binop == nil {
+			return guards
+		}
+		i := paramIndex(fn, binop)
+		if i < 0 {
+			return guards
+		}
+		pn, ok := tsucc.Instrs[len(tsucc.Instrs)-1].(*ssa.Panic)
+		if !ok {
+			return guards
+		}
+		guards[i] = pn
+		b = fsucc
+	}
+}
+
+// paramIndex returns the index of the parameter of fn compared to nil
+// by binop, or -1.
+func paramIndex(fn *ssa.Function, binop *ssa.BinOp) int {
+	for i, fp := range fn.Params {
+		if isNil(binop.X) && binop.Y == fp || isNil(binop.Y) && binop.X == fp {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkPreconditions exports intentionalArgs for fn if fn begins with
+// nil checks of its parameters followed by panic.
+func checkPreconditions(pass *analysis.Pass, fn *ssa.Function) {
+	if !preconditions || factObject(fn) == nil {
+		return
+	}
+	fact := intentionalArgs{}
+	for i := range entryGuards(fn) {
+		fact[i] = struct{}{}
+	}
+	if len(fact) > 0 {
+		pass.ExportObjectFact(factObject(fn), &fact)
+	}
+}
+
+// isIntentional reports whether the function f intentionally panics
+// when its i-th argument is nil.
+func isIntentional(pass *analysis.Pass, f *ssa.Function, i int) bool {
+	var fact intentionalArgs
+	if !preconditions || factObject(f) == nil || !pass.ImportObjectFact(factObject(f), &fact) {
+		return false
+	}
+	_, ok := fact[i]
+	return ok
+}
+
+// reportViolation reports the call c passing nil to a parameter of a
+// precondition of s, as -validation-panics says.
+func reportViolation(pass *analysis.Pass, c ssa.CallInstruction, s *ssa.Function) {
+	validationPanics.report(pass, analysis.Diagnostic{
+		Pos:     c.Pos(),
+		Message: fmt.Sprintf("this call violates a precondition of %s", s.Name()),
+	}, validationCategory)
+}
+
+// checkPanicMessages reports the panics of the nil checks at the entry
+// of fn whose value is a string not mentioning the parameter, such as
+//
+//	if p == nil { panic("invalid argument") }
+//
+// Panics with errors or with values built at run time aren't reported.
+func checkPanicMessages(pass *analysis.Pass, fn *ssa.Function) {
+	if !panicMessages {
+		return
+	}
+	for i, pn := range entryGuards(fn) {
+		name := fn.Params[i].Name()
+		v := pn.X
+		if mi, ok := v.(*ssa.MakeInterface); ok {
+			v = mi.X
+		}
+		if types.Implements(v.Type(), errorType) {
+			continue
+		}
+		c, ok := v.(*ssa.Const)
+		if !ok {
+			continue
+		}
+		if c.Value == nil || c.Value.Kind() != constant.String || !mentions(constant.StringVal(c.Value), name) {
+			report(pass, pn.Pos(), "the panic on nil %s should mention %s or be an error", name, name)
+		}
+	}
+}
+
+// mentions reports whether s has name as a word, not a part of another
+// identifier, as the p in "p is nil" but not in "nothing to process".
+func mentions(s, name string) bool {
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for i := 0; ; {
+		j := strings.Index(s[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (start == 0 || !isIdent(before)) && (end == len(s) || !isIdent(after)) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// errorType is the error interface.
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
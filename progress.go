@@ -0,0 +1,80 @@
+package nilarg
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Progress is the state of a run reported to OnProgress.
+type Progress struct {
+	// Packages is the number of packages analyzed so far.
+	Packages int
+	// Package is the path of the package whose analysis started or
+	// ended, or reported the last finding.
+	Package string
+	// Done reports whether the analysis of Package ended.
+	Done bool
+	// Findings is the number of diagnostics reported so far.
+	Findings int
+}
+
+// OnProgress, if not nil, is called when the analysis of a package
+// starts or ends and when a diagnostic is reported, so that drivers
+// embedding the analyzer can show the progress of long runs. The calls
+// are serialized even when the driver analyzes packages in parallel.
+var OnProgress func(Progress)
+
+var (
+	// showProgress writes the progress of the run to the standard
+	// error.
+	showProgress bool
+
+	progressMu    sync.Mutex
+	progressState Progress
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&showProgress, "progress", false,
+		"write the number of analyzed packages and findings to stderr while running")
+}
+
+// progress updates the state of the run with update and reports it to
+// OnProgress and, with -progress, to the standard error.
+func progress(pass *analysis.Pass, update func(*Progress)) {
+	if OnProgress == nil && !showProgress {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progressState.Package = pass.Pkg.Path()
+	progressState.Done = false
+	update(&progressState)
+	if OnProgress != nil {
+		OnProgress(progressState)
+	}
+	if showProgress {
+		fmt.Fprintf(os.Stderr, "nilarg: %d packages, %d findings: %s\n",
+			progressState.Packages, progressState.Findings, progressState.Package)
+	}
+}
+
+// startPackage reports that the analysis of the package of pass started.
+// It returns a function which reports that the analysis ended.
+func startPackage(pass *analysis.Pass) func() {
+	progress(pass, func(*Progress) {})
+	return func() {
+		progress(pass, func(p *Progress) {
+			p.Packages++
+			p.Done = true
+		})
+	}
+}
+
+// countFinding reports that a diagnostic was reported in the package of
+// pass.
+func countFinding(pass *analysis.Pass) {
+	progress(pass, func(p *Progress) { p.Findings++ })
+}
@@ -0,0 +1,114 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// nonNilElem reports whether v is an element of a local slice or array
+// literal all of whose elements are non-nil, as p in
+//
+//	for _, p := range []*T{a, &T{}} {
+//		use(p)
+//	}
+//
+// where a isn't nil. The literal must not escape, so that its elements
+// can't be replaced elsewhere. seen has the literals being checked, whose
+// elements are unknown while checking the values stored into them.
+func nonNilElem(pass *analysis.Pass, v ssa.Value, seen map[*ssa.Alloc]bool) bool {
+	load, ok := v.(*ssa.UnOp)
+	if !ok || load.Op != token.MUL {
+		return false
+	}
+	ia, ok := load.X.(*ssa.IndexAddr)
+	if !ok {
+		return false
+	}
+	x := ia.X
+	if s, ok := x.(*ssa.Slice); ok {
+		if !onlyIndexed(s) {
+			return false
+		}
+		x = s.X
+	}
+	lit, ok := x.(*ssa.Alloc)
+	if !ok || lit.Referrers() == nil {
+		return false
+	}
+	arr, ok := lit.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array)
+	if !ok || !isNillable(arr.Elem()) || seen[lit] {
+		return false
+	}
+	if seen == nil {
+		seen = make(map[*ssa.Alloc]bool)
+	}
+	seen[lit] = true
+	defer delete(seen, lit)
+	stored := make(map[int64]bool)
+	for _, r := range *lit.Referrers() {
+		switch r := r.(type) {
+		case *ssa.Slice:
+			if !onlyIndexed(r) {
+				return false
+			}
+		case *ssa.IndexAddr:
+			if r.Referrers() == nil {
+				continue
+			}
+			for _, ir := range *r.Referrers() {
+				switch ir := ir.(type) {
+				case *ssa.Store:
+					c, ok := r.Index.(*ssa.Const)
+					if !ok || ir.Addr != ssa.Value(r) || nilnessIn(pass, nil, ir.Val, seen) != isnonnil {
+						return false
+					}
+					stored[c.Int64()] = true
+				case *ssa.UnOp:
+					if ir.Op != token.MUL {
+						return false
+					}
+				case *ssa.DebugRef:
+				default:
+					return false
+				}
+			}
+		case *ssa.DebugRef:
+		default:
+			return false
+		}
+	}
+	// The elements left out of the literal are nil.
+	return int64(len(stored)) == arr.Len()
+}
+
+// onlyIndexed reports whether the elements of the slice s are only
+// loaded, as in a range loop, and it is otherwise only passed to len.
+func onlyIndexed(s *ssa.Slice) bool {
+	if s.Referrers() == nil {
+		return true
+	}
+	for _, r := range *s.Referrers() {
+		switch r := r.(type) {
+		case *ssa.IndexAddr:
+			if r.Referrers() == nil {
+				continue
+			}
+			for _, ir := range *r.Referrers() {
+				if load, ok := ir.(*ssa.UnOp); !ok || load.Op != token.MUL {
+					return false
+				}
+			}
+		case *ssa.Call:
+			if b, ok := r.Call.Value.(*ssa.Builtin); !ok || b.Name() != "len" {
+				return false
+			}
+		case *ssa.DebugRef:
+		default:
+			return false
+		}
+	}
+	return true
+}
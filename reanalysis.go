@@ -0,0 +1,139 @@
+package nilarg
+
+import (
+	"go/token"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Finding is a diagnostic reported by the analyzer, resolved to its
+// position so that it outlives the file set of the run.
+type Finding struct {
+	// Func is the name of the function declaration enclosing the
+	// diagnostic, with methods named T.M, or "" outside functions.
+	Func    string
+	Posn    token.Position
+	Message string
+}
+
+// Reanalyze, if not nil, restricts the diagnostics of its package to the
+// ones which can change after editing some of its files, so that hosts
+// like editors can re-analyze the package on each edit and merge the
+// new findings with the previous ones by Merge.
+var Reanalyze *Reanalysis
+
+// Reanalysis is the set of the files edited in a package and the
+// functions in the other files whose diagnostics depend on them.
+type Reanalysis struct {
+	Package string
+	Files   map[string]bool
+	Funcs   map[string]bool
+}
+
+// NewReanalysis returns the Reanalysis of the package pkg whose previous
+// Result is prev after editing the files changed. Besides the functions
+// in the changed files, the functions calling them in the package,
+// directly or not, are re-analyzed, because the facts they depend on
+// can change.
+func NewReanalysis(pkg string, prev *Result, changed []string) *Reanalysis {
+	r := &Reanalysis{Package: pkg, Files: make(map[string]bool), Funcs: make(map[string]bool)}
+	var queue []string
+	for _, file := range changed {
+		r.Files[file] = true
+		queue = append(queue, prev.Files[file]...)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if r.Funcs[name] {
+			continue
+		}
+		r.Funcs[name] = true
+		queue = append(queue, prev.Callers[name]...)
+	}
+	return r
+}
+
+// Merge returns the findings of prev which the re-analysis didn't
+// repeat and the findings of next, the Result of the re-analysis.
+func (r *Reanalysis) Merge(prev, next *Result) []Finding {
+	var merged []Finding
+	for _, f := range prev.Findings {
+		if !r.Files[f.Posn.Filename] && !r.Funcs[f.Func] {
+			merged = append(merged, f)
+		}
+	}
+	return append(merged, next.Findings...)
+}
+
+// matchesReanalysis reports whether the diagnostic at pos is reported
+// by the re-analysis selected by Reanalyze.
+func matchesReanalysis(pass *analysis.Pass, pos token.Pos) bool {
+	r := Reanalyze
+	if r == nil || r.Package != pass.Pkg.Path() {
+		return true
+	}
+	name, _ := enclosingFunc(pass, pos)
+	return r.Files[pass.Fset.Position(pos).Filename] || r.Funcs[name]
+}
+
+// findings collects the findings of the packages being analyzed until
+// their Results are built.
+var findings = struct {
+	sync.Mutex
+	m map[*analysis.Pass][]Finding
+}{m: make(map[*analysis.Pass][]Finding)}
+
+// recordFinding records the diagnostic d reported by pass.
+func recordFinding(pass *analysis.Pass, d analysis.Diagnostic) {
+	name, _ := enclosingFunc(pass, d.Pos)
+	findings.Lock()
+	defer findings.Unlock()
+	findings.m[pass] = append(findings.m[pass], Finding{name, pass.Fset.Position(d.Pos), d.Message})
+}
+
+// takeFindings returns and forgets the findings of pass.
+func takeFindings(pass *analysis.Pass) []Finding {
+	findings.Lock()
+	defer findings.Unlock()
+	f := findings.m[pass]
+	delete(findings.m, pass)
+	return f
+}
+
+// callGraph sets the Files and Callers of res for fns, naming the
+// anonymous functions by the declarations enclosing them.
+func callGraph(pass *analysis.Pass, res *Result, fns []*ssa.Function) {
+	res.Files = make(map[string][]string)
+	res.Callers = make(map[string][]string)
+	callers := make(map[string]map[string]bool)
+	for _, fn := range fns {
+		caller, ok := enclosingFunc(pass, fn.Pos())
+		if !ok {
+			continue
+		}
+		if fn.Parent() == nil {
+			file := pass.Fset.Position(fn.Pos()).Filename
+			res.Files[file] = append(res.Files[file], caller)
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				c, ok := instr.(ssa.CallInstruction)
+				if !ok || c.Common().StaticCallee() == nil || c.Common().StaticCallee().Pkg != fn.Pkg {
+					continue
+				}
+				callee, ok := enclosingFunc(pass, c.Common().StaticCallee().Pos())
+				if !ok || callee == caller || callers[callee][caller] {
+					continue
+				}
+				if callers[callee] == nil {
+					callers[callee] = make(map[string]bool)
+				}
+				callers[callee][caller] = true
+				res.Callers[callee] = append(res.Callers[callee], caller)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package nilarg
+
+import "golang.org/x/tools/go/ssa"
+
+var trustRecover bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&trustRecover, "trust-recover", false,
+		"don't report parameters whose dereferences are all after a deferred call of a function calling recover")
+}
+
+// recovered reports whether the panics of instr in fn are recovered, as
+// with -trust-recover instr is after a deferred call recovering them, as
+// in
+//
+//	func f(p *T) {
+//		defer func() { recover() }()
+//		p.x = 1
+//	}
+//
+// The panics of nil dereferences don't escape such functions to their
+// callers.
+func recovered(fn *ssa.Function, instr ssa.Instruction) bool {
+	if !trustRecover {
+		return false
+	}
+	for _, b := range fn.Blocks {
+		for _, i := range b.Instrs {
+			if d, ok := i.(*ssa.Defer); ok && callsRecover(d.Call.Value) && before(d, instr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callsRecover reports whether the deferred function f calls recover
+// itself, which is the only way for recover to stop a panic, and doesn't
+// panic again, as in
+//
+//	defer func() {
+//		r := recover()
+//		log.Print(r)
+//		panic(r)
+//	}()
+func callsRecover(f ssa.Value) bool {
+	if mc, ok := f.(*ssa.MakeClosure); ok {
+		f = mc.Fn
+	}
+	fn, ok := f.(*ssa.Function)
+	if !ok {
+		return false
+	}
+	recovers := false
+	for _, b := range fn.Blocks {
+		for _, i := range b.Instrs {
+			switch i := i.(type) {
+			case *ssa.Panic:
+				return false
+			case *ssa.Call:
+				if bi, ok := i.Call.Value.(*ssa.Builtin); ok && bi.Name() == "recover" {
+					recovers = true
+				}
+			}
+		}
+	}
+	return recovers
+}
@@ -0,0 +1,142 @@
+package nilarg
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// registries maps the passes being run to the functions registered in
+// the package-level maps of their packages.
+var registries = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[*ssa.Global][]*ssa.Function
+}{m: make(map[*analysis.Pass]map[*ssa.Global][]*ssa.Function)}
+
+// collectRegistries records the functions stored in the package-level
+// maps of the package of fns, including the package initializer, as in
+//
+//	var handlers = map[string]func(*T){"a": handleA}
+//
+//	func init() { handlers["b"] = handleB }
+//
+// It returns a function which forgets the registries.
+func collectRegistries(pass *analysis.Pass, pkg *ssa.Package, fns []*ssa.Function) func() {
+	regs := make(map[*ssa.Global][]*ssa.Function)
+	if init := pkg.Func("init"); init != nil {
+		fns = append(fns[:len(fns):len(fns)], init)
+	}
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				up, ok := instr.(*ssa.MapUpdate)
+				if !ok {
+					continue
+				}
+				g := registry(up.Map)
+				h := registeredFunc(up.Value)
+				if g != nil && h != nil {
+					regs[g] = append(regs[g], h)
+				}
+			}
+		}
+	}
+	for _, hs := range regs {
+		sort.Slice(hs, func(i, j int) bool { return hs[i].Name() < hs[j].Name() })
+	}
+	registries.Lock()
+	defer registries.Unlock()
+	registries.m[pass] = regs
+	return func() {
+		registries.Lock()
+		defer registries.Unlock()
+		delete(registries.m, pass)
+	}
+}
+
+// registry returns the package-level variable of the map v, or nil if v
+// isn't loaded from one. The map built by the initializer of the
+// variable is stored to it after the updates, so the map of the
+// updates is traced to the store.
+func registry(v ssa.Value) *ssa.Global {
+	switch v := v.(type) {
+	case *ssa.UnOp:
+		g, _ := v.X.(*ssa.Global)
+		return g
+	case *ssa.MakeMap:
+		if v.Referrers() == nil {
+			return nil
+		}
+		for _, r := range *v.Referrers() {
+			if st, ok := r.(*ssa.Store); ok && st.Val == ssa.Value(v) {
+				g, _ := st.Addr.(*ssa.Global)
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// registeredFunc returns the function stored as v, or nil if v isn't a
+// function declared in the package.
+func registeredFunc(v ssa.Value) *ssa.Function {
+	if ct, ok := v.(*ssa.ChangeType); ok {
+		v = ct.X
+	}
+	fn, _ := v.(*ssa.Function)
+	return fn
+}
+
+// dispatchedFuncs returns the functions registered in the map from
+// which the called value v is looked up, as in
+//
+//	handlers[name](p)
+func dispatchedFuncs(pass *analysis.Pass, v ssa.Value) []*ssa.Function {
+	if ex, ok := v.(*ssa.Extract); ok && ex.Index == 0 {
+		// h, ok := handlers[name]
+		v = ex.Tuple
+	}
+	lookup, ok := v.(*ssa.Lookup)
+	if !ok {
+		return nil
+	}
+	g := registry(lookup.X)
+	if g == nil {
+		return nil
+	}
+	registries.Lock()
+	defer registries.Unlock()
+	return registries.m[pass][g]
+}
+
+// panickingHandler returns a function registered in the map from which
+// the callee of the dynamic call common is looked up and which panics
+// when its i-th argument is nil, or nil if there is none.
+func panickingHandler(pass *analysis.Pass, common *ssa.CallCommon, i int) *ssa.Function {
+	if common.IsInvoke() || common.StaticCallee() != nil {
+		return nil
+	}
+	for _, h := range dispatchedFuncs(pass, common.Value) {
+		var fact panicArgs
+		if factObject(h) == nil || !pass.ImportObjectFact(factObject(h), &fact) {
+			continue
+		}
+		if _, ok := fact[i]; ok {
+			return h
+		}
+	}
+	return nil
+}
+
+// checkDispatch reports the dynamic call c if it passes nil to a
+// function registered in the map the callee is looked up from which
+// panics on it.
+func checkDispatch(pass *analysis.Pass, c *ssa.Call, stack []fact) {
+	for i, arg := range c.Call.Args {
+		if h := panickingHandler(pass, c.Common(), i); h != nil && nilnessOf(pass, stack, arg) == isnil {
+			report(pass, c.Pos(), "this call can cause panic: the registered %s panics on nil", h.Name())
+		}
+	}
+}
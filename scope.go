@@ -0,0 +1,90 @@
+package nilarg
+
+import (
+	"go/token"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// symbol restricts the diagnostics to a function, named pkg.Name with
+// methods named pkg.T.M, and the functions of its package which it
+// calls, directly or not.
+var symbol string
+
+func init() {
+	usage := "report only in the function `pkg.Name` and the functions of its package which it calls, directly or not"
+	Analyzer.Flags.StringVar(&symbol, "func", "", usage)
+	Analyzer.Flags.StringVar(&symbol, "symbol", "", "alias of -func")
+}
+
+// scopes maps the passes being run to the names of the functions
+// selected by symbol in their packages, as in -run.
+var scopes = struct {
+	sync.Mutex
+	m map[*analysis.Pass]map[string]bool
+}{m: make(map[*analysis.Pass]map[string]bool)}
+
+// collectScope records the function of fns selected by symbol and the
+// functions of fns which it calls, directly or not, including by the
+// anonymous functions in them. It returns a function which forgets the
+// scope.
+func collectScope(pass *analysis.Pass, fns []*ssa.Function) func() {
+	if symbol == "" {
+		return func() {}
+	}
+	scope := make(map[string]bool)
+	if name := strings.TrimPrefix(symbol, pass.Pkg.Path()+"."); name != symbol {
+		callees := make(map[string][]string)
+		for _, fn := range fns {
+			caller, ok := enclosingFunc(pass, fn.Pos())
+			if !ok {
+				continue
+			}
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					c, ok := instr.(ssa.CallInstruction)
+					if !ok || c.Common().StaticCallee() == nil || c.Common().StaticCallee().Pkg != fn.Pkg {
+						continue
+					}
+					if callee, ok := enclosingFunc(pass, c.Common().StaticCallee().Pos()); ok {
+						callees[caller] = append(callees[caller], callee)
+					}
+				}
+			}
+		}
+		queue := []string{name}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if scope[name] {
+				continue
+			}
+			scope[name] = true
+			queue = append(queue, callees[name]...)
+		}
+	}
+	scopes.Lock()
+	defer scopes.Unlock()
+	scopes.m[pass] = scope
+	return func() {
+		scopes.Lock()
+		defer scopes.Unlock()
+		delete(scopes.m, pass)
+	}
+}
+
+// matchesSymbol reports whether pos is in a function selected by
+// symbol. Nothing is selected in the other packages, including the
+// ones called by the function, which are analyzed before it.
+func matchesSymbol(pass *analysis.Pass, pos token.Pos) bool {
+	if symbol == "" {
+		return true
+	}
+	name, ok := enclosingFunc(pass, pos)
+	scopes.Lock()
+	defer scopes.Unlock()
+	return ok && scopes.m[pass][name]
+}
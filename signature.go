@@ -0,0 +1,87 @@
+package nilarg
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// signatures enables advisory diagnostics suggesting value parameters
+// in place of pointer parameters which are only read and must not be
+// nil.
+var signatures bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&signatures, "signatures", false,
+		"suggest changing *T to T for parameters which are only read and must not be nil")
+}
+
+// adviseSignatures reports the pointer parameters of fns which have a
+// panicArgs fact and are never written through, with the number of the
+// call sites in the package which a signature change would affect.
+func adviseSignatures(pass *analysis.Pass, fns []*ssa.Function) {
+	if !signatures {
+		return
+	}
+	calls := callSites(fns)
+	for _, fn := range fns {
+		var fact panicArgs
+		if factObject(fn) == nil || !pass.ImportObjectFact(factObject(fn), &fact) {
+			continue
+		}
+		for i, fp := range fn.Params {
+			if _, ok := fact[i]; !ok || i == 0 && fn.Signature.Recv() != nil {
+				continue
+			}
+			ptr, ok := fp.Type().(*types.Pointer)
+			if !ok || !isReadOnly(fp) {
+				continue
+			}
+			elem := types.TypeString(ptr.Elem(), types.RelativeTo(pass.Pkg))
+			report(pass, fp.Pos(), "parameter %s is only read and must not be nil; consider changing *%s to %s (%d call sites)",
+				fp.Name(), elem, elem, calls[fn])
+		}
+	}
+}
+
+// isReadOnly reports whether the pointer v is only used to read the
+// value it points to.
+func isReadOnly(v ssa.Value) bool {
+	for _, r := range *v.Referrers() {
+		switch r := r.(type) {
+		case *ssa.DebugRef:
+		case *ssa.UnOp:
+			if r.Op != token.MUL {
+				return false
+			}
+		case *ssa.FieldAddr:
+			if !isReadOnly(r) {
+				return false
+			}
+		case *ssa.IndexAddr:
+			if !isReadOnly(r) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// callSites counts the static calls of each function in fns.
+func callSites(fns []*ssa.Function) map[*ssa.Function]int {
+	calls := make(map[*ssa.Function]int)
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if c, ok := instr.(ssa.CallInstruction); ok && c.Common().StaticCallee() != nil {
+					calls[c.Common().StaticCallee()]++
+				}
+			}
+		}
+	}
+	return calls
+}
@@ -0,0 +1,282 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// stringers enables the tracking of nil pointers passed to logging
+// helpers which call String or Error on their arguments.
+var stringers bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&stringers, "stringers", false,
+		"report nil pointers passed to functions calling their String or Error method which dereferences the receiver")
+}
+
+// stringerCalls has the information about interface arguments, or
+// variadic arguments of interfaces, on which the function calls the
+// methods String or Error, as in
+//
+//	func logf(format string, args ...interface{}) {
+//		for i, a := range args {
+//			if s, ok := a.(fmt.Stringer); ok {
+//				args[i] = s.String()
+//			}
+//		}
+//		...
+//	}
+//
+// The key is the argument index and the values are the method names.
+type stringerCalls map[int][]string
+
+func (*stringerCalls) AFact() {}
+
+func (f *stringerCalls) String() string {
+	var calls []string
+	for i, names := range *f {
+		calls = append(calls, fmt.Sprintf("%d:%v", i, names))
+	}
+	sort.Strings(calls)
+	return fmt.Sprintf("stringerCalls%v", calls)
+}
+
+// stringerMethods are the methods called by printing helpers.
+var stringerMethods = map[string]bool{"String": true, "Error": true}
+
+// checkStringerCalls exports stringerCalls for the functions in fns,
+// including the ones passing their arguments on to such functions, as
+// in
+//
+//	func debugf(format string, args ...interface{}) {
+//		logf("debug: "+format, args...)
+//	}
+func checkStringerCalls(pass *analysis.Pass, fns []*ssa.Function) {
+	if !stringers {
+		return
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range fns {
+			if factObject(fn) == nil {
+				continue
+			}
+			var old stringerCalls
+			pass.ImportObjectFact(factObject(fn), &old)
+			fact := stringerCallsOf(pass, fn)
+			if len(fact) > len(old) {
+				pass.ExportObjectFact(factObject(fn), &fact)
+				changed = true
+			}
+		}
+	}
+}
+
+// stringerCallsOf returns the stringerCalls of fn.
+func stringerCallsOf(pass *analysis.Pass, fn *ssa.Function) stringerCalls {
+	params := make(map[ssa.Value]int)
+	for i, fp := range fn.Params {
+		params[fp] = i
+	}
+	fact := stringerCalls{}
+	add := func(i int, name string) {
+		for _, n := range fact[i] {
+			if n == name {
+				return
+			}
+		}
+		fact[i] = append(fact[i], name)
+		sort.Strings(fact[i])
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			c, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			common := c.Common()
+			if common.IsInvoke() {
+				if !stringerMethods[common.Method.Name()] {
+					continue
+				}
+				if i, ok := params[stringerOperand(common.Value)]; ok {
+					add(i, common.Method.Name())
+				}
+				continue
+			}
+			s := common.StaticCallee()
+			if s == nil || factObject(s) == nil {
+				continue
+			}
+			var sfact stringerCalls
+			if !pass.ImportObjectFact(factObject(s), &sfact) {
+				continue
+			}
+			for k, names := range sfact {
+				if k >= len(common.Args) {
+					continue
+				}
+				if i, ok := params[common.Args[k]]; ok {
+					for _, name := range names {
+						add(i, name)
+					}
+				}
+			}
+		}
+	}
+	return fact
+}
+
+// stringerOperand returns the interface value, or the slice of
+// interface values, which the receiver v of a String or Error call is
+// asserted from.
+func stringerOperand(v ssa.Value) ssa.Value {
+	if ex, ok := v.(*ssa.Extract); ok && ex.Index == 0 {
+		v = ex.Tuple
+	}
+	ta, ok := v.(*ssa.TypeAssert)
+	if !ok {
+		return v
+	}
+	v = ta.X
+	if ia := elementAddr(v); ia != nil {
+		return ia.X
+	}
+	return v
+}
+
+// printFuncs maps the formatting functions of the standard library to
+// the index of their variadic arguments. They call String and Error on
+// the arguments but recover the panics of nil pointer receivers and
+// print <nil> instead.
+var printFuncs = map[string]int{
+	"fmt.Errorf":   1,
+	"fmt.Fprint":   1,
+	"fmt.Fprintf":  2,
+	"fmt.Fprintln": 1,
+	"fmt.Print":    0,
+	"fmt.Printf":   1,
+	"fmt.Println":  0,
+	"fmt.Sprint":   0,
+	"fmt.Sprintf":  1,
+	"fmt.Sprintln": 0,
+	"log.Fatal":    0,
+	"log.Fatalf":   1,
+	"log.Fatalln":  0,
+	"log.Panic":    0,
+	"log.Panicf":   1,
+	"log.Panicln":  0,
+	"log.Print":    0,
+	"log.Printf":   1,
+	"log.Println":  0,
+}
+
+// checkStringerArgs reports the call c if it passes a nil pointer, or a
+// result of a call which can return nil, to a function calling its
+// String or Error method, and the method panics on the nil receiver.
+// The formatting functions of the standard library don't panic but
+// print <nil>, which is reported as well.
+func checkStringerArgs(pass *analysis.Pass, c *ssa.Call, stack []fact) {
+	if !stringers {
+		return
+	}
+	s := c.Call.StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return
+	}
+	var fact stringerCalls
+	k, printer := printFuncs[s.String()]
+	if printer {
+		fact = stringerCalls{k: {"Error", "String"}}
+	} else if !pass.ImportObjectFact(factObject(s), &fact) {
+		return
+	}
+	for k, names := range fact {
+		if k >= len(c.Call.Args) {
+			continue
+		}
+		for _, x := range interfaceArgs(c.Call.Args[k]) {
+			nilness := nilnessOf(pass, stack, x)
+			if _, ok := mayReturnNil(pass, x); nilness == isnonnil || nilness == unknown && !ok {
+				continue
+			}
+			t := types.TypeString(x.Type(), types.RelativeTo(pass.Pkg))
+			what := "a nil " + t
+			if nilness != isnil {
+				what = "a possibly nil " + t
+			}
+			for _, name := range names {
+				if nilPanickingMethod(pass, x.Type(), name) == nil {
+					continue
+				}
+				if printer {
+					report(pass, c.Pos(), "%s of %s dereferences the receiver, so %s prints <nil>", name, what, s)
+				} else {
+					report(pass, c.Pos(), "this call can cause panic: %s calls %s on %s", s.Name(), name, what)
+				}
+				break
+			}
+		}
+	}
+}
+
+// interfaceArgs returns the pointers converted to the interface v, or
+// to the elements of the slice literal v.
+func interfaceArgs(v ssa.Value) []ssa.Value {
+	var vals []ssa.Value
+	if mi, ok := v.(*ssa.MakeInterface); ok {
+		vals = append(vals, mi.X)
+	}
+	slice, ok := v.(*ssa.Slice)
+	if !ok {
+		return vals
+	}
+	lit, ok := slice.X.(*ssa.Alloc)
+	if !ok || lit.Referrers() == nil {
+		return vals
+	}
+	for _, r := range *lit.Referrers() {
+		ia, ok := r.(*ssa.IndexAddr)
+		if !ok || ia.Referrers() == nil {
+			continue
+		}
+		for _, ir := range *ia.Referrers() {
+			if st, ok := ir.(*ssa.Store); ok && st.Addr == ssa.Value(ia) {
+				if mi, ok := st.Val.(*ssa.MakeInterface); ok {
+					vals = append(vals, mi.X)
+				}
+			}
+		}
+	}
+	return vals
+}
+
+// nilPanickingMethod returns the method called name of the pointer type
+// t if it panics when the receiver is nil, because the method has a
+// value receiver or a panicArgs fact for the receiver.
+func nilPanickingMethod(pass *analysis.Pass, t types.Type, name string) *types.Func {
+	if _, ok := t.Underlying().(*types.Pointer); !ok {
+		return nil
+	}
+	sel := types.NewMethodSet(t).Lookup(nil, name)
+	if sel == nil {
+		return nil
+	}
+	m := sel.Obj().(*types.Func)
+	if _, ok := m.Type().(*types.Signature).Recv().Type().Underlying().(*types.Pointer); !ok {
+		// The wrapper of the value method dereferences the pointer.
+		return m
+	}
+	var fact panicArgs
+	if !pass.ImportObjectFact(m, &fact) || isNilSafeRecv(pass, m, 0) {
+		return nil
+	}
+	if _, ok := fact[0]; ok {
+		return m
+	}
+	return nil
+}
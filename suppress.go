@@ -0,0 +1,362 @@
+package nilarg
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// baselineFile is the file listing the fingerprints of the accepted
+// findings, one per line, each optionally followed by until=YYYY-MM-DD.
+var baselineFile string
+
+func init() {
+	Analyzer.Flags.StringVar(&baselineFile, "baseline", "",
+		"suppress the findings whose fingerprints are listed in `file`, one per line with an optional until=YYYY-MM-DD")
+}
+
+// ignoreDirective is the comment suppressing the findings on its line or
+// the next one, as in
+//
+//	//nilarg:ignore until=2025-12-31 fixed by the refactoring
+//	p.f()
+const ignoreDirective = "//nilarg:ignore"
+
+// argDirective begins the comments on an argument of a call suppressing
+// the findings of passing the argument at the call, as in
+//
+//	f(p /* nilarg: checked above */)
+//
+// for guards the analyzer can't see. It is a token of its own, so
+// //nilarg:ignore isn't one.
+const argDirective = "nilarg:"
+
+// dateLayout is the layout of the expiry dates of suppressions.
+const dateLayout = "2006-01-02"
+
+// suppressed reports whether d, whose fingerprint is fp, is suppressed
+// by a directive or the baseline which hasn't expired. The message of a
+// finding whose suppression expired mentions the expiry.
+func suppressed(pass *analysis.Pass, d *analysis.Diagnostic, fp string) bool {
+	until, ok := directiveExpiry(pass, d)
+	if !ok {
+		until, ok = baselineExpiry(fp)
+	}
+	if !ok {
+		return false
+	}
+	if until.IsZero() || time.Now().Before(until) {
+		return true
+	}
+	d.Message += fmt.Sprintf(" (the suppression expired on %s)", until.Format(dateLayout))
+	return false
+}
+
+// directiveExpiry returns the expiry of the ignore directive on the line
+// of d or the previous one, which is zero if it doesn't expire.
+func directiveExpiry(pass *analysis.Pass, d *analysis.Diagnostic) (time.Time, bool) {
+	file, _ := enclosingPath(pass, d.Pos)
+	if file == nil {
+		return time.Time{}, false
+	}
+	line := pass.Fset.Position(d.Pos).Line
+	for _, g := range file.Comments {
+		for _, c := range g.List {
+			l := pass.Fset.Position(c.Pos()).Line
+			if (l == line || l == line-1) && isIgnoreDirective(c) {
+				// A directive with a malformed expiry, reported by
+				// checkIgnoreDirectives, suppresses nothing.
+				until, err := expiry(strings.Fields(strings.TrimPrefix(c.Text, ignoreDirective)))
+				return until, err == nil
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// isIgnoreDirective reports whether the comment c is ignoreDirective,
+// followed by a space or nothing.
+func isIgnoreDirective(c *ast.Comment) bool {
+	if !strings.HasPrefix(c.Text, ignoreDirective) {
+		return false
+	}
+	rest := c.Text[len(ignoreDirective):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// checkIgnoreDirectives reports the ignoreDirective comments in the
+// files of pass whose expiries aren't dates.
+func checkIgnoreDirectives(pass *analysis.Pass) {
+	for _, f := range pass.Files {
+		for _, g := range f.Comments {
+			for _, c := range g.List {
+				if !isIgnoreDirective(c) {
+					continue
+				}
+				if _, err := expiry(strings.Fields(strings.TrimPrefix(c.Text, ignoreDirective))); err != nil {
+					report(pass, c.Pos(), "this suppression has a malformed expiry: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// expiry returns the date given by the until=YYYY-MM-DD field in fields,
+// or the zero time if there is none.
+func expiry(fields []string) (time.Time, error) {
+	for _, f := range fields {
+		if strings.HasPrefix(f, "until=") {
+			t, err := time.Parse(dateLayout, strings.TrimPrefix(f, "until="))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%s: want until=YYYY-MM-DD", f)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// baselines caches the entries of the baseline files by their names.
+var baselines = struct {
+	sync.Mutex
+	m map[string]map[string]time.Time
+}{m: make(map[string]map[string]time.Time)}
+
+// loadBaseline reads the baseline file, if any, unless it was read.
+func loadBaseline() error {
+	if baselineFile == "" {
+		return nil
+	}
+	baselines.Lock()
+	defer baselines.Unlock()
+	if _, ok := baselines.m[baselineFile]; ok {
+		return nil
+	}
+	entries, err := readBaseline(baselineFile)
+	if err != nil {
+		return err
+	}
+	baselines.m[baselineFile] = entries
+	return nil
+}
+
+// baselineExpiry returns the expiry of the baseline entry of the
+// fingerprint fp, which is zero if it doesn't expire.
+func baselineExpiry(fp string) (time.Time, bool) {
+	if baselineFile == "" {
+		return time.Time{}, false
+	}
+	baselines.Lock()
+	defer baselines.Unlock()
+	until, ok := baselines.m[baselineFile][fp]
+	return until, ok
+}
+
+// readBaseline returns the expiries of the fingerprints listed in the
+// baseline file name. Lines starting with # are comments.
+func readBaseline(name string) (map[string]time.Time, error) {
+	entries := make(map[string]time.Time)
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		until, err := expiry(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		entries[fields[0]] = until
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return entries, nil
+}
+
+// argDirectives maps the passes being run to the argDirective comments in
+// the files of their packages.
+var argDirectives = struct {
+	sync.Mutex
+	m map[*analysis.Pass][]*ast.Comment
+}{m: make(map[*analysis.Pass][]*ast.Comment)}
+
+// collectArgDirectives records the argDirective comments in the files of
+// pass. It returns a function which forgets the comments.
+func collectArgDirectives(pass *analysis.Pass) func() {
+	var comments []*ast.Comment
+	for _, f := range pass.Files {
+		for _, g := range f.Comments {
+			for _, c := range g.List {
+				if isArgDirective(c) {
+					comments = append(comments, c)
+				}
+			}
+		}
+	}
+	argDirectives.Lock()
+	defer argDirectives.Unlock()
+	argDirectives.m[pass] = comments
+	return func() {
+		argDirectives.Lock()
+		defer argDirectives.Unlock()
+		delete(argDirectives.m, pass)
+	}
+}
+
+// isArgDirective reports whether the comment c begins with argDirective
+// followed by a space or nothing.
+func isArgDirective(c *ast.Comment) bool {
+	text := strings.TrimPrefix(strings.TrimPrefix(c.Text, "//"), "/*")
+	text = strings.TrimSuffix(strings.TrimSpace(text), "*/")
+	if !strings.HasPrefix(text, argDirective) {
+		return false
+	}
+	rest := text[len(argDirective):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// argSuppressed reports whether the i-th argument of the call c, counting
+// the receiver of a method call as the first, has a comment beginning with
+// argDirective attached to it by argOf.
+func argSuppressed(pass *analysis.Pass, c ssa.CallInstruction, i int) bool {
+	call := directiveCall(pass, c)
+	if call == nil {
+		return false
+	}
+	// The receiver of a method call is the first argument of c.
+	var from, to token.Pos
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if isSel && len(c.Common().Args) == len(call.Args)+1 {
+		if i == 0 {
+			from, to = sel.X.Pos(), sel.Sel.Pos()
+		}
+		i--
+	}
+	if i >= 0 {
+		// The variadic arguments are packed into a slice.
+		sig := c.Common().Signature()
+		if i >= len(call.Args) || sig.Variadic() && !call.Ellipsis.IsValid() && i == sig.Params().Len()-1 {
+			return false
+		}
+		return argHasDirective(pass, call, i)
+	}
+	return hasDirective(pass, from, to)
+}
+
+// varargSuppressed reports whether the k-th of the variadic arguments of
+// the call c, which are packed into a slice, has a comment beginning
+// with argDirective as argSuppressed does.
+func varargSuppressed(pass *analysis.Pass, c ssa.CallInstruction, k int) bool {
+	call := directiveCall(pass, c)
+	if call == nil || call.Ellipsis.IsValid() {
+		return false
+	}
+	i := c.Common().Signature().Params().Len() - 1 + k
+	if i >= len(call.Args) {
+		return false
+	}
+	return argHasDirective(pass, call, i)
+}
+
+// directiveCall returns the call expression of c, if there are comments
+// beginning with argDirective in the package of pass at all.
+func directiveCall(pass *analysis.Pass, c ssa.CallInstruction) *ast.CallExpr {
+	argDirectives.Lock()
+	n := len(argDirectives.m[pass])
+	argDirectives.Unlock()
+	if n == 0 {
+		return nil
+	}
+	node, _ := Node(pass.Files, c)
+	switch node := node.(type) {
+	case *ast.CallExpr:
+		return node
+	case *ast.GoStmt:
+		return node.Call
+	case *ast.DeferStmt:
+		return node.Call
+	}
+	return nil
+}
+
+// argOf returns the index of the argument of call to which the comment
+// at pos, between its parentheses, is attached. A comment between two
+// arguments is attached to the one on its line, or to the nearest one,
+// so that the comments in
+//
+//	f(a /* nilarg: a */, b)
+//	f(
+//		a, // nilarg: a
+//		b,
+//	)
+//
+// are on a only.
+func argOf(fset *token.FileSet, call *ast.CallExpr, c *ast.Comment) int {
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	for j, arg := range call.Args {
+		if c.Pos() >= arg.Pos() {
+			if c.Pos() < arg.End() {
+				return j
+			}
+			continue
+		}
+		if j == 0 {
+			return 0
+		}
+		prev := call.Args[j-1].End()
+		onPrev, onNext := line(c.Pos()) == line(prev), line(c.End()) == line(arg.Pos())
+		if onPrev != onNext {
+			if onPrev {
+				return j - 1
+			}
+			return j
+		}
+		if c.Pos()-prev < arg.Pos()-c.End() {
+			return j - 1
+		}
+		return j
+	}
+	return len(call.Args) - 1
+}
+
+// argHasDirective reports whether a comment beginning with argDirective
+// is attached to the i-th argument of call.
+func argHasDirective(pass *analysis.Pass, call *ast.CallExpr, i int) bool {
+	argDirectives.Lock()
+	comments := argDirectives.m[pass]
+	argDirectives.Unlock()
+	for _, cm := range comments {
+		if call.Lparen < cm.Pos() && cm.Pos() < call.Rparen && argOf(pass.Fset, call, cm) == i {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDirective reports whether a comment beginning with argDirective
+// is in the span [from, to).
+func hasDirective(pass *analysis.Pass, from, to token.Pos) bool {
+	argDirectives.Lock()
+	comments := argDirectives.m[pass]
+	argDirectives.Unlock()
+	for _, cm := range comments {
+		if from <= cm.Pos() && cm.Pos() < to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package nilarg
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// reportMode is how a kind of findings is reported:
+//
+//	report   as the other findings
+//	category with a category of the kind, for the tools filtering findings
+//	off      not at all
+type reportMode string
+
+func (m *reportMode) String() string { return string(*m) }
+
+func (m *reportMode) Set(s string) error {
+	switch s {
+	case "report", "category", "off":
+		*m = reportMode(s)
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q: want report, category or off", s)
+}
+
+// report reports d as m says, with the category c for "category".
+func (m reportMode) report(pass *analysis.Pass, d analysis.Diagnostic, c string) {
+	switch m {
+	case "off":
+		return
+	case "category":
+		d.Category = c
+	}
+	reportDiag(pass, d)
+}
+
+// testAsserts is how the findings of single-result type assertions in
+// test files are reported, as tests often assert the types of mocks
+// which only a broken test can get wrong.
+var testAsserts = reportMode("report")
+
+func init() {
+	Analyzer.Flags.Var(&testAsserts, "test-type-asserts",
+		"report the panics of type assertions in _test.go files as the others (report), with the category "+testAssertCategory+" (category), or not at all (off)")
+}
+
+// testAssertCategory is the category of the findings of type assertions
+// in test files with -test-type-asserts category.
+const testAssertCategory = "test-type-assert"
+
+// reportIn reports d, a finding in fn of a panic described by what, such
+// as "type asserted", as -test-type-asserts says for type assertions in
+// test files.
+func reportIn(pass *analysis.Pass, fn *ssa.Function, what string, d analysis.Diagnostic) {
+	if !strings.HasPrefix(what, "type asserted") || !isTestFunc(pass, fn) {
+		reportDiag(pass, d)
+		return
+	}
+	testAsserts.report(pass, d, testAssertCategory)
+}
@@ -0,0 +1,162 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// isTestFunc reports whether fn is declared in a _test.go file.
+func isTestFunc(pass *analysis.Pass, fn *ssa.Function) bool {
+	return strings.HasSuffix(pass.Fset.Position(fn.Pos()).Filename, "_test.go")
+}
+
+// nilTestCase describes the first case of a table-driven test whose
+// field loaded by v is nil, as in
+//
+//	tests := []struct{ p *T }{{nil}, {&T{}}}
+//	for _, tt := range tests {
+//		f(tt.p)
+//	}
+//
+// It returns "" if v isn't a nillable field of an element of a slice
+// literal or no case has the field nil. Elements given as whole values,
+// such as the results of calls, have unknown fields and are never nil.
+func nilTestCase(v ssa.Value) string {
+	x, field, ok := fieldLoad(v)
+	if !ok {
+		return ""
+	}
+	ia := elementAddr(x)
+	if ia == nil {
+		return ""
+	}
+	slice, ok := ia.X.(*ssa.Slice)
+	if !ok {
+		return ""
+	}
+	lit, ok := slice.X.(*ssa.Alloc)
+	if !ok || lit.Referrers() == nil {
+		return ""
+	}
+	arr, ok := lit.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array)
+	if !ok {
+		return ""
+	}
+	st := structOf(arr.Elem())
+	if st == nil || !isNillable(st.Field(field).Type()) {
+		return ""
+	}
+	for i := int64(0); i < arr.Len(); i++ {
+		name := fmt.Sprintf("%d", i)
+		elem := literalElement(lit, i)
+		if elem != nil {
+			name = caseName(elem, st, name)
+			if val, known := elemField(elem, field); !known || val != nil && !isNil(val) {
+				continue
+			}
+		}
+		return fmt.Sprintf("test case %s has nil %s", name, st.Field(field).Name())
+	}
+	return ""
+}
+
+// elementAddr returns the address of the slice element which x is or
+// is copied from.
+func elementAddr(x ssa.Value) *ssa.IndexAddr {
+	switch x := x.(type) {
+	case *ssa.IndexAddr:
+		return x
+	case *ssa.UnOp:
+		ia, _ := x.X.(*ssa.IndexAddr)
+		return ia
+	case *ssa.Alloc:
+		// the range variable the element is copied to
+		var ia *ssa.IndexAddr
+		for _, r := range *x.Referrers() {
+			if st, ok := r.(*ssa.Store); ok && st.Addr == x {
+				load, ok := st.Val.(*ssa.UnOp)
+				if !ok || ia != nil {
+					return nil
+				}
+				ia, _ = load.X.(*ssa.IndexAddr)
+			}
+		}
+		return ia
+	}
+	return nil
+}
+
+// literalElement returns the address of the i-th element of the array
+// literal lit, or nil if the element isn't set.
+func literalElement(lit *ssa.Alloc, i int64) *ssa.IndexAddr {
+	for _, r := range *lit.Referrers() {
+		ia, ok := r.(*ssa.IndexAddr)
+		if !ok {
+			continue
+		}
+		if c, ok := ia.Index.(*ssa.Const); ok && c.Int64() == i {
+			return ia
+		}
+	}
+	return nil
+}
+
+// elemField returns the value stored to the field of the element elem,
+// or nil if the literal leaves it out, and whether the value is known.
+// It isn't for an element stored as a whole, unless it's the address of
+// a struct literal, as in []*T{{p: nil}}.
+func elemField(elem *ssa.IndexAddr, field int) (ssa.Value, bool) {
+	if elem.Referrers() == nil {
+		return nil, true
+	}
+	for _, r := range *elem.Referrers() {
+		if st, ok := r.(*ssa.Store); ok && st.Addr == elem {
+			lit, ok := st.Val.(*ssa.Alloc)
+			if !ok {
+				return nil, false
+			}
+			return storedField(lit, field), true
+		}
+	}
+	return storedField(elem, field), true
+}
+
+// storedField returns the value stored to the field of the struct at
+// addr, or nil.
+func storedField(addr ssa.Value, field int) ssa.Value {
+	if addr.Referrers() == nil {
+		return nil
+	}
+	for _, r := range *addr.Referrers() {
+		fa, ok := r.(*ssa.FieldAddr)
+		if !ok || fa.Field != field || fa.Referrers() == nil {
+			continue
+		}
+		for _, fr := range *fa.Referrers() {
+			if st, ok := fr.(*ssa.Store); ok && st.Addr == fa {
+				return st.Val
+			}
+		}
+	}
+	return nil
+}
+
+// caseName returns the quoted name field of the test case elem, or def
+// if the struct st has no constant name.
+func caseName(elem *ssa.IndexAddr, st *types.Struct, def string) string {
+	for i := 0; i < st.NumFields(); i++ {
+		if !strings.EqualFold(st.Field(i).Name(), "name") {
+			continue
+		}
+		v, _ := elemField(elem, i)
+		if c, ok := v.(*ssa.Const); ok && c.Value != nil && c.Value.Kind() == constant.String {
+			return c.Value.ExactString()
+		}
+	}
+	return def
+}
@@ -0,0 +1,15 @@
+package fingerprint // want package:"&{}"
+
+// The same package as testdata/src/fingerprint with the findings moved
+// to other lines.
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func use() int {
+
+	return get(nil) // want "this call can cause panic"
+}
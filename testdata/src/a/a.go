@@ -48,7 +48,7 @@ func f5(x *int, ptr *[3]int, i interface{}, m map[int]int) {
 	}
 }
 
-func f6(i interface{}) interface{ f() } {
+func f6(i interface{}) interface{ f() } { // want f6:"nilReturns\\[0\\]"
 	i2, ok := i.(interface{ f() })
 	if ok {
 		return i2
@@ -78,7 +78,7 @@ func f10(b *bytes.Buffer) { // want f10:"&map\\[0:{}\\]"
 	b.Bytes()
 }
 
-func f11(i interface{}) interface{ f() } {
+func f11(i interface{}) interface{ f() } { // want f11:"nilReturns\\[0\\]"
 	if i != nil {
 		if true {
 			if true {
@@ -92,7 +92,7 @@ func f11(i interface{}) interface{ f() } {
 type s struct {
 	vars []*int
 }
-func (x *s) At(i int) *int { return x.vars[i] } // want At:"&map\\[0:{}\\]"
+func (x *s) At(i int) *int { return x.vars[i] } // want At:"&map\\[0:{}\\]" At:"panicFields\\[0.vars\\]"
 func f12(r *int, params *s) { // want f12:"&map\\[1:{}\\]"
 	_ = params.At(1)
 }
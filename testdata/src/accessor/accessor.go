@@ -0,0 +1,27 @@
+package accessor // want package:"&{}"
+
+type T struct{ n int }
+
+func (t *T) N() int { // want N:"&map\\[0:{}\\]"
+	return t.n
+}
+
+type S struct{ t *T }
+
+func (s *S) T() *T { // want T:"accessor\\(0\\)" T:"&map\\[0:{}\\]"
+	return s.t
+}
+
+func f(s *S) int { // want f:"&map\\[0:{}\\]"
+	if s.T() == nil {
+		return s.T().N() // want "this call can cause panic"
+	}
+	return s.T().N()
+}
+
+func g(s *S) int { // want g:"&map\\[0:{}\\]"
+	if s.t == nil {
+		return s.T().N() // want "this call can cause panic"
+	}
+	return s.T().N()
+}
@@ -0,0 +1,49 @@
+package addrtaken // want package:"&{}"
+
+type T struct{ x int }
+
+// show only reads through pp.
+func show(pp **T) bool { // want show:"&map\\[0:{}\\]"
+	return *pp != nil
+}
+
+// reset writes through pp.
+func reset(pp **T) { // want reset:"&map\\[0:{}\\]"
+	*pp = &T{}
+}
+
+func spilled(p *T) int { // want spilled:"&map\\[0:{}\\]"
+	show(&p)
+	return p.x
+}
+
+func pointer(p *T) int { // want pointer:"&map\\[0:{}\\]"
+	q := &p
+	show(q)
+	return (*q).x
+}
+
+func copied(p *T) int { // want copied:"&map\\[0:{}\\]"
+	c := p
+	show(&c)
+	return c.x
+}
+
+// written may have p replaced by reset.
+func written(p *T) int {
+	reset(&p)
+	return p.x
+}
+
+func checked(p *T) int {
+	show(&p)
+	if p == nil {
+		return 0
+	}
+	return p.x
+}
+
+func use() {
+	spilled(nil) // want "this call can cause panic"
+	written(nil)
+}
@@ -0,0 +1,39 @@
+package alias // want package:"&{}"
+
+type T struct{ x int }
+
+type U T
+
+type Reader interface{ Read() }
+
+type Closer interface{ Close() }
+
+func copied(p *T) int { // want copied:"&map\\[0:{}\\]"
+	q := p
+	return q.x
+}
+
+func converted(p *T) int { // want converted:"&map\\[0:{}\\]"
+	q := (*U)(p)
+	return q.x
+}
+
+func asserted(r Reader) { // want asserted:"&map\\[0:{}\\]"
+	var i interface{} = r
+	i.(Closer).Close()
+}
+
+func checked(p *T) int {
+	if p == nil {
+		return 0
+	}
+	q := (*U)(p)
+	return q.x
+}
+
+func use() {
+	copied(nil)    // want "this call can cause panic: p is dereferenced in copied"
+	converted(nil) // want "this call can cause panic: p is dereferenced in converted"
+	asserted(nil)  // want "this call can cause panic: r is type asserted in asserted"
+	checked(nil)
+}
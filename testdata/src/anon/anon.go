@@ -0,0 +1,43 @@
+package anon // want package:"&{}"
+
+type T struct{ x int }
+
+func deref(p *T) int { // want deref:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func local() int {
+	get := func(p *T) int { return p.x }
+	return get(nil) // want "this call can cause panic: p is dereferenced"
+}
+
+func nested() {
+	func() {
+		deref(nil) // want "this call can cause panic"
+	}()
+}
+
+func captured(n int) int {
+	inc := func(p *T) int { return p.x + n }
+	return inc(nil) // want "this call can cause panic"
+}
+
+func chained() int {
+	get := func(p *T) int { return deref(p) }
+	return get(nil) // want "this call can cause panic"
+}
+
+func checked() int {
+	get := func(p *T) int {
+		if p == nil {
+			return 0
+		}
+		return p.x
+	}
+	return get(nil)
+}
+
+func outer(p *T) int { // want outer:"&map\\[0:{}\\]"
+	get := func(q *T) int { return q.x }
+	return get(p)
+}
@@ -0,0 +1,63 @@
+package argcomment // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func (t *T) add(p *T) int { // want add:"&map\\[0:{} 1:{}\\]"
+	return t.x + p.x
+}
+
+func both(p, q *T) int { // want both:"&map\\[0:{} 1:{}\\]"
+	return p.x + q.x
+}
+
+func valid(p *T) bool {
+	return p != nil
+}
+
+// checked only calls get with p which valid checked, which the analyzer
+// can't see.
+func checked(p *T) int {
+	if !valid(p) {
+		return 0
+	}
+	return get(p /* nilarg: checked by valid */)
+}
+
+// unchecked has no comment on the argument.
+func unchecked(p *T) int { // want unchecked:"&map\\[0:{}\\]"
+	if !valid(p) {
+		return 0
+	}
+	return get(p)
+}
+
+// ignored has a //nilarg:ignore comment on the argument, which isn't a
+// comment beginning with nilarg: and keeps the fact.
+func ignored(p *T) int { // want ignored:"&map\\[0:{}\\]"
+	return get(
+		p, //nilarg:ignore
+	)
+}
+
+func use(t *T) { // want use:"&map\\[0:{}\\]"
+	get(nil /* nilarg: never reached in production */)
+	get( /* nilarg: before the argument */ nil)
+	get(nil) // want "this call can cause panic: p is dereferenced in get"
+	t.add(nil /* nilarg: t.add is fine here */)
+	t.add(nil) // want "this call can cause panic: p is dereferenced in add"
+	var nt *T
+	nt. /* nilarg: the receiver */ add(t)
+	nt.add(t /* nilarg: not the receiver */) // want "this call can cause panic: t is dereferenced in add"
+	both(nil /* nilarg: only p */, nil)      // want "this call can cause panic: q is dereferenced in both"
+	both(nil, nil /* nilarg: only q */)      // want "this call can cause panic: p is dereferenced in both"
+	both(                                    // want "this call can cause panic: q is dereferenced in both"
+		nil, // nilarg: only p
+		nil,
+	)
+	checked(nil)
+	unchecked(nil) // want "this call can cause panic: p is passed to get in unchecked"
+}
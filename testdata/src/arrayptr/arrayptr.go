@@ -0,0 +1,42 @@
+package arrayptr // want package:"&{}"
+
+func length(p *[4]int) int {
+	return len(p) + cap(p)
+}
+
+func indices(p *[4]int) int {
+	n := 0
+	for i := range p {
+		n += i
+	}
+	return n
+}
+
+func values(p *[4]int) int { // want values:"&map\\[0:{}\\]"
+	n := 0
+	for _, v := range p {
+		n += v
+	}
+	return n
+}
+
+func empty(p *[4]int) []int { // want empty:"&map\\[0:{}\\]"
+	return p[:0]
+}
+
+func whole(p *[0]int) []int { // want whole:"&map\\[0:{}\\]"
+	return p[:]
+}
+
+func index(p *[4]int) int { // want index:"&map\\[0:{}\\]"
+	return p[1]
+}
+
+func use() {
+	length(nil)
+	indices(nil)
+	values(nil) // want "this call can cause panic: p is indexed in values"
+	empty(nil)  // want "this call can cause panic: p is sliced in empty"
+	whole(nil)  // want "this call can cause panic: p is sliced in whole"
+	index(nil)  // want "this call can cause panic: p is indexed in index"
+}
@@ -0,0 +1,60 @@
+package bound // want package:"&{}"
+
+type T struct{ x int }
+
+type S struct{ n int }
+
+func (s *S) add(t *T) { s.n += t.x } // want add:"&map\\[0:{} 1:{}\\]"
+
+type E struct{ *S }
+
+// value passes p to a method value of s, which dereferences s too.
+func value(s *S, p *T) { // want value:"&map\\[0:{} 1:{}\\]"
+	f := s.add
+	f(p)
+}
+
+// promoted passes p to a method value promoted from the embedded S.
+func promoted(e E, p *T) { // want promoted:"&map\\[1:{}\\]"
+	f := e.add
+	f(p)
+}
+
+// expr passes p to a method expression, whose thunk takes the receiver
+// first like the method.
+func expr(s *S, p *T) { // want expr:"&map\\[0:{} 1:{}\\]"
+	f := (*S).add
+	f(s, p)
+}
+
+type hooks struct{ add func(*T) }
+
+// stored passes p to a method value stored in a struct.
+func stored(s *S, p *T) { // want stored:"&map\\[0:{} 1:{}\\]"
+	h := hooks{add: s.add}
+	h.add(p)
+}
+
+func apply(f func(*T), p *T) { // want apply:"&map\\[0:{}\\]"
+	f(p)
+}
+
+// passed passes a method value of s to a function which calls it.
+func passed(s *S, p *T) { // want passed:"&map\\[0:{}\\]"
+	apply(s.add, p)
+}
+
+func checked(s *S, p *T) { // want checked:"&map\\[0:{}\\]"
+	f := s.add
+	if p != nil {
+		f(p)
+	}
+}
+
+func use(s *S) { // want use:"&map\\[0:{}\\]"
+	value(s, nil) // want "this call can cause panic"
+	f := s.add
+	f(nil) // want "this call can cause panic"
+	h := hooks{add: s.add}
+	h.add(nil) // want "this call can cause panic"
+}
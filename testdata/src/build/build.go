@@ -0,0 +1,7 @@
+package build // want package:"&{}"
+
+type T struct{ x int }
+
+func use() int {
+	return get(nil) // want "this call can cause panic"
+}
@@ -0,0 +1,8 @@
+//go:build !nilarg_never
+// +build !nilarg_never
+
+package build
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
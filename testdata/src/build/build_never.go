@@ -0,0 +1,17 @@
+//go:build nilarg_never
+// +build nilarg_never
+
+package build
+
+// get is excluded by the build constraint, so it must neither export a
+// fact nor suppress the diagnostic of the call in build.go.
+func get(p *T) int {
+	if p == nil {
+		return 0
+	}
+	return p.x
+}
+
+func never() int {
+	return get(nil)
+}
@@ -0,0 +1,36 @@
+package callback // want package:"&{}"
+
+func run(cb func()) { // want run:"&map\\[0:{}\\]"
+	cb()
+}
+
+func runChecked(cb func()) {
+	if cb != nil {
+		cb()
+	}
+}
+
+func each(xs []int, f func(int)) { // want each:"conditionalArgs\\[1:\\[len\\(0\\)>0\\]\\]"
+	for _, x := range xs {
+		f(x)
+	}
+}
+
+func forward(cb func()) { // want forward:"&map\\[0:{}\\]"
+	run(cb)
+}
+
+func fallback(cb func()) { // want fallback:"&map\\[0:{}\\]"
+	if cb == nil {
+		cb() // want "this call can cause panic: cb is nil"
+	}
+}
+
+func use() {
+	run(nil) // want "this call can cause panic: cb is called in run"
+	run(func() {})
+	runChecked(nil)
+	each(nil, nil)
+	each([]int{1}, nil) // want "this call can cause panic because len\\(xs\\) > 0"
+	forward(nil)        // want "this call can cause panic: cb is passed to run in forward"
+}
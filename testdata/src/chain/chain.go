@@ -0,0 +1,44 @@
+package chain // want package:"&{}"
+
+type T struct{ x int }
+
+func handleNil() {}
+
+func elseBranch(p *T) int {
+	if p == nil {
+		handleNil()
+	} else {
+		return p.x
+	}
+	return 0
+}
+
+func elseIf(p *T, ok bool) int {
+	if p == nil {
+		handleNil()
+	} else if ok {
+		return p.x
+	} else {
+		return -p.x
+	}
+	return 0
+}
+
+func ladder(p, q *T) int {
+	if q == nil {
+		handleNil()
+	} else if p == nil {
+		handleNil()
+	} else {
+		return p.x + q.x
+	}
+	return 0
+}
+
+// fallThrough can cause panic because the nil branch doesn't return.
+func fallThrough(p *T) int { // want fallThrough:"&map\\[0:{}\\]"
+	if p == nil {
+		handleNil()
+	}
+	return p.x
+}
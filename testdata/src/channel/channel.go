@@ -0,0 +1,40 @@
+package channel // want package:"&{}"
+
+type T struct{ x int }
+
+func produce(ch chan<- *T) {
+	ch <- &T{}
+	ch <- nil
+}
+
+func consume() int {
+	ch := make(chan *T)
+	go produce(ch)
+	v := <-ch // want "the value received from this channel can be nil: nil is sent at channel.go:7"
+	return v.x
+}
+
+func consumeChecked() int {
+	ch := make(chan *T)
+	go produce(ch)
+	if v := <-ch; v != nil {
+		return v.x
+	}
+	return 0
+}
+
+func closure() int {
+	ch := make(chan *T, 1)
+	go func() { ch <- nil }()
+	sum := 0
+	for v := range ch { // want "the value received from this channel can be nil: nil is sent at channel.go:28"
+		sum += v.x
+	}
+	return sum
+}
+
+func nonNil() int {
+	ch := make(chan *T, 1)
+	ch <- &T{}
+	return (<-ch).x
+}
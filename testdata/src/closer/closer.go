@@ -0,0 +1,27 @@
+package closer // want package:"&{}"
+
+func stop(done chan struct{}) { // want stop:"&map\\[0:{}\\]"
+	close(done)
+}
+
+func stopChecked(done chan struct{}) {
+	if done != nil {
+		close(done)
+	}
+}
+
+// send blocks forever on nil ch instead of panicking.
+func send(ch chan<- int) {
+	ch <- 1
+}
+
+func shutdown(done chan struct{}) { // want shutdown:"&map\\[0:{}\\]"
+	stop(done)
+}
+
+func use() {
+	stop(nil) // want "this call can cause panic: done is closed in stop"
+	stop(make(chan struct{}))
+	stopChecked(nil)
+	shutdown(nil) // want "this call can cause panic: done is passed to stop in shutdown"
+}
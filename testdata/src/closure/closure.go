@@ -0,0 +1,33 @@
+package closure // want package:"&{}"
+
+type T struct{ x int }
+
+func use(p *T) int { // want use:"&map\\[0:{}\\]"
+	return p.x
+}
+
+// A panics only when the returned closure is called.
+func A(p *T) func() int { // want A:"&map\\[0:{}\\]"
+	return func() int { return use(p) }
+}
+
+// B calls the closure immediately.
+func B(p *T) { // want B:"&map\\[0:{}\\]"
+	func() { _ = *p }()
+}
+
+// C checks p in the closure.
+func C(p *T) func() int {
+	return func() int {
+		if p == nil {
+			return 0
+		}
+		return p.x
+	}
+}
+
+// D never uses the closure.
+func D(p *T) {
+	f := func() int { return p.x }
+	_ = f
+}
@@ -0,0 +1,31 @@
+package combined // want package:"&{}"
+
+type Logger struct{ n int }
+
+func (l *Logger) Log() { // want Log:"&map\\[0:{}\\]"
+	l.n++
+}
+
+type config struct{ log *Logger }
+
+type Option func(*config)
+
+func WithLogger(l *Logger) Option { // want WithLogger:"optionFields\\[combined.config.log\\]"
+	return func(c *config) { c.log = l }
+}
+
+// New dereferences name, so calling it with nil and without options is
+// reported twice unless the diagnostics are combined.
+func New(name *string, opts ...Option) { // want New:"&map\\[0:{}\\]" New:"requiredOptions\\[combined.config.log\\]" New:"variadicElems\\[all\\]"
+	_ = *name
+	c := &config{}
+	for _, o := range opts {
+		o(c)
+	}
+	c.log.Log()
+}
+
+func use(l *Logger) {
+	New(nil) // want "this call can cause panic: no option sets combined.config.log; name is dereferenced in New$"
+	New(new(string), WithLogger(l))
+}
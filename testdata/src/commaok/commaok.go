@@ -0,0 +1,60 @@
+package commaok // want package:"&{}"
+
+type T struct{ n int }
+
+func (t *T) N() int { return t.n } // want N:"&map\\[0:{}\\]"
+
+func get(t *T) int { // want get:"&map\\[0:{}\\]"
+	return t.n
+}
+
+func call(f func()) { // want call:"&map\\[0:{}\\]"
+	f()
+}
+
+func lookup(m map[string]*T) int {
+	t, ok := m["a"]
+	if !ok {
+		return get(t) // want "this call can cause panic"
+	}
+	return get(t)
+}
+
+func assert(x interface{}) int {
+	t, ok := x.(*T)
+	if ok {
+		return get(t)
+	}
+	return get(t) // want "this call can cause panic"
+}
+
+func receive(c chan func()) {
+	f, ok := <-c
+	if ok {
+		call(f)
+		return
+	}
+	call(f) // want "this call can cause panic"
+}
+
+type namer interface{ N() int }
+
+func iface(x interface{}) int {
+	n, ok := x.(namer)
+	if !ok {
+		return 0
+	}
+	if n == nil {
+		// Unreachable: a successful assertion to an interface isn't nil.
+		return get(nil)
+	}
+	return n.N()
+}
+
+func values(m map[string]int) int {
+	n, ok := m["a"]
+	if !ok {
+		return n
+	}
+	return n + 1
+}
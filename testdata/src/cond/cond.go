@@ -0,0 +1,62 @@
+package cond // want package:"&{}"
+
+func fill(p *[8]int, n int) { // want fill:"conditionalArgs\\[0:\\[1>0\\]\\]"
+	for i := 0; i < n; i++ {
+		p[i] = i
+	}
+}
+
+func first(p *int, n int) int { // want first:"conditionalArgs\\[0:\\[1!=0\\]\\]"
+	if n == 0 {
+		return 0
+	}
+	return *p
+}
+
+func always(p *int, n int) int { // want always:"&map\\[0:{}\\]"
+	if n > 0 {
+		return *p
+	}
+	return -*p
+}
+
+func encode(dst, src []byte) { // want encode:"conditionalArgs\\[0:\\[len\\(1\\)>0\\]\\]"
+	for i := range src {
+		dst[i] = src[i]
+	}
+}
+
+func decode(dst *[4]byte, src string) { // want decode:"conditionalArgs\\[0:\\[len\\(1\\)!=0\\]\\]"
+	if len(src) == 0 {
+		return
+	}
+	dst[0] = src[0]
+}
+
+// pair can panic on both nil p and nil q under conditions on different
+// parameters, and the call satisfying both is reported for p.
+func pair(p, q *int, m, n int) int { // want pair:"conditionalArgs\\[0:\\[2>0\\] 1:\\[3>0\\]\\]"
+	s := 0
+	if n > 0 {
+		s += *q
+	}
+	if m > 0 {
+		s += *p
+	}
+	return s
+}
+
+func use(n int) {
+	fill(nil, 0)
+	fill(nil, 3) // want "this call can cause panic because n > 0"
+	fill(nil, n)
+	first(nil, 0)
+	first(nil, 1) // want "this call can cause panic because n != 0"
+	encode(nil, nil)
+	encode(nil, []byte{})
+	encode(nil, []byte{1})       // want "this call can cause panic because len\\(src\\) > 0"
+	encode(nil, make([]byte, 3)) // want "this call can cause panic because len\\(src\\) > 0"
+	decode(nil, "")
+	decode(nil, "ab")    // want "this call can cause panic because len\\(src\\) != 0"
+	pair(nil, nil, 1, 1) // want "this call can cause panic because m > 0"
+}
@@ -0,0 +1,16 @@
+package contracts // want package:"&{}"
+
+type T struct{ x int }
+
+func (t *T) X() int { // want X:"&map\\[0:{}\\]" "callers of T.X can cause panic by passing nil t: must not be nil: dereferenced at contracts.go:6"
+	return t.x
+}
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]" "callers of get can cause panic by passing nil p: must not be nil: passed to X at contracts.go:10"
+	return p.X()
+}
+
+// use isn't reported with -contracts.
+func use() int {
+	return get(nil)
+}
@@ -0,0 +1,55 @@
+package convergence // want package:"&{}"
+
+type T struct{ x int }
+
+// The callers come before their callees, so that each round of the
+// fixpoint only finds the facts of one more level of the chain.
+
+func a(p *T) { // want a:"&map\\[0:{}\\]"
+	b(p)
+}
+
+func b(p *T) { // want b:"&map\\[0:{}\\]"
+	c(p)
+}
+
+func c(p *T) { // want c:"&map\\[0:{}\\]"
+	d(p)
+}
+
+func d(p *T) { // want d:"&map\\[0:{}\\]"
+	e(p)
+}
+
+func e(p *T) { // want e:"&map\\[0:{}\\]"
+	p.x++
+}
+
+// pong and ping call each other.
+
+func pong(p *T, n int) { // want pong:"&map\\[0:{}\\]"
+	if n > 0 {
+		ping(p, n-1)
+	}
+}
+
+func ping(p *T, n int) { // want ping:"&map\\[0:{}\\]"
+	p.x++
+	if n > 0 {
+		pong(p, n-1)
+	}
+}
+
+type Node struct{ next *Node }
+
+// walk calls itself.
+func walk(n *Node) { // want walk:"&map\\[0:{}\\]"
+	if n.next != nil {
+		walk(n.next)
+	}
+}
+
+func use() {
+	a(nil)       // want "this call can cause panic"
+	pong(nil, 1) // want "this call can cause panic"
+}
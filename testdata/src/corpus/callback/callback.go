@@ -0,0 +1,20 @@
+package callback // want package:"&{}"
+
+// This case reproduces the panic of calling a hook which is
+// optional for the callers, as in the event handlers of watchers invoked
+// without checking that they were registered.
+
+type Event struct{ Path string }
+
+func notify(e Event, onEvent func(Event)) { // want notify:"&map\\[1:{}\\]"
+	onEvent(e)
+}
+
+func rename(from, to string) {
+	notify(Event{Path: to}, nil) // want "this call can cause panic: onEvent is called in notify"
+}
+
+// renameFixed is rename as fixed, passing a no-op hook.
+func renameFixed(from, to string) {
+	notify(Event{Path: to}, func(Event) {})
+}
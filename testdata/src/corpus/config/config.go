@@ -0,0 +1,27 @@
+package config // want package:"&{}"
+
+// This case reproduces the panic of passing an unset field of a
+// configuration to a helper which uses it, as in the loggers of
+// libraries configured with a struct whose fields are optional.
+
+type Logger struct{ prefix string }
+
+type Options struct {
+	Logger *Logger
+	Name   string
+}
+
+func logf(l *Logger, msg string) string { // want logf:"&map\\[0:{}\\]"
+	return l.prefix + msg
+}
+
+func start() string {
+	opts := Options{Name: "server"}
+	return logf(opts.Logger, "starting") // want "this call can cause panic: l is dereferenced in logf"
+}
+
+// startFixed is start as fixed, setting the logger.
+func startFixed() string {
+	opts := Options{Logger: &Logger{}, Name: "server"}
+	return logf(opts.Logger, "starting")
+}
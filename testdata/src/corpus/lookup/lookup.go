@@ -0,0 +1,28 @@
+package lookup // want package:"&{}"
+
+// This case reproduces the panic of dereferencing the result of a
+// lookup which returns nil for unknown keys, as in the registries of
+// plugins resolved by name from configuration.
+
+type Plugin struct{ Name string }
+
+var plugins = map[string]*Plugin{}
+
+func find(name string) *Plugin { // want find:"nilReturns\\[0\\]"
+	if p, ok := plugins[name]; ok {
+		return p
+	}
+	return nil
+}
+
+func nameOf(key string) string {
+	return find(key).Name // want "the result of find can be nil"
+}
+
+// nameOfFixed is nameOf as fixed, checking the result first.
+func nameOfFixed(key string) string {
+	if p := find(key); p != nil {
+		return p.Name
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package mapentry // want package:"&{}"
+
+// This case reproduces the panic of using the value of a failed
+// map lookup, as in the caches of clients which fall through to the
+// handling of a hit when the entry is missing.
+
+type Session struct{ user string }
+
+func user(s *Session) string { // want user:"&map\\[0:{}\\]"
+	return s.user
+}
+
+func current(sessions map[string]*Session, id string) string {
+	s, ok := sessions[id]
+	if !ok {
+		return user(s) // want "this call can cause panic: s is dereferenced in user"
+	}
+	return user(s)
+}
+
+// currentFixed is current as fixed, returning early on a miss.
+func currentFixed(sessions map[string]*Session, id string) string {
+	s, ok := sessions[id]
+	if !ok {
+		return ""
+	}
+	return user(s)
+}
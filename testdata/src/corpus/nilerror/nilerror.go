@@ -0,0 +1,29 @@
+package nilerror // want package:"&{}"
+
+// This case reproduces the panic of formatting an error which is
+// nil on success, as in the status helpers of API clients calling Error
+// before checking the error.
+
+type Status struct {
+	OK      bool
+	Message string
+}
+
+func failure(err error) Status { // want failure:"&map\\[0:{}\\]"
+	return Status{Message: err.Error()}
+}
+
+func check(valid bool) Status {
+	if valid {
+		return failure(nil) // want "this call can cause panic: err is the receiver of Error in failure"
+	}
+	return Status{OK: true}
+}
+
+// checkFixed is check as fixed, formatting only non-nil errors.
+func checkFixed(err error) Status {
+	if err != nil {
+		return failure(err)
+	}
+	return Status{OK: true}
+}
@@ -0,0 +1,27 @@
+package nilmap // want package:"&{}"
+
+// This case reproduces the panic of writing defaults into a map
+// which the caller left nil, as in the label and header helpers of
+// command-line tools: assignment to entry in nil map.
+
+type Spec struct {
+	Name   string
+	Labels map[string]string
+}
+
+func setDefaults(labels map[string]string, name string) { // want setDefaults:"&map\\[0:{}\\]"
+	labels["app"] = name
+}
+
+func build(name string) *Spec {
+	var labels map[string]string
+	setDefaults(labels, name) // want "this call can cause panic: labels is written to in setDefaults"
+	return &Spec{Name: name, Labels: labels}
+}
+
+// buildFixed is build as fixed, making the map first.
+func buildFixed(name string) *Spec {
+	labels := make(map[string]string)
+	setDefaults(labels, name)
+	return &Spec{Name: name, Labels: labels}
+}
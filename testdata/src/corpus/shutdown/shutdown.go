@@ -0,0 +1,19 @@
+package shutdown // want package:"&{}"
+
+// This case reproduces the panic of closing a channel which was
+// never made, as in the stop methods of workers started lazily.
+
+func stop(done chan struct{}) { // want stop:"&map\\[0:{}\\]"
+	close(done)
+}
+
+func stopIdle() {
+	var done chan struct{}
+	stop(done) // want "this call can cause panic: done is closed in stop"
+}
+
+// stopIdleFixed is stopIdle as fixed, making the channel first.
+func stopIdleFixed() {
+	done := make(chan struct{})
+	stop(done)
+}
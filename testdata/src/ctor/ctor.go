@@ -0,0 +1,64 @@
+package ctor // want package:"&{}"
+
+type T struct{ x int }
+
+type nilError struct{}
+
+func (nilError) Error() string { return "nil argument" }
+
+type S struct{ t *T }
+
+func New(t *T) (*S, error) { // want New:"nilReturns\\[0\\]" New:"nonNilOnSuccess\\[0\\]" New:"validatedArgs\\[0\\]"
+	if t == nil {
+		return nil, nilError{}
+	}
+	return &S{t: t}, nil
+}
+
+// X isn't flagged because New validates t.
+func (s *S) X() int { // want X:"&map\\[0:{}\\]"
+	return s.t.x
+}
+
+type U struct{ t *T }
+
+func NewU(t *T) *U {
+	return &U{t: t}
+}
+
+func (u *U) X() int { // want X:"&map\\[0:{}\\]" X:"panicFields\\[0.t\\]"
+	return u.t.x
+}
+
+type V struct{ t *T }
+
+func NewV() *V {
+	return &V{t: &T{}}
+}
+
+// Reset sets t to nil, so X is flagged even though NewV sets t.
+func (v *V) Reset() { // want Reset:"&map\\[0:{}\\]"
+	v.t = nil
+}
+
+func (v *V) X() int { // want X:"&map\\[0:{}\\]" X:"panicFields\\[0.t\\]"
+	return v.t.x
+}
+
+type W struct{ t *T }
+
+func NewW(t *T) *W {
+	if t == nil {
+		panic("nil t")
+	}
+	return &W{t: t}
+}
+
+// Zero leaves t nil, so X is flagged even though NewW validates t.
+func Zero() *W {
+	return &W{}
+}
+
+func (w *W) X() int { // want X:"&map\\[0:{}\\]" X:"panicFields\\[0.t\\]"
+	return w.t.x
+}
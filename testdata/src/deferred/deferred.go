@@ -0,0 +1,48 @@
+package deferred // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func release(p *T) { // want release:"&map\\[0:{}\\]"
+	p.x = 0
+}
+
+// deferNil panics when it returns.
+func deferNil() {
+	defer release(nil) // want "this call can cause panic: p is dereferenced in release"
+}
+
+// deferChecked defers the release of p when p is nil.
+func deferChecked(p *T) { // want deferChecked:"&map\\[0:{}\\]"
+	if p == nil {
+		defer release(p) // want "this call can cause panic"
+	}
+}
+
+// deferGuarded only defers the release of p when p isn't nil.
+func deferGuarded(p *T) {
+	if p != nil {
+		defer release(p)
+	}
+}
+
+// deferParam panics when it returns if p is nil.
+func deferParam(p *T) { // want deferParam:"&map\\[0:{}\\]"
+	defer release(p)
+}
+
+// deferCallback defers calling f, which panics when f is nil.
+func deferCallback(f func()) { // want deferCallback:"&map\\[0:{}\\]"
+	defer f()
+}
+
+func use() {
+	deferParam(nil)    // want "this call can cause panic"
+	deferCallback(nil) // want "this call can cause panic"
+	if p := (*T)(nil); p == nil {
+		defer deferParam(p) // want "this call can cause panic"
+	}
+}
@@ -0,0 +1,69 @@
+package derefguard // want package:"&{}"
+
+type T struct{ x, y int }
+
+func find(ok bool) *T { // want find:"nilReturns\\[0\\]"
+	if ok {
+		return &T{}
+	}
+	return nil
+}
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+// twice only reports the first dereference of t, after which t isn't
+// nil.
+func twice() int {
+	t := find(true)
+	x := t.x // want "the result of find can be nil"
+	return x + t.y
+}
+
+// dominated dereferences t again in a block dominated by the first
+// dereference.
+func dominated(ok bool) int {
+	t := find(ok)
+	x := t.x // want "the result of find can be nil"
+	if ok {
+		x += t.y
+	}
+	return x
+}
+
+// sibling dereferences t in a block the first dereference doesn't
+// dominate.
+func sibling(ok bool) int {
+	t := find(ok)
+	if ok {
+		return t.x // want "the result of find can be nil"
+	}
+	return t.y // want "the result of find can be nil"
+}
+
+// passed calls get with t after dereferencing it.
+func passed() int {
+	t := find(true)
+	x := t.x // want "the result of find can be nil"
+	return x + get(t)
+}
+
+// unreached calls get with nil only after dereferencing nil, which
+// panics first.
+func unreached(p *T) int { // want unreached:"&map\\[0:{}\\]"
+	if p == nil {
+		x := p.x
+		return x + get(p)
+	}
+	return 0
+}
+
+// reached calls get with nil before dereferencing it.
+func reached(p *T) int { // want reached:"&map\\[0:{}\\]"
+	if p == nil {
+		x := get(p) // want "this call can cause panic"
+		return x + p.x
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package embedded // want package:"&{}"
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type S struct {
+	Reader
+	n int
+}
+
+func New() *S {
+	return &S{n: 1} // want "the embedded Reader is nil and its method Read is called at embedded.go:25"
+}
+
+func NewValue() S {
+	return S{} // want "the embedded Reader is nil and its method Read is called at embedded.go:25"
+}
+
+func NewWith(r Reader) *S {
+	return &S{Reader: r}
+}
+
+func (s *S) Next(p []byte) (int, error) { // want Next:"&map\\[0:{}\\]"
+	return s.Read(p)
+}
+
+type T struct {
+	Reader
+}
+
+func NewT() *T {
+	return &T{}
+}
+
+// SetReader sets the embedded Reader, so NewT isn't reported.
+func (t *T) SetReader(r Reader) { // want SetReader:"&map\\[0:{}\\]"
+	t.Reader = r
+}
+
+func (t *T) Next(p []byte) (int, error) { // want Next:"&map\\[0:{}\\]"
+	return t.Reader.Read(p)
+}
+
+type U struct {
+	Reader
+}
+
+func NewU() *U {
+	return &U{}
+}
+
+func (u *U) Next(p []byte) (int, error) { // want Next:"&map\\[0:{}\\]"
+	if u.Reader == nil {
+		return 0, nil
+	}
+	return u.Read(p)
+}
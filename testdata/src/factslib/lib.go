@@ -0,0 +1,9 @@
+package factslib // want package:"&{}"
+
+// Get, Put and Free are implemented in assembly, so their contracts come
+// from the fact files.
+func Get(p *int) int
+
+func Put(p *int, v *int)
+
+func Free(p *int)
@@ -0,0 +1,2 @@
+// The assembly implementations of factslib are omitted from the test
+// data.
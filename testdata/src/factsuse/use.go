@@ -0,0 +1,11 @@
+package factsuse // want package:"&{}"
+
+import "factslib"
+
+func use() {
+	var v int
+	factslib.Get(nil) // want "this call can cause panic"
+	factslib.Put(nil, &v)
+	factslib.Put(&v, nil) // want "this call can cause panic"
+	factslib.Free(nil)
+}
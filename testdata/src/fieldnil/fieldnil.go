@@ -0,0 +1,45 @@
+package fieldnil // want package:"&{}"
+
+type Logger struct{ prefix string }
+
+type Config struct {
+	Logger *Logger
+	Name   string
+}
+
+func run(l *Logger) string { // want run:"&map\\[0:{}\\]"
+	return l.prefix
+}
+
+func explicit() string {
+	cfg := Config{Logger: nil}
+	return run(cfg.Logger) // want "this call can cause panic: l is dereferenced in run"
+}
+
+func omitted() string {
+	cfg := Config{Name: "a"}
+	return run(cfg.Logger) // want "this call can cause panic: l is dereferenced in run"
+}
+
+func pointer() string {
+	cfg := &Config{}
+	return run(cfg.Logger) // want "this call can cause panic: l is dereferenced in run"
+}
+
+// set assigns the field after the literal.
+func set(l *Logger) string {
+	cfg := Config{}
+	cfg.Logger = l
+	return run(cfg.Logger)
+}
+
+// escaping passes the struct to a function which can set the field.
+func escaping() string {
+	cfg := &Config{}
+	configure(cfg)
+	return run(cfg.Logger)
+}
+
+func configure(cfg *Config) { // want configure:"&map\\[0:{}\\]"
+	cfg.Logger = &Logger{}
+}
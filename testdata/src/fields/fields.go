@@ -0,0 +1,40 @@
+package fields // want package:"&{}"
+
+type Logger struct{ prefix string }
+
+func (l *Logger) Print(s string) { // want Print:"&map\\[0:{}\\]"
+	println(l.prefix + s)
+}
+
+type Deps struct {
+	Log  *Logger
+	Name string
+}
+
+func run(d *Deps) { // want run:"&map\\[0:{}\\]" run:"panicFields\\[0.Log\\]"
+	d.Log.Print(d.Name)
+}
+
+func runValue(d Deps) { // want runValue:"panicFields\\[0.Log\\]"
+	d.Log.Print("x")
+}
+
+func guarded(d *Deps) { // want guarded:"&map\\[0:{}\\]"
+	if d.Log != nil {
+		d.Log.Print(d.Name)
+	}
+}
+
+func escape(d *Deps) {}
+
+func g() {
+	run(&Deps{Name: "x"}) // want "this call can cause panic: field Log is nil"
+	run(&Deps{Log: nil})  // want "this call can cause panic: field Log is nil"
+	run(&Deps{Log: &Logger{}})
+	runValue(Deps{}) // want "this call can cause panic: field Log is nil"
+	guarded(&Deps{})
+
+	d := &Deps{}
+	escape(d)
+	run(d)
+}
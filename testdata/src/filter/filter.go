@@ -0,0 +1,19 @@
+package filter // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func Selected() int {
+	return get(nil) // want "this call can cause panic"
+}
+
+func skipped() int {
+	return get(nil)
+}
+
+func (t *T) Selected() int {
+	return get(nil) // want "this call can cause panic"
+}
@@ -0,0 +1,11 @@
+package fingerprint // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func use() int {
+	return get(nil) // want "this call can cause panic"
+}
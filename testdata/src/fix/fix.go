@@ -0,0 +1,28 @@
+package fix // want package:"&{}"
+
+type T struct{ x int }
+
+func deref(p *T) int { // want deref:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func set(m map[string]int) { // want set:"&map\\[0:{}\\]"
+	m["a"] = 1
+}
+
+func a() {
+	deref(nil) // want "this call can cause panic"
+}
+
+func b(p *T) error { // want b:"&map\\[0:{}\\]"
+	if p == nil {
+		deref(p) // want "this call can cause panic"
+	}
+	return nil
+}
+
+func c(m map[string]int) { // want c:"&map\\[0:{}\\]"
+	if m == nil {
+		set(m) // want "this call can cause panic"
+	}
+}
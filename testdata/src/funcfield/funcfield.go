@@ -0,0 +1,67 @@
+package funcfield // want package:"&{}"
+
+type Req struct{ url string }
+
+type Resp struct{ body string }
+
+// Deps are the dependencies of a service, injected as functions.
+type Deps struct {
+	Fetch func(*Req) *Resp // want Fetch:"&map\\[0:{}\\]"
+	Store func(*Resp) error
+	Log   func(*Req) // want Log:"&map\\[0:{}\\]"
+	Parse func(*Req) string
+}
+
+func fetch(r *Req) *Resp { // want fetch:"&map\\[0:{}\\]"
+	return &Resp{r.url}
+}
+
+func fetchCached(r *Req) *Resp { // want fetchCached:"&map\\[0:{}\\]" fetchCached:"nilReturns\\[0\\]"
+	if r.url == "" {
+		return nil
+	}
+	return &Resp{}
+}
+
+func store(p *Resp) error {
+	if p == nil {
+		return nil
+	}
+	return nil
+}
+
+func logReq(r *Req) { // want logReq:"&map\\[0:{}\\]"
+	println(r.url)
+}
+
+func parse(r *Req) string { // want parse:"&map\\[0:{}\\]"
+	return r.url
+}
+
+func newDeps(cached bool) *Deps {
+	d := &Deps{Fetch: fetch, Store: store, Log: logReq}
+	if cached {
+		d.Fetch = fetchCached
+	}
+	// Parse is also assigned a closure, whose facts are unknown.
+	d.Parse = parse
+	if cached {
+		d.Parse = func(r *Req) string { return "" }
+	}
+	return d
+}
+
+// get passes r to Fetch, which panics on nil with all its
+// implementations.
+func get(d *Deps, r *Req) *Resp { // want get:"&map\\[0:{} 1:{}\\]"
+	return d.Fetch(r)
+}
+
+func use(d *Deps) { // want use:"&map\\[0:{}\\]"
+	d.Fetch(nil) // want "this call can cause panic: Fetch is assigned fetch and fetchCached"
+	d.Store(nil)
+	d.Parse(nil)
+	d.Log(nil)       // want "this call can cause panic: Log is assigned logReq"
+	get(d, nil)      // want "this call can cause panic"
+	defer d.Log(nil) // want "this call can cause panic: Log is assigned logReq"
+}
@@ -0,0 +1,32 @@
+package funcslice // want package:"&{}"
+
+type T struct{ x int }
+
+func deref(p *T) { // want deref:"&map\\[0:{}\\]"
+	p.x++
+}
+
+func noop(p *T) {}
+
+func apply(fs []func(*T), p *T) { // want apply:"elementCalls\\[0:\\[\\[0 1\\]\\]\\]"
+	for _, f := range fs {
+		f(p)
+	}
+}
+
+func chain(p *T, fs ...func(*T)) { // want chain:"elementCalls\\[1:\\[\\[0 0\\]\\]\\]" chain:"variadicElems\\[all\\]"
+	for i := range fs {
+		fs[i](p)
+	}
+}
+
+func run(p *T) { // want run:"&map\\[0:{}\\]"
+	apply([]func(*T){noop, deref}, p)
+}
+
+func use() {
+	apply([]func(*T){noop, deref}, nil) // want "this call can cause panic: deref in the slice panics on nil"
+	apply([]func(*T){noop}, nil)
+	chain(nil, noop, deref) // want "this call can cause panic: deref in the slice panics on nil"
+	chain(&T{}, deref)
+}
@@ -0,0 +1,21 @@
+package generated // want package:"&{}"
+
+type T struct{ x int }
+
+// Sum is generated with a parameter for each field of a message.
+func Sum(a, b, c, d, e, f, g, h, i, j *T) int { // want Sum:"panicArgs\\[10 params: 0-9\\]" "exported function Sum panics when any of its 10 nillable parameters is nil"
+	return a.x + b.x + c.x + d.x + e.x + f.x + g.x + h.x + i.x + j.x
+}
+
+// Some only dereferences some of its parameters.
+func Some(a, b, c, d, e, f, g, h, i, j, k, l *T, n int) int { // want Some:"panicArgs\\[10 params: 0-3,5-8,10-11\\]" "exported function Some panics when one of a, b, c and 7 more parameters is nil"
+	if e != nil && j != nil {
+		return e.x + j.x
+	}
+	return a.x + b.x + c.x + d.x + f.x + g.x + h.x + i.x + k.x + l.x + n
+}
+
+// Few has as many parameters as are listed.
+func Few(a, b, c, d, e, f, g, h *T) int { // want Few:"&map\\[0:{} 1:{} 2:{} 3:{} 4:{} 5:{} 6:{} 7:{}\\]" "exported function Few panics when a or b or c or d or e or f or g or h is nil"
+	return a.x + b.x + c.x + d.x + e.x + f.x + g.x + h.x
+}
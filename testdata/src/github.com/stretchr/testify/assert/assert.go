@@ -0,0 +1,14 @@
+// Package assert is a stub of the assertions of testify.
+package assert
+
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+func NotNil(t TestingT, object interface{}, msgAndArgs ...interface{}) bool {
+	if object == nil {
+		t.Errorf("expected value not to be nil")
+		return false
+	}
+	return true
+}
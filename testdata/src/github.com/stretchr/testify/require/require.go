@@ -0,0 +1,24 @@
+// Package require is a stub of the assertions of testify aborting tests.
+package require
+
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+func NotNil(t TestingT, object interface{}, msgAndArgs ...interface{}) {
+	if object == nil {
+		t.Errorf("expected value not to be nil")
+		t.FailNow()
+	}
+}
+
+type Assertions struct{ t TestingT }
+
+func New(t TestingT) *Assertions {
+	return &Assertions{t}
+}
+
+func (a *Assertions) NotNil(object interface{}, msgAndArgs ...interface{}) {
+	NotNil(a.t, object, msgAndArgs...)
+}
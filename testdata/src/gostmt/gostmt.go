@@ -0,0 +1,51 @@
+package gostmt // want package:"&{}"
+
+type T struct{ x int }
+
+func work(p *T) { // want work:"&map\\[0:{}\\]"
+	p.x++
+}
+
+func (t *T) run() { // want run:"&map\\[0:{}\\]"
+	t.x++
+}
+
+// spawnNil crashes the program in the spawned goroutine.
+func spawnNil() {
+	go work(nil) // want "this call can cause panic: p is dereferenced in work"
+}
+
+// spawnChecked spawns work with p when p is nil.
+func spawnChecked(p *T) { // want spawnChecked:"&map\\[0:{}\\]"
+	if p == nil {
+		go work(p) // want "this call can cause panic"
+	}
+}
+
+// spawnGuarded only spawns work with p when p isn't nil.
+func spawnGuarded(p *T) {
+	if p != nil {
+		go work(p)
+	}
+}
+
+// spawnMethod runs a method of a nil receiver.
+func spawnMethod() {
+	var t *T
+	go t.run() // want "this call can cause panic"
+}
+
+// spawnParam panics in the goroutine if p is nil.
+func spawnParam(p *T) { // want spawnParam:"&map\\[0:{}\\]"
+	go work(p)
+}
+
+// spawnFunc calls f in a goroutine, which panics when f is nil.
+func spawnFunc(f func()) { // want spawnFunc:"&map\\[0:{}\\]"
+	go f()
+}
+
+func use() {
+	spawnParam(nil) // want "this call can cause panic"
+	spawnFunc(nil)  // want "this call can cause panic"
+}
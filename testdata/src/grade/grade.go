@@ -0,0 +1,12 @@
+package grade // want package:"&{}" "nil-safety grade C: 1 of 2 dereferences of nillable parameters are guarded, 1 exported functions panic on nil arguments"
+
+func Deref(p *int) int { // want Deref:"&map\\[0:{}\\]"
+	return *p
+}
+
+func guarded(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,20 @@
+package guard // want package:"&{}"
+
+type T struct{ x int }
+
+func Get(p *T) int { // want Get:"&map\\[0:{}\\]" "exported function Get panics when p is nil"
+	return p.x
+}
+
+func Load(p *T, m map[string]int) (int, error) { // want Load:"&map\\[0:{} 1:{}\\]" "exported function Load panics when p or m is nil"
+	m["x"] = p.x
+	return 0, nil
+}
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func g() {
+	get(nil)
+}
@@ -0,0 +1,7 @@
+// want package:"&{}"
+package guardimport // import "guardimport"
+
+func Parse(m map[string]int) error { // want Parse:"&map\\[0:{}\\]" "exported function Parse panics when m is nil"
+	m["x"] = 1
+	return nil
+}
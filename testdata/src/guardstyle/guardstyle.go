@@ -0,0 +1,44 @@
+package guardstyle // want package:"&{}"
+
+import "reflect"
+
+type T struct{ x int }
+
+type I interface{ M() }
+
+// reflected and notNil are flagged because the checks by reflection
+// aren't seen.
+func reflected(p *T) int { // want reflected:"&map\\[0:{}\\]"
+	if reflect.ValueOf(p).IsNil() { // want "write this nil check as p == nil"
+		return 0
+	}
+	return p.x
+}
+
+func notNil(m map[string]int) { // want notNil:"&map\\[0:{}\\]"
+	if !reflect.ValueOf(m).IsNil() { // want "write this nil check as m != nil"
+		m["x"]++
+	}
+}
+
+func typed(p *T) int {
+	if p == (*T)(nil) { // want "write this nil check as p == nil"
+		return 0
+	}
+	return p.x
+}
+
+func typedNotNil(p *T) int {
+	if (*T)(nil) != p { // want "write this nil check as p != nil"
+		return p.x
+	}
+	return 0
+}
+
+// Interfaces holding nil pointers aren't nil, so their checks aren't
+// reported.
+func iface(i I) bool {
+	return reflect.ValueOf(i).IsNil() || i == (*T)(nil) || i == nil
+}
+
+func (*T) M() {}
@@ -0,0 +1,19 @@
+package histogram // want package:"&{}" "dereferences of nillable parameters by kind \\(guarded/total\\): call 0/1, map write 1/2, pointer dereference 0/1"
+
+func deref(p *int) int { // want deref:"&map\\[0:{}\\]"
+	return *p
+}
+
+func viaDeref(p *int) int { // want viaDeref:"&map\\[0:{}\\]"
+	return deref(p)
+}
+
+func set(m map[string]int) { // want set:"&map\\[0:{}\\]"
+	m["a"] = 1
+}
+
+func setGuarded(m map[string]int) {
+	if m != nil {
+		m["a"] = 1
+	}
+}
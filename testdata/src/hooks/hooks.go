@@ -0,0 +1,55 @@
+package hooks // want package:"&{}"
+
+type Hooks struct {
+	OnStart func()
+	OnStop  func()
+	OnLog   func()
+}
+
+type Server struct{ n int }
+
+func (s *Server) start() { // want start:"&map\\[0:{}\\]"
+	s.n++
+}
+
+func (s *Server) stop() { // want stop:"&map\\[0:{}\\]"
+	s.n--
+}
+
+// log handles nil receivers.
+func (s *Server) log() { // want log:"&{}"
+	if s == nil {
+		return
+	}
+	s.n++
+}
+
+func find(name string) *Server { // want find:"nilReturns\\[0\\]"
+	if name == "" {
+		return nil
+	}
+	return &Server{}
+}
+
+func wire(enabled bool) *Hooks {
+	var s *Server
+	if enabled {
+		s = &Server{}
+	}
+	h := &Hooks{OnStart: s.start, OnLog: s.log}
+	h.OnStop = find("").stop
+	return h
+}
+
+func wireChecked(s *Server) *Hooks {
+	if s == nil {
+		s = &Server{}
+	}
+	return &Hooks{OnStart: s.start}
+}
+
+func run(h *Hooks) { // want run:"&map\\[0:{}\\]"
+	h.OnStart() // want "this call can cause panic: OnStart is bound to start of a possibly nil receiver at hooks.go:39"
+	h.OnStop()  // want "this call can cause panic: OnStop is bound to stop of a possibly nil receiver at hooks.go:40"
+	h.OnLog()
+}
@@ -0,0 +1,13 @@
+package hover // want package:"&{}"
+
+func deref(p *int) int { // want deref:"&map\\[0:{}\\]"
+	return *p
+}
+
+func store(m map[int]int) { // want store:"&map\\[0:{}\\]"
+	m[0] = 0
+}
+
+func wrap(p *int) int { // want wrap:"&map\\[0:{}\\]"
+	return deref(p)
+}
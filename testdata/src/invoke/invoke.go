@@ -0,0 +1,28 @@
+package invoke // want package:"&{}"
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+func read(r Reader) int { // want read:"&map\\[0:{}\\]"
+	n, _ := r.Read(nil)
+	return n
+}
+
+func checked(r Reader) int { // want checked:"typedNilChecks\\[0:\\[Read\\]\\]"
+	if r == nil {
+		return 0
+	}
+	n, _ := r.Read(nil)
+	return n
+}
+
+func wrap(r Reader) int { // want wrap:"&map\\[0:{}\\]"
+	return read(r)
+}
+
+func use() {
+	read(nil) // want "this call can cause panic: r is the receiver of Read in read"
+	checked(nil)
+	wrap(nil) // want "this call can cause panic: r is passed to read in wrap"
+}
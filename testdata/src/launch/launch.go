@@ -0,0 +1,29 @@
+package launch // want package:"&{}"
+
+import "sync"
+
+type T struct{ x int }
+
+func submit(wg *sync.WaitGroup) {
+	var p *T
+	wg.Go(func() { _ = p.x }) // want "this goroutine can panic: p is nil and dereferenced in the submitted function"
+}
+
+func submitChecked(wg *sync.WaitGroup) {
+	var p *T
+	wg.Go(func() {
+		if p != nil {
+			_ = p.x
+		}
+	})
+}
+
+func submitNonNil(wg *sync.WaitGroup) {
+	p := &T{}
+	wg.Go(func() { _ = p.x })
+}
+
+func goStmt() {
+	var p *T
+	go func() { _ = p.x }() // want "this goroutine can panic: p is nil and dereferenced in the submitted function"
+}
@@ -0,0 +1,57 @@
+package lenguard // want package:"&{}"
+
+func set(m map[string]int) {
+	if len(m) == 0 {
+		return
+	}
+	m["a"] = 1
+}
+
+func first(xs []*int) int {
+	if len(xs) > 0 {
+		return *xs[0]
+	}
+	return 0
+}
+
+func stored(m map[string]int) {
+	ok := 0 < len(m)
+	if ok {
+		m["a"] = 1
+	}
+}
+
+func always(m map[string]int) { // want always:"conditionalArgs\\[0:\\[len\\(0\\)>=0\\]\\]"
+	if len(m) >= 0 {
+		m["a"] = 1
+	}
+}
+
+func empty(m map[string]int) { // want empty:"conditionalArgs\\[0:\\[len\\(0\\)<1\\]\\]"
+	if len(m) < 1 {
+		m["a"] = 1
+	}
+}
+
+func load(ok bool) map[string]int { // want load:"nilReturns\\[0\\]"
+	if !ok {
+		return nil
+	}
+	return map[string]int{"a": 1}
+}
+
+func result() {
+	m := load(false)
+	if len(m) != 0 {
+		m["b"] = 2
+	}
+	m["c"] = 3 // want "the result of load can be nil"
+}
+
+func use() {
+	set(nil)
+	first(nil)
+	stored(nil)
+	always(nil) // want "this call can cause panic because len\\(m\\) >= 0"
+	empty(nil)  // want "this call can cause panic because len\\(m\\) < 1"
+}
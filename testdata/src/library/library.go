@@ -0,0 +1,24 @@
+package library // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+// Get is always called with non-nil by the package, but its users can
+// pass nil.
+func Get(p *T) int { // want Get:"&map\\[0:{}\\]" "callers of Get can cause panic by passing nil p: must not be nil: passed to get at library.go:12"
+	return get(p)
+}
+
+func Total() int {
+	return Get(&T{})
+}
+
+type counter struct{ n *int }
+
+// Inc isn't reported because counter is unexported.
+func (c *counter) Inc() { // want Inc:"&map\\[0:{}\\]" Inc:"panicFields\\[0.n\\]"
+	*c.n++
+}
@@ -0,0 +1,34 @@
+package loopguard // want package:"&{}"
+
+type T struct {
+	x    int
+	next *T
+}
+
+// sum checks p before the loop, whose header is entered both from the
+// check and from the end of the body.
+func sum(p *T, n int) int {
+	s := 0
+	if p != nil {
+		for i := 0; i < n; i++ {
+			s += p.x
+		}
+	}
+	return s
+}
+
+// inner checks p in the nil branch of the outer check, which doesn't
+// make p non-nil.
+func inner(p, q *T) int { // want inner:"&map\\[0:{}\\]"
+	if q == nil {
+		if p == nil {
+			return 0
+		}
+	}
+	return p.x
+}
+
+func use() {
+	sum(nil, 3)
+	inner(nil, &T{}) // want "this call can cause panic: p is dereferenced in inner"
+}
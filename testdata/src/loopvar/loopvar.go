@@ -0,0 +1,35 @@
+package loopvar // want package:"&{}"
+
+type node struct {
+	next *node
+	n    int
+}
+
+func (n *node) work() { // want work:"&map\\[0:{}\\]"
+	n.n++
+}
+
+// each is built before Go 1.22, where the iterations share p.
+func each(first *node) {
+	for p := first; p != nil; p = p.next {
+		go func() { p.work() }() // want "this goroutine can panic: the iterations share p before Go 1.22 and it is nil when the loop ends"
+	}
+}
+
+func shared(first *node) {
+	p := first
+	for p != nil {
+		go func() { p.work() }() // want "this goroutine can panic: the iterations share p and it is nil when the loop ends"
+		p = p.next
+	}
+}
+
+func checked(first *node) {
+	for p := first; p != nil; p = p.next {
+		go func() {
+			if p != nil {
+				p.work()
+			}
+		}()
+	}
+}
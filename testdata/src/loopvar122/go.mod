@@ -0,0 +1,3 @@
+module loopvar122
+
+go 1.22
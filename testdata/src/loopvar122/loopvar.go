@@ -0,0 +1,25 @@
+package loopvar122 // want package:"&{}"
+
+type node struct {
+	next *node
+	n    int
+}
+
+func (n *node) work() { // want work:"&map\\[0:{}\\]"
+	n.n++
+}
+
+// each is built with Go 1.22, where each iteration has its own p.
+func each(first *node) {
+	for p := first; p != nil; p = p.next {
+		go func() { p.work() }()
+	}
+}
+
+func shared(first *node) {
+	p := first
+	for p != nil {
+		go func() { p.work() }() // want "this goroutine can panic: the iterations share p and it is nil when the loop ends"
+		p = p.next
+	}
+}
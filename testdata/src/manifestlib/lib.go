@@ -0,0 +1,11 @@
+package manifestlib // want package:"&{}"
+
+type Buffer struct{ b []byte }
+
+// Len and Deref are implemented in assembly, so their contracts come
+// from the manifest.
+func (b *Buffer) Len() int
+
+func Deref(p *int) int
+
+func Safe(p *int) int
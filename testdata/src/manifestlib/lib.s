@@ -0,0 +1,2 @@
+// The assembly implementations of manifestlib are omitted from the
+// test data.
@@ -0,0 +1,16 @@
+package manifestuse // want package:"&{}"
+
+import "manifestlib"
+
+func deref(p *int) int { // want deref:"&map\\[0:{}\\]"
+	return manifestlib.Deref(p)
+}
+
+func use(b *manifestlib.Buffer) { // want use:"&map\\[0:{}\\]"
+	manifestlib.Deref(nil) // want "this call can cause panic"
+	manifestlib.Safe(nil)
+	deref(nil) // want "this call can cause panic: p is passed to Deref in deref"
+	var nb *manifestlib.Buffer
+	nb.Len() // want "this call can cause panic"
+	b.Len()
+}
@@ -0,0 +1,49 @@
+package message // want package:"&{}"
+
+type T struct{ x int }
+
+type nilError struct{ name string }
+
+func (e nilError) Error() string { return e.name + " is nil" }
+
+func mentioned(p *T) int {
+	if p == nil {
+		panic("mentioned: p is nil")
+	}
+	return p.x
+}
+
+func vague(p *T) int {
+	if p == nil {
+		panic("invalid argument") // want "the panic on nil p should mention p or be an error"
+	}
+	return p.x
+}
+
+func typed(p *T) int {
+	if p == nil {
+		panic(nilError{"p"})
+	}
+	return p.x
+}
+
+func number(p *T) int {
+	if p == nil {
+		panic(1) // want "the panic on nil p should mention p or be an error"
+	}
+	return p.x
+}
+
+func hidden(p *T) int {
+	if p == nil {
+		panic("nothing to process") // want "the panic on nil p should mention p or be an error"
+	}
+	return p.x
+}
+
+func quoted(p *T) int {
+	if p == nil {
+		panic("hidden: nil p")
+	}
+	return p.x
+}
@@ -0,0 +1,11 @@
+package methodlib // want package:"&{}"
+
+type T struct{ x int }
+
+type Opts struct{ N int }
+
+func (t *T) Apply(o *Opts) { // want Apply:"&map\\[0:{} 1:{}\\]"
+	t.x = o.N
+}
+
+type Embed struct{ *T }
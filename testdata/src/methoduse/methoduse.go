@@ -0,0 +1,11 @@
+package methoduse // want package:"&{}"
+
+import "methodlib"
+
+func use(t *methodlib.T, e methodlib.Embed) { // want use:"&map\\[0:{}\\]"
+	t.Apply(nil) // want "this call can cause panic"
+	t.Apply(&methodlib.Opts{})
+	e.Apply(nil) // want "this call can cause panic"
+	var n *methodlib.T
+	n.Apply(&methodlib.Opts{}) // want "this call can cause panic"
+}
@@ -0,0 +1,56 @@
+package methodvalue // want package:"&{}"
+
+import "io"
+
+type T struct{ x int }
+
+func (t *T) get() int { return t.x } // want get:"&map\\[0:{}\\]"
+
+func (t T) value() int { return t.x }
+
+// read creates a method value of r, which panics when r is nil.
+func read(r io.Reader) func([]byte) (int, error) { // want read:"&map\\[0:{}\\]"
+	return r.Read
+}
+
+// deferred never calls the method value of r.
+func deferred(r io.Reader, ok bool) func([]byte) (int, error) { // want deferred:"&map\\[0:{}\\]" deferred:"nilReturns\\[0\\]"
+	f := r.Read
+	if ok {
+		return f
+	}
+	return nil
+}
+
+// guarded only creates the method value when r isn't nil.
+func guarded(r io.Reader) func([]byte) (int, error) { // want guarded:"nilReturns\\[0\\]"
+	if r == nil {
+		return nil
+	}
+	return r.Read
+}
+
+// pointer binds a pointer to a method with a pointer receiver, which
+// doesn't dereference it until the method is called.
+func pointer(t *T) func() int {
+	return t.get
+}
+
+// value binds the value t points to.
+func value(t *T) func() int { // want value:"&map\\[0:{}\\]"
+	return t.value
+}
+
+// asserted is an explicit assertion of r to its own type.
+func asserted(r io.Reader) io.Reader { // want asserted:"&map\\[0:{}\\]"
+	return r.(io.Reader)
+}
+
+func use() {
+	read(nil)            // want "this call can cause panic: r is bound to a method value in read"
+	deferred(nil, false) // want "this call can cause panic: r is bound to a method value in deferred"
+	guarded(nil)
+	pointer(nil)
+	value(nil)    // want "this call can cause panic: t is dereferenced in value"
+	asserted(nil) // want "this call can cause panic: r is type asserted in asserted"
+}
@@ -0,0 +1,7 @@
+package missinglib
+
+type T struct{ x int }
+
+func Get(p *T) int {
+	return p.x
+}
@@ -0,0 +1,17 @@
+package missinguse // want package:"&{}"
+
+import "missinglib"
+
+// get doesn't know that missinglib.Get dereferences p, because missinglib
+// has no facts.
+func get(p *missinglib.T) int {
+	return missinglib.Get(p) // want "calls to missinglib are not checked"
+}
+
+func again(p *missinglib.T) int {
+	return missinglib.Get(p)
+}
+
+func use() int {
+	return get(nil) + again(nil)
+}
@@ -0,0 +1,78 @@
+package nilhelper // want package:"&{}"
+
+type T struct{ x int }
+
+func valid(p *T) bool {
+	return p != nil
+}
+
+func isEmpty(p *T) bool {
+	return p == nil
+}
+
+func mustHave(p interface{}) {}
+
+type checker struct{}
+
+func (checker) NonNil(p *T) bool {
+	return p != nil
+}
+
+// other isn't listed in -nil-checks.
+func other(p *T) bool {
+	return p != nil
+}
+
+func checked(p *T) int {
+	if !valid(p) {
+		return 0
+	}
+	return p.x
+}
+
+func empty(p *T) int {
+	if isEmpty(p) {
+		return 0
+	}
+	return p.x
+}
+
+// emptyInverted dereferences p when isEmpty reports it is nil.
+func emptyInverted(p *T) int { // want emptyInverted:"&map\\[0:{}\\]"
+	if !isEmpty(p) {
+		return 0
+	}
+	return p.x
+}
+
+func short(p, q *T) int {
+	if valid(p) && valid(q) {
+		return p.x + q.x
+	}
+	return 0
+}
+
+func method(c checker, p *T) int {
+	if c.NonNil(p) {
+		return p.x
+	}
+	return 0
+}
+
+func asserted(p *T) int {
+	mustHave(p)
+	return p.x
+}
+
+func assertedLate(p *T) int { // want assertedLate:"&map\\[0:{}\\]"
+	x := p.x
+	mustHave(p)
+	return x
+}
+
+func unlisted(p *T) int { // want unlisted:"&map\\[0:{}\\]"
+	if !other(p) {
+		return 0
+	}
+	return p.x
+}
@@ -0,0 +1,37 @@
+package nillable // want package:"&{}"
+
+import "unsafe"
+
+type T struct{ x int }
+
+func call(f func()) { // want call:"&map\\[0:{}\\]"
+	f()
+}
+
+func stop(c chan int) { // want stop:"&map\\[0:{}\\]"
+	close(c)
+}
+
+func handler(ok bool) func() { // want handler:"nilReturns\\[0\\]"
+	if !ok {
+		return nil
+	}
+	return func() {}
+}
+
+func done(ok bool) chan int { // want done:"nilReturns\\[0\\]"
+	if !ok {
+		return nil
+	}
+	return make(chan int)
+}
+
+func load(p unsafe.Pointer) int { // want load:"&map\\[0:{}\\]"
+	return (*T)(p).x
+}
+
+func use() {
+	call(handler(false)) // want "the result of handler can be nil"
+	stop(done(false))    // want "the result of done can be nil"
+	load(nil)            // want "this call can cause panic"
+}
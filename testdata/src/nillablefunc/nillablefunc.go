@@ -0,0 +1,32 @@
+package nillablefunc // want package:"&{}"
+
+type S struct{ f func() }
+
+func run(s *S) { // want run:"panicFields\\[0.f\\]" run:"&map\\[0:{}\\]"
+	s.f()
+}
+
+func call(f func()) { // want call:"&map\\[0:{}\\]"
+	f()
+}
+
+func stop(c chan int) { // want stop:"&map\\[0:{}\\]"
+	close(c)
+}
+
+func or(f func(), ok bool) func() { // want or:"nilReturns\\[0\\]"
+	if ok {
+		return func() {}
+	}
+	return f
+}
+
+func relay(c chan int) chan int { // want relay:"identityReturns\\[0:0\\]"
+	return c
+}
+
+func use(f func()) {
+	run(&S{})          // want "field f is nil"
+	call(or(f, false)) // want "the result of or can be nil"
+	stop(relay(nil))   // want "c is closed in stop"
+}
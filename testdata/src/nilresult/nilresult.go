@@ -0,0 +1,41 @@
+package nilresult // want package:"&{}"
+
+type T struct{ name string }
+
+// wrap returns the result of find, declared after it.
+func wrap(key string) *T { // want wrap:"nilReturns\\[0\\]"
+	return find(key)
+}
+
+func find(key string) *T { // want find:"nilReturns\\[0\\]"
+	if key == "" {
+		return nil
+	}
+	return &T{key}
+}
+
+// must never returns nil.
+func must(key string) *T {
+	if t := find(key); t != nil {
+		return t
+	}
+	return &T{}
+}
+
+func name(t *T) string { // want name:"&map\\[0:{}\\]"
+	return t.name
+}
+
+func use() {
+	_ = name(find("a")) // want "this call can cause panic: the result of find can be nil: t is dereferenced in name"
+	_ = name(wrap("a")) // want "this call can cause panic: the result of wrap can be nil"
+	_ = name(must("a"))
+	if t := find("b"); t != nil {
+		_ = name(t)
+	}
+	t := find("c")
+	if t == nil {
+		return
+	}
+	_ = name(t)
+}
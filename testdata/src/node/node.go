@@ -0,0 +1,13 @@
+package node // want package:"&{}"
+
+type T struct{ x int }
+
+func f(p *T, m map[string]int, s []int, i interface{}) int { // want f:"&map\\[0:{} 1:{} 2:{} 3:{}\\]"
+	m["k"] = p.x
+	n := s[0] + m["k"]
+	_ = i.(error)
+	g(p)
+	return n
+}
+
+func g(p *T) {}
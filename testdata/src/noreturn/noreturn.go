@@ -0,0 +1,57 @@
+package noreturn // want package:"&{}"
+
+import (
+	"log"
+	"os"
+	"runtime"
+)
+
+type T struct{ x int }
+
+func fatal(p *T) int {
+	if p == nil {
+		log.Fatal("p is nil")
+	}
+	return p.x
+}
+
+func exit(p *T) int {
+	if p == nil {
+		os.Exit(1)
+	}
+	return p.x
+}
+
+func goexit(p *T) int {
+	if p == nil {
+		runtime.Goexit()
+	}
+	return p.x
+}
+
+func logger(l *log.Logger, p *T) int {
+	if p == nil {
+		l.Fatalf("p is nil")
+	}
+	return p.x
+}
+
+// die never returns with -no-return die.
+func die(msg string) {
+	panic(msg)
+}
+
+func custom(p *T) int {
+	if p == nil {
+		die("p is nil")
+	}
+	return p.x
+}
+
+// printed goes on after logging.
+func printed(p *T) int { // want printed:"&map\\[0:{}\\]"
+	if p == nil {
+		log.Print("p is nil")
+	}
+	return p.x
+}
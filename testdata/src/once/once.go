@@ -0,0 +1,30 @@
+package once // want package:"&{}"
+
+import "sync"
+
+type T struct{ x int }
+
+type C struct {
+	once sync.Once
+	p    *T
+	q    *T
+}
+
+// get initializes p lazily before dereferencing it.
+func get(c *C) int {
+	c.once.Do(func() { c.p = &T{} })
+	return c.p.x
+}
+
+// getQ dereferences q, which Do doesn't initialize.
+func getQ(c *C) int { // want getQ:"panicFields\\[0.q\\]"
+	c.once.Do(func() { c.p = &T{} })
+	return c.q.x
+}
+
+// getOther dereferences p of c, while Do initializes p of d.
+func getOther(c, d *C) int { // want getOther:"&map\\[0:{}\\]" getOther:"panicFields\\[0.p\\]"
+	c.once.Do(func() { d.p = &T{} })
+	return c.p.x
+}
+
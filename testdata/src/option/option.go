@@ -0,0 +1,50 @@
+package option // want package:"&{}"
+
+type Logger struct{ n int }
+
+func (l *Logger) Log() { // want Log:"&map\\[0:{}\\]"
+	l.n++
+}
+
+type config struct {
+	log  *Logger
+	name string
+}
+
+type Option func(*config)
+
+func WithLogger(l *Logger) Option { // want WithLogger:"optionFields\\[option.config.log\\]"
+	return func(c *config) { c.log = l }
+}
+
+func WithName(name string) Option { // want WithName:"optionFields\\[\\]"
+	return func(c *config) { c.name = name }
+}
+
+func New(opts ...Option) string { // want New:"requiredOptions\\[option.config.log\\]" New:"variadicElems\\[all\\]"
+	c := &config{}
+	for _, o := range opts {
+		o(c)
+	}
+	c.log.Log()
+	return c.name
+}
+
+// Twice logs twice, requiring the logger once.
+func Twice(opts ...Option) { // want Twice:"requiredOptions\\[option.config.log\\]" Twice:"variadicElems\\[all\\]"
+	c := &config{}
+	for _, o := range opts {
+		o(c)
+	}
+	c.log.Log()
+	c.log.Log()
+}
+
+func use(l *Logger, opts []Option) {
+	New()              // want "this call can cause panic: no option sets option.config.log"
+	New(WithName("x")) // want "this call can cause panic: no option sets option.config.log"
+	New(WithLogger(l))
+	New(WithName("x"), WithLogger(l))
+	New(opts...)
+	Twice() // want "this call can cause panic: no option sets option.config.log"
+}
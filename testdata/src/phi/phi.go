@@ -0,0 +1,44 @@
+package phi // want package:"&{}"
+
+type T struct{ x int }
+
+func pick(p *T, fallback bool) int { // want pick:"&map\\[0:{}\\]"
+	if fallback {
+		p = &T{}
+	}
+	return p.x
+}
+
+func loop(p *T, n int) int { // want loop:"conditionalArgs\\[0:\\[1<=0\\]\\]"
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			p = &T{x: i}
+		}
+	}
+	return p.x
+}
+
+func defaulted(p *T) int {
+	if p == nil {
+		p = &T{}
+	}
+	return p.x
+}
+
+func returned(p *T, fallback bool) int {
+	if p == nil {
+		return 0
+	}
+	if fallback {
+		p = &T{}
+	}
+	return p.x
+}
+
+func use() {
+	pick(nil, false) // want "this call can cause panic: p is dereferenced in pick"
+	loop(nil, 0)     // want "this call can cause panic because n <= 0"
+	loop(nil, 1)
+	defaulted(nil)
+	returned(nil, false)
+}
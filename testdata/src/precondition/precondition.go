@@ -0,0 +1,22 @@
+package precondition // want package:"&{}"
+
+type T struct{ x int }
+
+func New(p, q *T) *T { // want New:"&map\\[0:{} 1:{}\\]" New:"intentionalArgs\\[0 1\\]"
+	if p == nil {
+		panic("p must not be nil")
+	}
+	if q == nil {
+		panic("q must not be nil")
+	}
+	return &T{p.x + q.x}
+}
+
+func deref(p *T) int { // want deref:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func g() {
+	New(nil, &T{}) // want "this call violates a precondition of New"
+	deref(nil)     // want "this call can cause panic"
+}
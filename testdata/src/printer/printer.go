@@ -0,0 +1,31 @@
+package printer // want package:"&{}"
+
+import "fmt"
+
+type T struct{ name string }
+
+func (t *T) String() string { // want String:"&map\\[0:{}\\]"
+	return t.name
+}
+
+type E struct{ msg string }
+
+func (e *E) Error() string { // want Error:"&map\\[0:{}\\]"
+	return e.msg
+}
+
+func find(name string) *T { // want find:"nilReturns\\[0\\]"
+	if name == "" {
+		return nil
+	}
+	return &T{name}
+}
+
+func use(name string) {
+	var t *T
+	fmt.Println(t)                    // want "String of a nil \\*T dereferences the receiver, so fmt.Println prints <nil>"
+	_ = fmt.Sprintf("%v", find(name)) // want "String of a possibly nil \\*T dereferences the receiver, so fmt.Sprintf prints <nil>"
+	var e *E
+	_ = fmt.Errorf("wrapped: %v", e) // want "Error of a nil \\*E dereferences the receiver, so fmt.Errorf prints <nil>"
+	fmt.Println(&T{name})
+}
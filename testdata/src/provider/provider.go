@@ -0,0 +1,10 @@
+package provider // want package:"&{}"
+
+func deref(p *int) int { // want deref:"&map\\[0:{}\\]"
+	return *p
+}
+
+func g() {
+	// Not reported because the analyzer only provides facts.
+	deref(nil)
+}
@@ -0,0 +1,71 @@
+package rangelit // want package:"&{}"
+
+import "errors"
+
+type T struct{ name string }
+
+// pick returns one of the elements of a literal of non-nil elements, so
+// its result isn't nil when the error is.
+func pick(name string) (*T, error) { // want pick:"nilReturns\\[0\\]" pick:"nonNilOnSuccess\\[0\\]"
+	for _, t := range []*T{{"a"}, {"b"}} {
+		if t.name == name {
+			return t, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// pickPartial can return the nil element of its literal.
+func pickPartial(name string) (*T, error) { // want pickPartial:"nilReturns\\[0\\]"
+	for _, t := range []*T{{"a"}, nil} {
+		if t != nil && t.name == name {
+			return t, nil
+		}
+	}
+	for _, t := range []*T{{"c"}, nil} {
+		if name == "" {
+			return t, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// pickShared ranges over a literal which escapes to modify, which can
+// replace its elements.
+func pickShared(name string) (*T, error) { // want pickShared:"nilReturns\\[0\\]"
+	ts := []*T{{"a"}}
+	modify(ts)
+	for _, t := range ts {
+		if name == "" {
+			return t, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func modify(ts []*T) { // want modify:"&map\\[0:{}\\]"
+	ts[0] = nil
+}
+
+func use(t *T) string { // want use:"&map\\[0:{}\\]"
+	return t.name
+}
+
+func caller() string {
+	t, err := pick("a")
+	if err != nil {
+		return ""
+	}
+	u, err := pickPartial("a")
+	if err != nil {
+		return ""
+	}
+	return use(t) + use(u) // want "this call can cause panic: the result of pickPartial can be nil"
+}
+
+// swapped stores elements of the literal into itself.
+func swapped() string {
+	a := [2]*T{{}, {}}
+	a[0], a[1] = a[1], a[0]
+	return use(a[0])
+}
@@ -0,0 +1,46 @@
+package readonly // want package:"&{}"
+
+// Reading from, ranging over and deleting from a nil map don't panic.
+
+func get(m map[string]int, k string) int {
+	return m[k]
+}
+
+func lookup(m map[string]int, k string) (int, bool) {
+	v, ok := m[k]
+	return v, ok
+}
+
+func sum(m map[string]int) int {
+	n := len(m)
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func del(m map[string]int, k string) {
+	delete(m, k)
+}
+
+// count reads and writes m, and only the write panics.
+func count(m map[string]int, k string) int { // want count:"&map\\[0:{}\\]"
+	if m[k] > 0 {
+		return m[k]
+	}
+	m[k]++
+	return m[k]
+}
+
+func viaCount(m map[string]int) int { // want viaCount:"&map\\[0:{}\\]"
+	return count(m, "a")
+}
+
+func use() {
+	get(nil, "a")
+	lookup(nil, "a")
+	sum(nil)
+	del(nil, "a")
+	count(nil, "a") // want "this call can cause panic: m is written to in count"
+	viaCount(nil)   // want "this call can cause panic: m is passed to count in viaCount"
+}
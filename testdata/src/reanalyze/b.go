@@ -0,0 +1,17 @@
+package reanalyze
+
+func viaGet(p *T) int { // want viaGet:"&map\\[0:{}\\]"
+	return get(p)
+}
+
+func callsViaGet() int {
+	return viaGet(nil) // want "this call can cause panic"
+}
+
+func unrelated(p *T) int { // want unrelated:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func callsUnrelated() int {
+	return unrelated(nil) // want "this call can cause panic"
+}
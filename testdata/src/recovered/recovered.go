@@ -0,0 +1,52 @@
+package recovered // want package:"&{}"
+
+type T struct{ x int }
+
+func safely(p *T) (n int) {
+	defer func() {
+		if recover() != nil {
+			n = -1
+		}
+	}()
+	return p.x
+}
+
+func handle() {
+	recover()
+}
+
+func named(p *T) int {
+	defer handle()
+	return p.x
+}
+
+// late dereferences p before deferring the recover.
+func late(p *T) int { // want late:"&map\\[0:{}\\]"
+	n := p.x
+	defer handle()
+	return n
+}
+
+// indirect defers a function which doesn't call recover itself, so it
+// recovers nothing.
+func indirect(p *T) int { // want indirect:"&map\\[0:{}\\]"
+	defer func() { handle() }()
+	return p.x
+}
+
+// repanicked recovers the panic only to panic again.
+func repanicked(p *T) int { // want repanicked:"&map\\[0:{}\\]"
+	defer func() {
+		if r := recover(); r != nil {
+			panic(r)
+		}
+	}()
+	return p.x
+}
+
+func use() {
+	safely(nil)
+	named(nil)
+	late(nil)       // want "this call can cause panic"
+	repanicked(nil) // want "this call can cause panic"
+}
@@ -0,0 +1,22 @@
+package recv // want package:"&{}"
+
+type T struct{ s string }
+
+// String handles nil receivers.
+func (t *T) String() string { // want String:"&{}"
+	if t == nil {
+		return "<nil>"
+	}
+	return t.s
+}
+
+// Name doesn't.
+func (t *T) Name() string { // want Name:"&map\\[0:{}\\]"
+	return t.s
+}
+
+func g() {
+	var t *T
+	_ = t.String()
+	_ = t.Name() // want "this call can cause panic"
+}
@@ -0,0 +1,45 @@
+package registry // want package:"&{}"
+
+type T struct{ x int }
+
+type HandlerFunc func(*T) int
+
+func handleA(p *T) int { // want handleA:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func handleB(p *T) int {
+	return 0
+}
+
+func handleC(p *T) int { // want handleC:"&map\\[0:{}\\]"
+	return p.x + 1
+}
+
+var handlers = map[string]HandlerFunc{
+	"a": handleA,
+	"b": handleB,
+}
+
+func init() {
+	handlers["c"] = handleC
+}
+
+var safe = map[string]HandlerFunc{"b": handleB}
+
+func dispatch(name string, p *T) int { // want dispatch:"&map\\[1:{}\\]"
+	return handlers[name](p)
+}
+
+func dispatchOK(name string, p *T) int { // want dispatchOK:"&map\\[1:{}\\]"
+	if h, ok := handlers[name]; ok {
+		return h(p)
+	}
+	return 0
+}
+
+func use(name string) {
+	handlers[name](nil) // want "this call can cause panic: the registered handleA panics on nil"
+	safe[name](nil)
+	dispatch(name, nil) // want "this call can cause panic"
+}
@@ -0,0 +1,13 @@
+package b // want package:"&{}"
+
+type T struct{ x int }
+
+// B dereferences only its second argument.
+func B(x, y *T) int { // want B:"&map\\[1:{}\\]"
+	return y.x
+}
+
+// C dereferences only its first argument.
+func C(x *T) int { // want C:"&map\\[0:{}\\]"
+	return x.x
+}
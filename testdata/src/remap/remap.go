@@ -0,0 +1,19 @@
+package remap // want package:"&{}"
+
+import "remap/b"
+
+// permuted passes y as the first argument of B, which is safe, and x
+// as the second one.
+func permuted(x, y *b.T) int { // want permuted:"&map\\[0:{}\\]"
+	return b.B(y, x)
+}
+
+// dropped forwards only its last argument.
+func dropped(x, y, z *b.T) int { // want dropped:"&map\\[2:{}\\]"
+	return b.C(z)
+}
+
+// duplicated passes x as both arguments.
+func duplicated(x, y *b.T) int { // want duplicated:"&map\\[0:{}\\]"
+	return b.B(x, x)
+}
@@ -0,0 +1,130 @@
+package ret // want package:"&{}"
+
+type T struct{ x int }
+
+func find(ok bool) *T { // want find:"nilReturns\\[0\\]"
+	if ok {
+		return &T{}
+	}
+	return nil
+}
+
+func same(p *T) *T { // want same:"identityReturns\\[0:0\\]"
+	return p
+}
+
+func checked(p *T) *T {
+	if p != nil {
+		return p
+	}
+	return &T{}
+}
+
+func lookup() (*T, error) { // want lookup:"nilReturns\\[0\\]" lookup:"correlatedReturns\\[\\[0 1\\]\\]"
+	return nil, nil
+}
+
+func use() int {
+	t := find(true)
+	return t.x // want "the result of find can be nil"
+}
+
+func useChecked() int {
+	if t := find(true); t != nil {
+		return t.x
+	}
+	return 0
+}
+
+func useTuple() int {
+	t, _ := lookup()
+	return t.x // want "the result of lookup can be nil"
+}
+
+func useNonNil() int {
+	return checked(nil).x
+}
+
+func id(p *T) *T { // want id:"identityReturns\\[0:0\\]"
+	return p
+}
+
+func deref(p *T) int { // want deref:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func useIdentity() int {
+	t := id(nil)
+	return t.x // want "the result of id is nil"
+}
+
+func passIdentity() int {
+	return deref(id(nil)) // want "this call can cause panic"
+}
+
+func passIdentityChecked(p *T) int {
+	if p == nil {
+		return 0
+	}
+	return deref(id(p))
+}
+
+type U struct{ y int }
+
+func pair(ok bool) (*T, *U) { // want pair:"nilReturns\\[0 1\\]" pair:"correlatedReturns\\[\\[0 1\\]\\]"
+	if ok {
+		return &T{}, &U{}
+	}
+	return nil, nil
+}
+
+func usePair() int {
+	t, u := pair(true)
+	if u != nil {
+		return t.x + u.y
+	}
+	return 0
+}
+
+func passPair() int {
+	t, u := pair(true)
+	if u == nil {
+		return deref(t) // want "this call can cause panic"
+	}
+	return u.y
+}
+
+type notFound struct{}
+
+func (notFound) Error() string { return "not found" }
+
+func open(ok bool) (*T, error) { // want open:"nilReturns\\[0\\]" open:"nonNilOnSuccess\\[0\\]"
+	if !ok {
+		return nil, notFound{}
+	}
+	return &T{}, nil
+}
+
+func useOpen() int {
+	t, err := open(true)
+	if err != nil {
+		return 0
+	}
+	return t.x
+}
+
+func useOpenUnchecked() int {
+	t, _ := open(true)
+	return t.x // want "the result of open can be nil"
+}
+
+func describe(err error) string { // want describe:"&map\\[0:{}\\]"
+	return err.Error()
+}
+
+// useErr passes the error of open, which is nil on success rather than
+// a nil result.
+func useErr() string {
+	_, err := open(true)
+	return describe(err)
+}
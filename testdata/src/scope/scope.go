@@ -0,0 +1,32 @@
+package scope // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func set(p *T) { // want set:"&map\\[0:{}\\]"
+	p.x = 1
+}
+
+func helper() int {
+	return get(nil) // want "this call can cause panic: p is dereferenced in get"
+}
+
+func Run() int {
+	f := func() {
+		set(nil) // want "this call can cause panic: p is dereferenced in set"
+	}
+	f()
+	return helper()
+}
+
+// other and T.M aren't called by Run, so they aren't reported.
+func other() {
+	set(nil)
+}
+
+func (t *T) M() int {
+	return get(nil)
+}
@@ -0,0 +1,49 @@
+package shortcircuit // want package:"&{}"
+
+type T struct{ x int }
+
+func both(p, q *T) int {
+	if p != nil && q != nil {
+		return p.x + q.x
+	}
+	return 0
+}
+
+func either(p *T, xs []int) int {
+	if p == nil || len(xs) == 0 {
+		return 0
+	}
+	return p.x + xs[0]
+}
+
+func stored(p, q *T) int {
+	ok := p != nil && q != nil
+	if ok {
+		return p.x + q.x
+	}
+	return 0
+}
+
+func negated(p, q *T) int {
+	bad := p == nil || q == nil
+	if !bad {
+		return p.x + q.x
+	}
+	return 0
+}
+
+func onlyOne(p, q *T) int { // want onlyOne:"&map\\[0:{}\\]"
+	ok := p != nil || q != nil
+	if ok {
+		return p.x
+	}
+	return 0
+}
+
+func use() {
+	both(nil, nil)
+	either(nil, nil)
+	stored(nil, nil)
+	negated(nil, nil)
+	onlyOne(nil, &T{}) // want "this call can cause panic: p is dereferenced in onlyOne"
+}
@@ -0,0 +1,21 @@
+package signature // want package:"&{}"
+
+type T struct{ x, y int }
+
+func sum(p *T) int { // want sum:"&map\\[0:{}\\]" "parameter p is only read and must not be nil; consider changing \\*T to T \\(2 call sites\\)"
+	return p.x + p.y
+}
+
+func reset(p *T) { // want reset:"&map\\[0:{}\\]"
+	p.x = 0
+}
+
+func (t *T) get() int { // want get:"&map\\[0:{}\\]"
+	return t.x
+}
+
+func g() {
+	t := &T{}
+	reset(t)
+	_ = sum(t) + sum(t) + t.get()
+}
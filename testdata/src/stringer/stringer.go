@@ -0,0 +1,51 @@
+package stringer // want package:"&{}"
+
+type Stringer interface{ String() string }
+
+type T struct{ name string }
+
+func (t *T) String() string { // want String:"&map\\[0:{}\\]"
+	return t.name
+}
+
+type U struct{ name string }
+
+func (u *U) String() string { // want String:"&{}"
+	if u == nil {
+		return "<nil>"
+	}
+	return u.name
+}
+
+type V struct{ name string }
+
+func (v V) String() string { return v.name }
+
+func logf(format string, args ...interface{}) string { // want logf:"stringerCalls\\[1:\\[String\\]\\]"
+	for _, a := range args {
+		if s, ok := a.(Stringer); ok {
+			format += s.String()
+		}
+	}
+	return format
+}
+
+func debugf(format string, args ...interface{}) string { // want debugf:"stringerCalls\\[1:\\[String\\]\\]"
+	return logf("debug: "+format, args...)
+}
+
+func show(v interface{}) string { // want show:"&map\\[0:{}\\]" show:"stringerCalls\\[0:\\[String\\]\\]"
+	return v.(Stringer).String()
+}
+
+func use() {
+	var t *T
+	var u *U
+	var v *V
+	logf("%v", t)   // want "this call can cause panic: logf calls String on a nil \\*T"
+	debugf("%v", t) // want "this call can cause panic: debugf calls String on a nil \\*T"
+	logf("%v", u)
+	logf("%v", v) // want "this call can cause panic: logf calls String on a nil \\*V"
+	logf("%v", &T{})
+	show(t) // want "this call can cause panic: show calls String on a nil \\*T"
+}
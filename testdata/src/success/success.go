@@ -0,0 +1,53 @@
+package success // want package:"&{}"
+
+import "errors"
+
+type T struct{ name string }
+
+// New wraps open, declared after it, and returns its result only when
+// the error is nil.
+func New(name string) (*T, error) { // want New:"nilReturns\\[0\\]" New:"nonNilOnSuccess\\[0\\]"
+	t, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Forward returns both results of open.
+func Forward(name string) (*T, error) { // want Forward:"nilReturns\\[0\\]" Forward:"nonNilOnSuccess\\[0\\]"
+	return open(name)
+}
+
+// Leak returns the result of open even when the error isn't nil.
+func Leak(name string) (*T, error) { // want Leak:"nilReturns\\[0\\]"
+	t, _ := open(name)
+	return t, nil
+}
+
+func open(name string) (*T, error) { // want open:"nilReturns\\[0\\]" open:"nonNilOnSuccess\\[0\\]"
+	if name == "" {
+		return nil, errors.New("empty name")
+	}
+	return &T{name}, nil
+}
+
+func use(t *T) string { // want use:"&map\\[0:{}\\]"
+	return t.name
+}
+
+func caller() string {
+	t, err := New("a")
+	if err != nil {
+		return ""
+	}
+	u, err := Forward("b")
+	if err != nil {
+		return ""
+	}
+	v, err := Leak("c")
+	if err != nil {
+		return ""
+	}
+	return use(t) + use(u) + use(v) // want "this call can cause panic: the result of Leak can be nil: t is dereferenced in use"
+}
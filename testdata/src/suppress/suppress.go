@@ -0,0 +1,28 @@
+package suppress // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func ignored() {
+	get(nil) //nilarg:ignore
+	//nilarg:ignore until=2999-01-01 until the callers are fixed
+	get(nil)
+}
+
+func expired() {
+	//nilarg:ignore until=2000-01-01
+	get(nil) // want "this call can cause panic: p is dereferenced in get \\(the suppression expired on 2000-01-01\\)"
+}
+
+func malformed() {
+	//nilarg:ignore until=2025-13-01 // want "this suppression has a malformed expiry: until=2025-13-01: want until=YYYY-MM-DD"
+	get(nil) // want "this call can cause panic: p is dereferenced in get"
+}
+
+func unknown() {
+	//nilarg:ignored
+	get(nil) // want "this call can cause panic: p is dereferenced in get"
+}
@@ -0,0 +1,7 @@
+package tcase // want package:"&{}"
+
+type T struct{ x int }
+
+func Get(p *T) int { // want Get:"&map\\[0:{}\\]"
+	return p.x
+}
@@ -0,0 +1,65 @@
+package tcase
+
+func withNil() int {
+	tests := []struct {
+		name string
+		p    *T
+	}{
+		{"empty", nil},
+		{"set", &T{}},
+	}
+	s := 0
+	for _, tt := range tests {
+		s += Get(tt.p) // want `this call can cause panic: test case "empty" has nil p`
+	}
+	for i := range tests {
+		s += Get(tests[i].p) // want `this call can cause panic: test case "empty" has nil p`
+	}
+	return s
+}
+
+func omitted() int {
+	tests := []struct{ p *T }{
+		{&T{}},
+		{},
+	}
+	s := 0
+	for _, tt := range tests {
+		s += Get(tt.p) // want "this call can cause panic: test case 1 has nil p"
+	}
+	return s
+}
+
+func withoutNil() int {
+	tests := []struct{ p *T }{
+		{&T{}},
+		{&T{x: 1}},
+	}
+	s := 0
+	for _, tt := range tests {
+		s += Get(tt.p)
+	}
+	return s
+}
+
+type tc struct{ p *T }
+
+func mk() tc { return tc{&T{}} }
+
+func whole() int {
+	tests := []tc{mk(), mk()}
+	s := 0
+	for _, tt := range tests {
+		s += Get(tt.p)
+	}
+	return s
+}
+
+func pointers() int {
+	tests := []*tc{{p: &T{}}, {p: nil}}
+	s := 0
+	for _, tt := range tests {
+		s += Get(tt.p) // want "this call can cause panic: test case 1 has nil p"
+	}
+	return s
+}
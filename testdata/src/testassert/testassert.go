@@ -0,0 +1,9 @@
+package testassert // want package:"&{}"
+
+type Store interface{ Get() int }
+
+type T struct{ x int }
+
+func deref(p *T) int { // want deref:"&map\\[0:{}\\]"
+	return p.x
+}
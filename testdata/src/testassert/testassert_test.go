@@ -0,0 +1,26 @@
+package testassert
+
+import "testing"
+
+type mock struct{ calls int }
+
+func (m *mock) Get() int { // want Get:"&map\\[0:{}\\]"
+	m.calls++
+	return 0
+}
+
+// asMock asserts the type of the store a test passes to the code under
+// test.
+func asMock(s Store) *mock { // want asMock:"&map\\[0:{}\\]"
+	return s.(*mock)
+}
+
+func TestGet(t *testing.T) { // want TestGet:"&map\\[0:{}\\]"
+	var s Store
+	if asMock(s).calls != 0 { // want "this call can cause panic: s is type asserted in asMock"
+		t.Fatal("called")
+	}
+	if deref(nil) != 0 { // want "this call can cause panic: p is dereferenced in deref"
+		t.Fatal("nonzero")
+	}
+}
@@ -0,0 +1,10 @@
+package testify // want package:"&{}"
+
+type T struct{ x int }
+
+func load(name string) *T { // want load:"nilReturns\\[0\\]"
+	if name == "" {
+		return nil
+	}
+	return &T{}
+}
@@ -0,0 +1,38 @@
+package testify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(p *T) int { // want get:"&map\\[0:{}\\]"
+	return p.x
+}
+
+func TestRequire(t *testing.T) {
+	p := load("a")
+	require.NotNil(t, p)
+	_ = get(p)
+}
+
+func TestAssertions(t *testing.T) {
+	r := require.New(t)
+	p := load("a")
+	r.NotNil(p)
+	_ = get(p)
+}
+
+func TestAssert(t *testing.T) {
+	p := load("a")
+	if assert.NotNil(t, p) {
+		_ = get(p)
+	}
+}
+
+func TestAssertOnly(t *testing.T) {
+	p := load("a")
+	assert.NotNil(t, p)
+	_ = get(p) // want "the result of load can be nil"
+}
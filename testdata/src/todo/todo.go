@@ -0,0 +1,22 @@
+package todo // want package:"&{}"
+
+type T struct{ x int }
+
+func get(p *T, m map[string]int) int { // want get:"&map\\[0:{}\\ 1:{}\\]"
+	m["x"] = p.x // want "p may be nil here" "m may be nil here"
+	if p.x > 0 { // want "p may be nil here"
+		return p.x // want "p may be nil here"
+	}
+	return 0
+}
+
+func checked(p *T) int { // want checked:"&map\\[0:{}\\]"
+	if p != nil {
+		return p.x
+	}
+	return *new(int) + p.x // want "p may be nil here"
+}
+
+func use() int {
+	return get(nil, nil)
+}
@@ -0,0 +1,42 @@
+package typednil // want package:"&{}"
+
+type Closer interface{ Close() }
+
+type File struct{ fd int }
+
+func (f *File) Close() { f.fd = -1 } // want Close:"&map\\[0:{}\\]"
+
+// Conn doesn't dereference nil receivers.
+type Conn struct{ open bool }
+
+func (c *Conn) Close() { // want Close:"&{}"
+	if c != nil {
+		c.open = false
+	}
+}
+
+func closeIf(c Closer) { // want closeIf:"typedNilChecks\\[0:\\[Close\\]\\]"
+	if c != nil {
+		c.Close()
+	}
+}
+
+func open(name string) *File { // want open:"nilReturns\\[0\\]"
+	if name == "" {
+		return nil
+	}
+	return &File{}
+}
+
+func use() {
+	var f *File
+	closeIf(f)        // want "this call can cause panic: a nil \\*File passed as c isn't a nil interface, so closeIf calls Close on it after checking c against nil"
+	closeIf(open("")) // want "a nil \\*File passed as c isn't a nil interface"
+	closeIf(&File{})
+	closeIf(nil)
+	var c *Conn
+	closeIf(c)
+	if g := open("a"); g != nil {
+		closeIf(g)
+	}
+}
@@ -0,0 +1,13 @@
+package typednillib // want package:"&{}"
+
+type Closer interface{ Close() }
+
+type File struct{ fd int }
+
+func (f *File) Close() { f.fd = -1 } // want Close:"&map\\[0:{}\\]"
+
+func CloseIf(c Closer) { // want CloseIf:"typedNilChecks\\[0:\\[Close\\]\\]"
+	if c != nil {
+		c.Close()
+	}
+}
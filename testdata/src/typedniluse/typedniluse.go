@@ -0,0 +1,9 @@
+package typedniluse // want package:"&{}"
+
+import "typednillib"
+
+func use() {
+	var f *typednillib.File
+	typednillib.CloseIf(f) // want "a nil \\*typednillib.File passed as c isn't a nil interface, so CloseIf calls Close on it"
+	typednillib.CloseIf(&typednillib.File{})
+}
@@ -0,0 +1,240 @@
+package validation // want package:"&{}"
+
+// The functions from errorsNew on validate their arguments against nil in
+// the idioms of Go before using them, returning errors made or wrapped in
+// various ways, so that none of them may have facts that their arguments
+// panic, only that they validate them.
+
+import (
+	"errors"
+	"fmt"
+)
+
+type T struct{ x int }
+
+var ErrNil = errors.New("nil argument")
+
+type argError struct{ name string }
+
+func (e *argError) Error() string { return e.name + " is nil" } // want Error:"&map\\[0:{}\\]"
+
+func wrap(err error, msg string) error {
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+func errNil(name string) error {
+	return &argError{name}
+}
+
+func invalid(format string, args ...interface{}) error {
+	return wrap(fmt.Errorf(format, args...), "invalid argument")
+}
+
+func annotate(err *error, op string) { // want annotate:"&map\\[0:{}\\]"
+	if *err != nil {
+		*err = wrap(*err, op)
+	}
+}
+
+func errorsNew(p *T) (int, error) { // want errorsNew:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, errors.New("p is nil")
+	}
+	return p.x, nil
+}
+
+func errorf(p *T) (int, error) { // want errorf:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, fmt.Errorf("p is nil")
+	}
+	return p.x, nil
+}
+
+func errorfWrapped(p *T) (int, error) { // want errorfWrapped:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, fmt.Errorf("load: %w", ErrNil)
+	}
+	return p.x, nil
+}
+
+func sentinel(p *T) (int, error) { // want sentinel:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, ErrNil
+	}
+	return p.x, nil
+}
+
+func wrapped(p *T) (int, error) { // want wrapped:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, wrap(errors.New("p is nil"), "load")
+	}
+	return p.x, nil
+}
+
+func helper(p *T) (int, error) { // want helper:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, errNil("p")
+	}
+	return p.x, nil
+}
+
+func variadicHelper(p *T) (int, error) { // want variadicHelper:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, invalid("%s is nil", "p")
+	}
+	return p.x, nil
+}
+
+func typed(p *T) (int, error) { // want typed:"validatedArgs\\[0\\]"
+	if p == nil {
+		return 0, &argError{"p"}
+	}
+	return p.x, nil
+}
+
+func named(p *T) (n int, err error) { // want named:"validatedArgs\\[0\\]"
+	if p == nil {
+		err = errors.New("p is nil")
+		return
+	}
+	n = p.x
+	return
+}
+
+func deferred(p *T) (n int, err error) {
+	defer annotate(&err, "deferred")
+	if p == nil {
+		return 0, ErrNil
+	}
+	return p.x, nil
+}
+
+func deferredClosure(p *T) (n int, err error) {
+	defer func() {
+		if err != nil {
+			err = wrap(err, "deferredClosure")
+		}
+	}()
+	if p == nil {
+		return 0, ErrNil
+	}
+	return p.x, nil
+}
+
+func either(p, q *T) (int, error) { // want either:"validatedArgs\\[0 1\\]"
+	if p == nil || q == nil {
+		return 0, ErrNil
+	}
+	return p.x + q.x, nil
+}
+
+func each(p, q *T) (int, error) { // want each:"validatedArgs\\[0 1\\]"
+	if p == nil {
+		return 0, errNil("p")
+	}
+	if q == nil {
+		return 0, errNil("q")
+	}
+	return p.x + q.x, nil
+}
+
+func switched(p *T) (int, error) { // want switched:"validatedArgs\\[0\\]"
+	switch {
+	case p == nil:
+		return 0, ErrNil
+	}
+	return p.x, nil
+}
+
+func elseBranch(p *T) (int, error) { // want elseBranch:"validatedArgs\\[0\\]"
+	if p != nil {
+		return p.x, nil
+	} else {
+		return 0, ErrNil
+	}
+}
+
+func logged(p *T) (int, error) { // want logged:"validatedArgs\\[0\\]"
+	if p == nil {
+		err := errNil("p")
+		fmt.Println(err)
+		return 0, err
+	}
+	return p.x, nil
+}
+
+func errOnly(p *T) error { // want errOnly:"validatedArgs\\[0\\]"
+	if p == nil {
+		return fmt.Errorf("errOnly: %w", errNil("p"))
+	}
+	p.x++
+	return nil
+}
+
+func loop(ps []*T) (int, error) {
+	n := 0
+	for i, p := range ps {
+		if p == nil {
+			return 0, fmt.Errorf("ps[%d] is nil", i)
+		}
+		n += p.x
+	}
+	return n, nil
+}
+
+func mapArg(m map[string]int) error { // want mapArg:"validatedArgs\\[0\\]"
+	if m == nil {
+		return errNil("m")
+	}
+	m["x"] = 1
+	return nil
+}
+
+func iface(s fmt.Stringer) (string, error) { // want iface:"validatedArgs\\[0\\]" iface:"typedNilChecks\\[0:\\[String\\]\\]"
+	if s == nil {
+		return "", ErrNil
+	}
+	return s.String(), nil
+}
+
+func panics(p *T) int {
+	if p == nil {
+		panic(errNil("p"))
+	}
+	return p.x
+}
+
+func notNil(p *T) error { // want notNil:"validatedArgs\\[0\\]"
+	if p == nil {
+		return ErrNil
+	}
+	return nil
+}
+
+func wrapIf(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, msg)
+}
+
+func checkErr(p *T) error { // want checkErr:"validatedArgs\\[0\\]"
+	if err := notNil(p); err != nil {
+		return wrap(err, "checkErr")
+	}
+	return nil
+}
+
+func validated(p *T) (int, error) { // want validated:"validatedArgs\\[0\\]"
+	if err := notNil(p); err != nil {
+		return 0, wrap(err, "validated")
+	}
+	return p.x, nil
+}
+
+func validatedTwice(p *T) (int, error) { // want validatedTwice:"validatedArgs\\[0\\]"
+	if err := checkErr(p); err != nil {
+		return 0, fmt.Errorf("validatedTwice: %w", err)
+	}
+	return p.x, nil
+}
@@ -0,0 +1,40 @@
+package validator // want package:"&{}"
+
+type Header struct{ n int }
+
+type Request struct {
+	Header *Header
+	Body   *Header
+}
+
+type invalid struct{}
+
+func (invalid) Error() string { return "invalid request" }
+
+func validate(req *Request) error { // want validate:"&map\\[0:{}\\]" validate:"validatedArgs\\[0.Header\\]"
+	if req.Header == nil {
+		return invalid{}
+	}
+	return nil
+}
+
+func handle(req *Request) (int, error) { // want handle:"&map\\[0:{}\\]"
+	if err := validate(req); err != nil {
+		return 0, err
+	}
+	return req.Header.n, nil
+}
+
+// unchecked doesn't check the error of validate.
+func unchecked(req *Request) int { // want unchecked:"&map\\[0:{}\\]" unchecked:"panicFields\\[0.Header\\]"
+	validate(req)
+	return req.Header.n
+}
+
+// body dereferences a field validate doesn't check.
+func body(req *Request) (int, error) { // want body:"&map\\[0:{}\\]" body:"panicFields\\[0.Body\\]"
+	if err := validate(req); err != nil {
+		return 0, err
+	}
+	return req.Body.n, nil
+}
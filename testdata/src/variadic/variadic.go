@@ -0,0 +1,61 @@
+package variadic // want package:"&{}"
+
+type Conn struct{ open bool }
+
+func (c *Conn) Close() { // want Close:"&map\\[0:{}\\]"
+	c.open = false
+}
+
+// closeAll closes every connection, so none of them can be nil.
+func closeAll(cs ...*Conn) { // want closeAll:"variadicElems\\[all\\]"
+	for _, c := range cs {
+		c.Close()
+	}
+}
+
+// first only dereferences the first connection.
+func first(name string, cs ...*Conn) bool { // want first:"&map\\[1:{}\\]" first:"variadicElems\\[0\\]"
+	return cs[0].open
+}
+
+// closeOpen checks the connections.
+func closeOpen(cs ...*Conn) {
+	for _, c := range cs {
+		if c != nil {
+			c.Close()
+		}
+	}
+}
+
+// shutdown passes c as one of the variadic arguments.
+func shutdown(c *Conn) { // want shutdown:"&map\\[0:{}\\]"
+	closeAll(c)
+}
+
+func use(c *Conn, cs []*Conn) { // want use:"&map\\[0:{}\\]"
+	closeAll(c, nil) // want "this call can cause panic: an element of cs is passed to Close in closeAll"
+	closeAll(c, c)
+	closeAll(cs...)
+	closeAll()
+	closeOpen(nil, c)
+	first("a", c, nil)
+	first("a", nil, c) // want "this call can cause panic: an element of cs is dereferenced in first"
+	first("a")         // want "this call can cause panic: cs is indexed in first"
+	closeAll(nil /* nilarg: closed elsewhere first */)
+}
+
+type Msg struct{ body string }
+
+func (c *Conn) Send(m *Msg) { // want Send:"&map\\[0:{} 1:{}\\]"
+	if c.open {
+		println(m.body)
+	}
+}
+
+// boundCall calls Send through a method value, whose arguments come
+// after the receiver bound to it.
+func boundCall(c *Conn) { // want boundCall:"&map\\[0:{}\\]"
+	send := c.Send
+	send(nil) // want "this call can cause panic: m is dereferenced in Send"
+	send(&Msg{})
+}
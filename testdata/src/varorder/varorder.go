@@ -0,0 +1,14 @@
+package varorder // want package:"&{}"
+
+// early passes f to runAll, declared after it, so it only sees the fact
+// of runAll in the next round of the fixpoint, which the new fact alone
+// must start.
+func early(f func()) { // want early:"&map\\[0:{}\\]"
+	runAll(f)
+}
+
+func runAll(fs ...func()) { // want runAll:"variadicElems\\[all\\]"
+	for _, f := range fs {
+		f()
+	}
+}
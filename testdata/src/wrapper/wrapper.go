@@ -0,0 +1,34 @@
+package wrapper // want package:"&{}"
+
+type T struct{ n int }
+
+// Inc panics on a nil receiver but accepts a nil p.
+func (t *T) Inc(p *int) { // want Inc:"&map\\[0:{}\\]"
+	t.n++
+	if p != nil {
+		t.n += *p
+	}
+}
+
+type V struct{ n int }
+
+func (v V) Get(p *int) int { // want Get:"&map\\[1:{}\\]"
+	return v.n + *p
+}
+
+type S struct{ *T }
+
+func use(t *T, v *V, s S) { // want use:"&map\\[0:{}\\]"
+	// The method value is bound to t, so the argument 0 of the
+	// call is p, not the receiver.
+	inc := t.Inc
+	inc(nil)
+
+	// (*V).Get is the wrapper of V.Get taking the receiver first.
+	get := (*V).Get
+	get(v, nil) // want "this call can cause panic"
+
+	// S.Inc is promoted from the embedded *T.
+	promoted := S.Inc
+	promoted(s, nil)
+}
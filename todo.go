@@ -0,0 +1,70 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// todos makes the analyzer report only the unguarded dereferences of
+// the flagged parameters, suggesting TODO comments above them, so that
+// the dereferences can be marked before adding any checks.
+var todos bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&todos, "todos", false,
+		"report only unguarded dereferences of flagged parameters, suggesting TODO comments above them")
+}
+
+// todoCategory is the category of the diagnostics reported with todos.
+const todoCategory = "todo"
+
+// suggestTodos reports the statements of fns dereferencing a parameter
+// for which the function has a panicArgs fact without a nil check, with
+// a fix inserting a TODO comment above the statement.
+func suggestTodos(pass *analysis.Pass, fns []*ssa.Function) {
+	if !todos {
+		return
+	}
+	for _, fn := range fns {
+		var fact panicArgs
+		if factObject(fn) == nil || !pass.ImportObjectFact(factObject(fn), &fact) {
+			continue
+		}
+		for i, fp := range fn.Params {
+			if _, ok := fact[i]; !ok || fp.Referrers() == nil {
+				continue
+			}
+			seen := make(map[ast.Stmt]bool)
+			for _, r := range *fp.Referrers() {
+				if !r.Pos().IsValid() || panicReason(pass, r, fp) == "" || isNilChecked(fp, r.Block()) {
+					continue
+				}
+				_, path := Node(pass.Files, r)
+				stmt := enclosingStmt(path)
+				if stmt == nil || seen[stmt] {
+					continue
+				}
+				seen[stmt] = true
+				text := fmt.Sprintf("// TODO(nilarg): %s may be nil; add check", fp.Name())
+				indent := strings.Repeat("\t", pass.Fset.Position(stmt.Pos()).Column-1)
+				reportDiag(pass, analysis.Diagnostic{
+					Pos:      stmt.Pos(),
+					Category: todoCategory,
+					Message:  fmt.Sprintf("%s may be nil here", fp.Name()),
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "Add a TODO comment",
+						TextEdits: []analysis.TextEdit{{
+							Pos:     stmt.Pos(),
+							End:     stmt.Pos(),
+							NewText: []byte(text + "\n" + indent),
+						}},
+					}},
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// typedNilChecks has the names of the methods which the function calls
+// on its interface parameters after checking them against nil, by the
+// parameter index, as in
+//
+//	func closeIf(c io.Closer) {
+//		if c != nil {
+//			c.Close()
+//		}
+//	}
+//
+// A nil pointer converted to the interface isn't a nil interface, so the
+// check passes and the method is called with the nil receiver.
+type typedNilChecks map[int][]string
+
+func (*typedNilChecks) AFact() {}
+
+func (c *typedNilChecks) String() string {
+	idx := make([]int, 0, len(*c))
+	for i := range *c {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var s []string
+	for _, i := range idx {
+		s = append(s, fmt.Sprintf("%d:%v", i, (*c)[i]))
+	}
+	return "typedNilChecks[" + strings.Join(s, " ") + "]"
+}
+
+// typedNilCategory is the category of the calls passing typed nils to
+// interfaces checked against nil.
+const typedNilCategory = "typed-nil"
+
+// checkTypedNilChecks exports typedNilChecks for fn if it calls methods
+// of its interface parameters only where they aren't nil.
+func checkTypedNilChecks(pass *analysis.Pass, fn *ssa.Function) {
+	if factObject(fn) == nil {
+		return
+	}
+	fact := typedNilChecks{}
+	for i, fp := range fn.Params {
+		if !types.IsInterface(fp.Type()) || fp.Referrers() == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, r := range *fp.Referrers() {
+			c, ok := r.(ssa.CallInstruction)
+			if !ok || !c.Common().IsInvoke() || c.Common().Value != fp || !isNilChecked(fp, c.Block()) {
+				continue
+			}
+			if name := c.Common().Method.Name(); !seen[name] {
+				seen[name] = true
+				fact[i] = append(fact[i], name)
+			}
+		}
+		sort.Strings(fact[i])
+	}
+	if len(fact) > 0 {
+		pass.ExportObjectFact(factObject(fn), &fact)
+	}
+}
+
+// checkTypedNil reports the call c if it passes a pointer which is or
+// can be nil as an interface argument which the callee checks against
+// nil before calling a method dereferencing the receiver, as in
+//
+//	var f *os.File
+//	closeIf(f)
+func checkTypedNil(pass *analysis.Pass, c ssa.CallInstruction, stack []fact) {
+	s := c.Common().StaticCallee()
+	if s == nil || factObject(s) == nil {
+		return
+	}
+	var fact typedNilChecks
+	if !pass.ImportObjectFact(factObject(s), &fact) {
+		return
+	}
+	params := factObject(s).Type().(*types.Signature).Params()
+	for i, methods := range fact {
+		if i >= len(c.Common().Args) || i >= params.Len() {
+			continue
+		}
+		mi, ok := c.Common().Args[i].(*ssa.MakeInterface)
+		if !ok {
+			continue
+		}
+		if _, ok := mi.X.Type().Underlying().(*types.Pointer); !ok {
+			continue
+		}
+		n := nilnessOf(pass, stack, mi.X)
+		if _, ok := mayReturnNil(pass, mi.X); n != isnil && !(n == unknown && ok) {
+			continue
+		}
+		for _, name := range methods {
+			if m := derefMethod(pass, c.Parent().Prog, mi.X.Type(), name); m != nil {
+				p := params.At(i).Name()
+				reportDiag(pass, analysis.Diagnostic{
+					Pos:      c.Pos(),
+					Category: typedNilCategory,
+					Message: fmt.Sprintf("this call can cause panic: a nil %s passed as %s isn't a nil interface, so %s calls %s on it after checking %s against nil",
+						types.TypeString(mi.X.Type(), types.RelativeTo(pass.Pkg)), p, s.Name(), name, p),
+				})
+				break
+			}
+		}
+	}
+}
+
+// derefMethod returns the method named name of t which panics when its
+// receiver is nil, or nil.
+func derefMethod(pass *analysis.Pass, prog *ssa.Program, t types.Type, name string) *ssa.Function {
+	ms := prog.MethodSets.MethodSet(t)
+	for k := 0; k < ms.Len(); k++ {
+		sel := ms.At(k)
+		if sel.Obj().Name() != name {
+			continue
+		}
+		m := prog.MethodValue(sel)
+		if m == nil || factObject(m) == nil {
+			return nil
+		}
+		var fact panicArgs
+		if !importPanicArgs(pass, factObject(m), &fact) || isNilSafeRecv(pass, factObject(m), 0) {
+			return nil
+		}
+		if _, ok := fact[0]; ok {
+			return m
+		}
+		return nil
+	}
+	return nil
+}
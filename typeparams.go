@@ -0,0 +1,18 @@
+//go:build !go1.19
+// +build !go1.19
+
+package nilarg
+
+import "go/types"
+
+// typeParamNillable reports whether t is a type parameter, which is only
+// told with the go/types of Go 1.19.
+func typeParamNillable(t types.Type) (nillable, ok bool) {
+	return false, false
+}
+
+// origin returns obj, as the functions have no instantiations before the
+// go/types of Go 1.19, where Func.Origin appeared.
+func origin(obj types.Object) types.Object {
+	return obj
+}
@@ -0,0 +1,67 @@
+//go:build go1.19
+// +build go1.19
+
+package nilarg
+
+import "go/types"
+
+// typeParamNillable reports whether t is a type parameter, and whether
+// all the types of its type set can be nil, as for
+//
+//	func F[P interface{ *T | map[string]int }](p P)
+//
+// The underlying type of a type parameter is its constraint, which is an
+// interface even for constraints such as any whose types can't be nil.
+func typeParamNillable(t types.Type) (nillable, ok bool) {
+	tp, ok := t.(*types.TypeParam)
+	if !ok {
+		return false, false
+	}
+	iface, _ := tp.Constraint().Underlying().(*types.Interface)
+	return iface != nil && nillableTerms(iface), true
+}
+
+// nillableTerms reports whether the type set of the constraint iface
+// only has types which can be nil. An element embedded in iface
+// restricting the set to such types is enough, since the type set is the
+// intersection of its elements, while the sets of methods only don't
+// restrict the types.
+func nillableTerms(iface *types.Interface) bool {
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		var terms []types.Type
+		switch e := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < e.Len(); j++ {
+				terms = append(terms, e.Term(j).Type())
+			}
+		default:
+			if inner, ok := e.Underlying().(*types.Interface); ok {
+				if nillableTerms(inner) {
+					return true
+				}
+				continue
+			}
+			terms = append(terms, e)
+		}
+		all := len(terms) > 0
+		for _, t := range terms {
+			all = all && isNillable(t)
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// origin returns the generic function or method which obj instantiates,
+// or obj itself, so that all the instantiations share the facts of their
+// origin. Nil arguments panic in the same way whatever the type
+// arguments are, as long as the parameter can be nil in all of them,
+// which typeParamNillable tells for type parameters.
+func origin(obj types.Object) types.Object {
+	if f, ok := obj.(*types.Func); ok {
+		return f.Origin()
+	}
+	return obj
+}
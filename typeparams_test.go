@@ -0,0 +1,115 @@
+//go:build go1.19
+// +build go1.19
+
+package nilarg
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// typeCheck type checks the source of the package p, which the SSA builder
+// of the analyzer can't build when it has generic functions.
+func typeCheck(t *testing.T, src string) (*types.Package, *types.Info) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg, info
+}
+
+func TestOrigin(t *testing.T) {
+	pkg, info := typeCheck(t, `package p
+
+type List[E any] struct{ head *E }
+
+func (l *List[E]) Get() E { return *l.head }
+
+func use(a *List[int], b *List[string]) {
+	a.Get()
+	b.Get()
+}
+`)
+	get := pkg.Scope().Lookup("List").Type().(*types.Named).Method(0)
+	instances := 0
+	for _, sel := range info.Selections {
+		if sel.Kind() != types.MethodVal {
+			continue
+		}
+		if sel.Obj() != get {
+			instances++
+		}
+		if got := origin(sel.Obj()); got != get {
+			t.Errorf("origin(%v) = %v, want %v", sel.Obj(), got, get)
+		}
+	}
+	if instances != 2 {
+		t.Errorf("found %d calls of instances of Get, want 2", instances)
+	}
+	if use := pkg.Scope().Lookup("use"); origin(use) != use {
+		t.Errorf("origin(use) = %v, want use itself", origin(use))
+	}
+}
+
+func TestTypeParamNillable(t *testing.T) {
+	pkg, _ := typeCheck(t, `package p
+
+type T struct{}
+
+type Ptrs interface{ *T | *int }
+
+type Stringer interface{ String() string }
+
+func Any[P any](p P)                                   {}
+func Ptr[P interface{ *T }](p P)                       {}
+func Union[P interface{ *T | map[string]int | []T }](p P) {}
+func Mixed[P interface{ *T | int }](p P)               {}
+func Tilde[P interface{ ~[]int | ~map[int]int }](p P)  {}
+func Nested[P interface{ Ptrs }](p P)                  {}
+func Methods[P Stringer](p P)                          {}
+func Both[P interface{ Stringer; *T }](p P)            {}
+func Comparable[P comparable](p P)                     {}
+func Plain(p *T, n int)                                {}
+`)
+	for name, want := range map[string]bool{
+		"Any":        false,
+		"Ptr":        true,
+		"Union":      true,
+		"Mixed":      false,
+		"Tilde":      true,
+		"Nested":     true,
+		"Methods":    false,
+		"Both":       true,
+		"Comparable": false,
+	} {
+		p := pkg.Scope().Lookup(name).Type().(*types.Signature).Params().At(0)
+		nillable, ok := typeParamNillable(p.Type())
+		if !ok || nillable != want {
+			t.Errorf("typeParamNillable(%s) = %v, %v; want %v, true", p.Type(), nillable, ok, want)
+		}
+		if isNillable(p.Type()) != want {
+			t.Errorf("isNillable(%s) = %v, want %v", p.Type(), !want, want)
+		}
+	}
+	plain := pkg.Scope().Lookup("Plain").Type().(*types.Signature).Params()
+	for i := 0; i < plain.Len(); i++ {
+		if _, ok := typeParamNillable(plain.At(i).Type()); ok {
+			t.Errorf("typeParamNillable(%s) reports a type parameter", plain.At(i).Type())
+		}
+	}
+}
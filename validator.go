@@ -0,0 +1,235 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// validatedArgs has the information about the fields of struct
+// arguments which a validator returning an error checks for nil, so
+// that they aren't nil when it returns a nil error, as in
+//
+//	func validate(req *Request) error {
+//		if req.Header == nil {
+//			return errors.New("no header")
+//		}
+//		return nil
+//	}
+//
+// The key is the argument index and the values are the field names, or
+// "" for the argument itself, which validators such as
+//
+//	func notNil(p *T) error {
+//		if p == nil {
+//			return errors.New("p is nil")
+//		}
+//		return nil
+//	}
+//
+// check.
+type validatedArgs map[int][]string
+
+func (*validatedArgs) AFact() {}
+
+func (f *validatedArgs) String() string {
+	var fields []string
+	for i, names := range *f {
+		for _, name := range names {
+			if name == "" {
+				fields = append(fields, fmt.Sprint(i))
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%d.%s", i, name))
+		}
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("validatedArgs%v", fields)
+}
+
+// checkValidators exports validatedArgs for the validators of fns until
+// no more are found, because validators can delegate to the validators
+// declared after them, as in
+//
+//	func check(p *T) error {
+//		if err := notNil(p); err != nil {
+//			return fmt.Errorf("check: %w", err)
+//		}
+//		return nil
+//	}
+func checkValidators(pass *analysis.Pass, fns []*ssa.Function) {
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range fns {
+			if checkValidator(pass, fn) {
+				changed = true
+			}
+		}
+	}
+}
+
+// checkValidator exports validatedArgs for fn if fn returns an error
+// last and checks some nillable arguments, or nillable fields of its
+// struct arguments, for nil on all the paths returning a nil error. It
+// reports whether the fact grew.
+func checkValidator(pass *analysis.Pass, fn *ssa.Function) bool {
+	res := fn.Signature.Results()
+	if factObject(fn) == nil || res.Len() == 0 || !types.Identical(res.At(res.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		return false
+	}
+	var success []*ssa.BasicBlock
+	for _, b := range fn.Blocks {
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok && isNil(ret.Results[len(ret.Results)-1]) {
+			success = append(success, b)
+		}
+	}
+	if len(success) == 0 {
+		return false
+	}
+	fact := validatedArgs{}
+	for i, fp := range fn.Params {
+		if isNillable(fp.Type()) && checkedOnAll(pass, fp, success) {
+			fact[i] = append(fact[i], "")
+		}
+		if structOf(fp.Type()) == nil || fp.Referrers() == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, fpr := range fieldReferrers(fp) {
+			name, loads := loadField(fpr)
+			for _, v := range loads {
+				if seen[name] || !isNillable(v.Type()) || !checkedOnAll(pass, v, success) {
+					continue
+				}
+				seen[name] = true
+				fact[i] = append(fact[i], name)
+			}
+		}
+		sort.Strings(fact[i])
+	}
+	var old validatedArgs
+	pass.ImportObjectFact(factObject(fn), &old)
+	if len(fact) == 0 || reflect.DeepEqual(fact, old) {
+		return false
+	}
+	pass.ExportObjectFact(factObject(fn), &fact)
+	return true
+}
+
+// checkedOnAll reports whether v is nil-checked, or validated by a
+// validator of v itself, in all of blocks.
+func checkedOnAll(pass *analysis.Pass, v ssa.Value, blocks []*ssa.BasicBlock) bool {
+	for _, b := range blocks {
+		if !isNilChecked(v, b) && !argValidated(pass, v, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// argValidated reports whether v is passed to a validator of the
+// argument itself whose error is checked for nil before the block b, as
+// in
+//
+//	if err := notNil(p); err != nil {
+//		return err
+//	}
+//	p.x
+func argValidated(pass *analysis.Pass, v ssa.Value, b *ssa.BasicBlock) bool {
+	if v.Referrers() == nil {
+		return false
+	}
+	for _, r := range *v.Referrers() {
+		c, ok := r.(*ssa.Call)
+		if !ok || c.Call.StaticCallee() == nil || factObject(c.Call.StaticCallee()) == nil {
+			continue
+		}
+		var fact validatedArgs
+		if !pass.ImportObjectFact(factObject(c.Call.StaticCallee()), &fact) {
+			continue
+		}
+		for _, i := range argIndices(c.Common(), v) {
+			for _, f := range fact[i] {
+				if f == "" && errChecked(c, b) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isValidated reports whether the field name of the struct parameter
+// fp, loaded as v, is validated by a call passing fp to a function with
+// a validatedArgs fact whose error is checked for nil before v, as in
+//
+//	if err := validate(req); err != nil {
+//		return err
+//	}
+//	req.Header.Get("X")
+func isValidated(pass *analysis.Pass, fp *ssa.Parameter, name string, v ssa.Value) bool {
+	instr, ok := v.(ssa.Instruction)
+	if !ok {
+		return false
+	}
+	for _, r := range fieldReferrers(fp) {
+		c, ok := r.(*ssa.Call)
+		if !ok || c.Call.StaticCallee() == nil || factObject(c.Call.StaticCallee()) == nil {
+			continue
+		}
+		var fact validatedArgs
+		if !pass.ImportObjectFact(factObject(c.Call.StaticCallee()), &fact) {
+			continue
+		}
+		for _, i := range argIndices(c.Common(), fp) {
+			for _, f := range fact[i] {
+				if f == name && errChecked(c, instr.Block()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// errChecked reports whether b is only reached when the error returned
+// last by the call c is nil.
+func errChecked(c *ssa.Call, b *ssa.BasicBlock) bool {
+	err := ssa.Value(c)
+	if res := c.Call.Signature().Results(); res.Len() > 1 {
+		err = nil
+		for _, r := range *c.Referrers() {
+			if ex, ok := r.(*ssa.Extract); ok && ex.Index == res.Len()-1 {
+				err = ex
+			}
+		}
+	}
+	if err == nil || err.Referrers() == nil {
+		return false
+	}
+	for _, r := range *err.Referrers() {
+		binop, ok := r.(*ssa.BinOp)
+		if !ok || binop.Op != token.EQL && binop.Op != token.NEQ || !isNil(binop.X) && !isNil(binop.Y) || binop.Referrers() == nil {
+			continue
+		}
+		for _, br := range *binop.Referrers() {
+			If, ok := br.(*ssa.If)
+			if !ok {
+				continue
+			}
+			succ := If.Block().Succs[0]
+			if binop.Op == token.NEQ {
+				succ = If.Block().Succs[1]
+			}
+			if edgeDominates(If.Block(), succ, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
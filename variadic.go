@@ -0,0 +1,243 @@
+package nilarg
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// variadicElems has the positions of the variadic arguments which cause
+// panic on calling the function when they are nil. The arguments are
+// packed into a slice, the last parameter of the function, so panicArgs
+// can only tell whether the slice itself is used. The key -1 stands for
+// all the arguments, as in
+//
+//	func closeAll(cs ...*Conn) {
+//		for _, c := range cs {
+//			c.Close()
+//		}
+//	}
+type variadicElems map[int]struct{}
+
+func (*variadicElems) AFact() {}
+
+func (e *variadicElems) String() string {
+	var idx []int
+	for k := range *e {
+		idx = append(idx, k)
+	}
+	sort.Ints(idx)
+	var ks []string
+	for _, k := range idx {
+		if k < 0 {
+			ks = append(ks, "all")
+		} else {
+			ks = append(ks, fmt.Sprint(k))
+		}
+	}
+	return "variadicElems[" + strings.Join(ks, " ") + "]"
+}
+
+// covers reports whether the k-th variadic argument panics when it is
+// nil.
+func (e variadicElems) covers(k int) bool {
+	_, all := e[-1]
+	_, ok := e[k]
+	return all || ok
+}
+
+// checkVariadicElems exports variadicElems for fn if it dereferences the
+// elements of its variadic parameter, or passes them to functions which
+// do, without checking them against nil, and reports whether the fact
+// changed.
+func checkVariadicElems(pass *analysis.Pass, fn *ssa.Function) bool {
+	if factObject(fn) == nil {
+		return false
+	}
+	fact, _ := elemPanics(pass, fn)
+	if len(fact) == 0 {
+		return false
+	}
+	var old variadicElems
+	if pass.ImportObjectFact(factObject(fn), &old) && reflect.DeepEqual(old, fact) {
+		return false
+	}
+	pass.ExportObjectFact(factObject(fn), &fact)
+	return true
+}
+
+// elemPanics returns the positions of the variadic arguments of fn which
+// cause panic when they are nil, and describes the first use panicking.
+// An element read with a constant index is the argument at that
+// position, and the others, such as the ones of range loops, can be any
+// of them.
+func elemPanics(pass *analysis.Pass, fn *ssa.Function) (variadicElems, string) {
+	if !fn.Signature.Variadic() || len(fn.Params) == 0 {
+		return nil, ""
+	}
+	vp := fn.Params[len(fn.Params)-1]
+	elem := vp.Type().Underlying().(*types.Slice).Elem()
+	if !isNillable(elem) && !isFunc(elem) || vp.Referrers() == nil {
+		return nil, ""
+	}
+	fact := variadicElems{}
+	detail := ""
+	for _, r := range *vp.Referrers() {
+		ia, ok := r.(*ssa.IndexAddr)
+		if !ok || ia.X != vp || ia.Referrers() == nil {
+			continue
+		}
+		k := -1
+		if c, ok := ia.Index.(*ssa.Const); ok {
+			k = int(c.Int64())
+		}
+		for _, ir := range *ia.Referrers() {
+			load, ok := ir.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL {
+				continue
+			}
+			if what := elemUse(pass, load); what != "" {
+				fact[k] = struct{}{}
+				if detail == "" {
+					detail = fmt.Sprintf(": an element of %s is %s in %s", vp.Name(), what, fn.Name())
+				}
+			}
+		}
+	}
+	return fact, detail
+}
+
+// elemUse describes the first use of the variadic element v which panics
+// when it is nil, or returns "".
+func elemUse(pass *analysis.Pass, v ssa.Value) string {
+	if v.Referrers() == nil {
+		return ""
+	}
+	for _, r := range *v.Referrers() {
+		if isNilChecked(v, r.Block()) {
+			continue
+		}
+		if x, what := dereference(r); x == v {
+			return what
+		}
+		c, ok := r.(ssa.CallInstruction)
+		if !ok {
+			continue
+		}
+		common := c.Common()
+		if common.Value == v {
+			if common.IsInvoke() {
+				return "the receiver of " + common.Method.Name()
+			}
+			return "called"
+		}
+		s := common.StaticCallee()
+		if s == nil || factObject(s) == nil {
+			continue
+		}
+		var fact panicArgs
+		if !importPanicArgs(pass, factObject(s), &fact) {
+			continue
+		}
+		for _, i := range argIndices(common, v) {
+			if _, ok := fact[i]; ok && !isNilSafeRecv(pass, factObject(s), i) && !argSuppressed(pass, c, i) {
+				return "passed to " + s.Name()
+			}
+		}
+	}
+	return ""
+}
+
+// varargs returns the variadic arguments of the call common, which the
+// builder stores into an array allocated for the call, by their
+// positions. It returns nil for calls passing a slice with ... and for
+// callees which aren't variadic.
+func varargs(common *ssa.CallCommon) []ssa.Value {
+	if len(common.Args) == 0 || !common.Signature().Variadic() {
+		return nil
+	}
+	slice, ok := common.Args[len(common.Args)-1].(*ssa.Slice)
+	if !ok {
+		return nil
+	}
+	arr, ok := slice.X.(*ssa.Alloc)
+	if !ok || arr.Comment != "varargs" || arr.Referrers() == nil {
+		return nil
+	}
+	n := arr.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Len()
+	args := make([]ssa.Value, n)
+	for k := range args {
+		ia := literalElement(arr, int64(k))
+		if ia == nil || ia.Referrers() == nil {
+			continue
+		}
+		for _, r := range *ia.Referrers() {
+			if st, ok := r.(*ssa.Store); ok && st.Addr == ssa.Value(ia) {
+				args[k] = st.Val
+			}
+		}
+	}
+	return args
+}
+
+// varargCall returns the call for which st stores its variadic argument
+// at the position k, or nil if st stores something else.
+func varargCall(st *ssa.Store) (ssa.CallInstruction, int) {
+	ia, ok := st.Addr.(*ssa.IndexAddr)
+	if !ok {
+		return nil, 0
+	}
+	arr, ok := ia.X.(*ssa.Alloc)
+	idx, isConst := ia.Index.(*ssa.Const)
+	if !ok || !isConst || arr.Comment != "varargs" || arr.Referrers() == nil {
+		return nil, 0
+	}
+	for _, r := range *arr.Referrers() {
+		slice, ok := r.(*ssa.Slice)
+		if !ok || slice.Referrers() == nil {
+			continue
+		}
+		for _, sr := range *slice.Referrers() {
+			if c, ok := sr.(ssa.CallInstruction); ok {
+				return c, int(idx.Int64())
+			}
+		}
+	}
+	return nil, 0
+}
+
+// varargPanics returns the static callee of c if it panics when the k-th
+// variadic argument of c is nil.
+func varargPanics(pass *analysis.Pass, c ssa.CallInstruction, k int) *ssa.Function {
+	s := c.Common().StaticCallee()
+	if s == nil || factObject(s) == nil || varargSuppressed(pass, c, k) {
+		return nil
+	}
+	var fact variadicElems
+	if !pass.ImportObjectFact(factObject(s), &fact) || !fact.covers(k) {
+		return nil
+	}
+	return s
+}
+
+// checkVarargs reports the call c if it passes nil as a variadic
+// argument at a position where the callee s panics on it, as in
+//
+//	closeAll(c, nil)
+func checkVarargs(pass *analysis.Pass, c ssa.CallInstruction, s *ssa.Function, stack []fact) {
+	for k, arg := range varargs(c.Common()) {
+		if arg == nil || varargPanics(pass, c, k) != s || nilnessOf(pass, stack, arg) != isnil {
+			continue
+		}
+		// The callees of other packages have no bodies to describe.
+		_, detail := elemPanics(pass, s)
+		report(pass, c.Pos(), "this call can cause panic%s", detail)
+		return
+	}
+}